@@ -1,8 +1,8 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -19,25 +19,16 @@ func main() {
 	// Note: Chi's Handler signature is func(http.ResponseWriter, *http.Request)
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		// Prepare response data
-		response := models.ApiResponse{
-			Code:    200,
-			Message: "Query successful",
-			Data: models.UserData{
-				ID:    1,
-				Email: "test@example.com",
-				Role:  "Admin, chi", // Marked as chi version
-			},
-		}
-
-		// Set response header to JSON
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, chi", // Marked as chi version
+		}).WithMessage("Query successful")
 
 		// Output JSON response
-		// Unlike Echo's c.JSON(), Chi needs manual JSON encoding
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-			return
+		// Unlike Echo's c.JSON(), Chi has no JSON helper; the net/http writer from models sets the header, status and body
+		if err := models.Write(w, response); err != nil {
+			log.Printf("failed to encode response: %v", err)
 		}
 	})
 
@@ -2,12 +2,12 @@ package main
 
 import (
 	"fmt"
-	"net/http"
 
 	// Echo framework
 	"github.com/labstack/echo/v4"
 	// Shared models
 	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/echomodels"
 )
 
 func main() {
@@ -19,19 +19,15 @@ func main() {
 	// Note: Echo's Handler signature is func(echo.Context) error
 	e.GET("/", func(c echo.Context) error {
 		// Prepare response data
-		response := models.ApiResponse{
-			Code:    200,
-			Message: "Query successful",
-			Data: models.UserData{
-				ID:    1,
-				Email: "test@example.com",
-				Role:  "Admin, echo", // Marked as echo version
-			},
-		}
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, echo", // Marked as echo version
+		}).WithMessage("Query successful")
 
-		// Use c.JSON() for output
+		// echomodels.Write calls c.JSON() with the status the envelope carries
 		// Unlike Gin, Echo requires you to return this result (because c.JSON returns an error)
-		return c.JSON(http.StatusOK, response)
+		return echomodels.Write(c, response)
 	})
 
 	// Startup message
@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	// Shared models
 	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/fibermodels"
 )
 
 func main() {
@@ -18,19 +19,15 @@ func main() {
 	// Note: Fiber's Handler signature is func(*fiber.Ctx) error
 	app.Get("/", func(c *fiber.Ctx) error {
 		// Prepare response data
-		response := models.ApiResponse{
-			Code:    200,
-			Message: "Query successful",
-			Data: models.UserData{
-				ID:    1,
-				Email: "test@example.com",
-				Role:  "Admin, fiber", // Marked as fiber version
-			},
-		}
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, fiber", // Marked as fiber version
+		}).WithMessage("Query successful")
 
-		// Use c.Status().JSON() for output
+		// fibermodels.Write uses c.Status().JSON() with the status the envelope carries
 		// Similar to Echo's c.JSON(), Fiber provides a convenient JSON response method
-		return c.Status(http.StatusOK).JSON(response)
+		return fibermodels.Write(c, response)
 	})
 
 	// Create user route
@@ -41,11 +38,13 @@ func main() {
 
 		// Parse request body JSON into struct
 		if err := c.BodyParser(&req); err != nil {
-			return c.Status(http.StatusBadRequest).JSON(models.ApiResponse{
-				Code:    400,
-				Message: "Invalid request body",
-				Data:    nil,
-			})
+			return fibermodels.Write(c, models.Fail(http.StatusBadRequest, "Invalid request body"))
+		}
+
+		// Check email format, password strength and role
+		// models.Invalid turns the field errors into a 422 response listing them
+		if err := req.Validate(); err != nil {
+			return fibermodels.Write(c, models.Invalid(err))
 		}
 
 		// Simulate created user data
@@ -57,11 +56,7 @@ func main() {
 		}
 
 		// Return standard response
-		return c.Status(http.StatusCreated).JSON(models.ApiResponse{
-			Code:    201,
-			Message: "User created successfully",
-			Data:    createdUser,
-		})
+		return fibermodels.Write(c, models.Created(createdUser).WithMessage("User created successfully"))
 	})
 
 	// Startup message
@@ -10,6 +10,8 @@ require (
 	github.com/cloudwego/base64x v0.1.6 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.8 // indirect
 	github.com/gin-contrib/sse v1.1.0 // indirect
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/ginmodels v0.0.0-00010101000000-000000000000
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/go-playground/validator/v10 v10.27.0 // indirect
@@ -37,3 +39,7 @@ require (
 	golang.org/x/tools v0.39.0 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/ginmodels => ../../pkg/models/ginmodels
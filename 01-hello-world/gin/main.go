@@ -3,10 +3,10 @@ package main
 import (
 	// Format I/O (for printing text)
 	"fmt"
-	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/ginmodels"
 )
 
 func main() {
@@ -16,19 +16,14 @@ func main() {
 	r := gin.Default()
 	r.GET("/", func(c *gin.Context) {
 		// Prepare the data to be sent back
-		response := models.ApiResponse{
-			Code:    200, // customize the business logic status code here
-			Message: "Query successful",
-			Data: models.UserData{
-				ID:    1,
-				Email: "test@example.com",
-				Role:  "Admin, gin",
-			},
-		}
-		// Use c.JSON() to output
-		// The first parameter is the HTTP Status Code (200 OK)
-		// The second parameter is the structure you want to convert to JSON (Struct)
-		c.JSON(http.StatusOK, response)
+		// models.OK carries the 200 status, so the handler never repeats it
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, gin",
+		}).WithMessage("Query successful")
+		// ginmodels.Write calls c.JSON() with the status the envelope carries
+		ginmodels.Write(c, response)
 		// c.String(http.StatusOK, "hello, world!")
 	})
 	fmt.Println("Server started successfully! Please visit: http://localhost:8080")
@@ -1,15 +1,39 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/go-mizu/mizu"
+	// Shared models
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/mizumodels"
 )
 
 func main() {
+	// Initialize Mizu app
+	// Similar to Echo's echo.New(), mizu.New() creates a new app instance
 	app := mizu.New()
 
+	// Define routes
+	// Note: Mizu's Handler signature is func(*mizu.Ctx) error
 	app.Get("/", func(c *mizu.Ctx) error {
-		return c.Text(200, "hello, world!")
+		// Prepare response data
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, mizu", // Marked as mizu version
+		}).WithMessage("Query successful")
+
+		// mizumodels.Write calls c.JSON() with the status the envelope carries
+		return mizumodels.Write(c, response)
 	})
 
-	app.Listen(":8080")
+	// Startup message
+	fmt.Println("Server started successfully! Please visit: http://localhost:8080")
+
+	// Start server
+	// app.Listen is equivalent to Echo's e.Start
+	if err := app.Listen(":8080"); err != nil {
+		panic(err)
+	}
 }
@@ -2,3 +2,7 @@
 module go-mizu/go-fw/01-hello-world/nethttp
 
 go 1.25
+
+require github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+
+replace github.com/go-mizu/go-fw => ../..
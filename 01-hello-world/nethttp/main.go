@@ -11,8 +11,6 @@ import (
 	// Network HTTP (for creating web servers)
 	// net/http is the foundation of all Go frameworks. Whether it's Gin or Fiber, they all ultimately call the logic here.
 	"net/http"
-	// Encoding JSON (for JSON serialization/deserialization)
-	"encoding/json"
 
 	"github.com/go-mizu/go-fw/pkg/models"
 )
@@ -34,9 +32,6 @@ func main() {
 		// Send the string through w back to the browser. Fprintln's F stands for File (in Linux philosophy, network connections are also file streams).
 		// fmt.Fprintln(w, "hello, world!")
 
-		// Set Header, tell the browser this is JSON format
-		w.Header().Set("Content-Type", "application/json")
-
 		// Define the data structure you want to return (usually use struct)
 		// data := map[string]interface{}{
 		// 	"status":  "success",
@@ -48,18 +43,14 @@ func main() {
 		// Use json package to convert map or struct to JSON and write to response
 		// json.NewEncoder(w).Encode(data)
 
-		// Instantiate struct
-		response := models.ApiResponse{
-			Code:    200,
-			Message: "Query successful",
-			Data: models.UserData{
-				ID:    1,
-				Email: "test@example.com",
-				Role:  "Admin, nethttp",
-			},
-		}
-		// Encode and return
-		json.NewEncoder(w).Encode(response)
+		// Wrap the data in the shared envelope; models.OK sets code 200
+		response := models.OK(models.UserData{
+			ID:    1,
+			Email: "test@example.com",
+			Role:  "Admin, nethttp",
+		}).WithMessage("Query successful")
+		// Write sets the JSON Content-Type and the status the envelope carries, then encodes it
+		models.Write(w, response)
 	})
 
 	// Print a prompt before starting to let yourself know where to click the URL
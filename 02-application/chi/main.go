@@ -1,10 +1,11 @@
 package main
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/models"
 )
 
 func main() {
@@ -21,7 +22,11 @@ func newRouter() http.Handler {
 	r := chi.NewRouter()
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		fmt.Fprintln(w, "hello, world!")
+		models.Write(w, models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, chi-02",
+		}).WithMessage("Query successful (Application pattern)"))
 	})
 
 	return r
@@ -1,9 +1,10 @@
 package main
 
 import (
-	"net/http"
-
 	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/echomodels"
 )
 
 func main() {
@@ -15,7 +16,11 @@ func newApp() *echo.Echo {
 	e := echo.New()
 
 	e.GET("/", func(c echo.Context) error {
-		return c.String(http.StatusOK, "hello, world!")
+		return echomodels.Write(c, models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, echo-02",
+		}).WithMessage("Query successful (Application pattern)"))
 	})
 
 	return e
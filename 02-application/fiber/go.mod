@@ -2,7 +2,11 @@ module github.com/go-mizu/go-fw/02-application/fiber
 
 go 1.25
 
-require github.com/gofiber/fiber/v2 v2.52.10
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/fibermodels v0.0.0-00010101000000-000000000000
+	github.com/gofiber/fiber/v2 v2.52.10
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
@@ -17,3 +21,7 @@ require (
 	github.com/valyala/tcplisten v1.0.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/fibermodels => ../../pkg/models/fibermodels
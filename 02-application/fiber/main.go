@@ -2,6 +2,9 @@ package main
 
 import (
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/fibermodels"
 )
 
 func main() {
@@ -13,7 +16,11 @@ func newApp() *fiber.App {
 	app := fiber.New()
 
 	app.Get("/", func(c *fiber.Ctx) error {
-		return c.SendString("hello, world!")
+		return fibermodels.Write(c, models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, fiber-02",
+		}).WithMessage("Query successful (Application pattern)"))
 	})
 
 	return app
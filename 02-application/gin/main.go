@@ -1,9 +1,10 @@
 package main
 
 import (
-	"net/http"
-
 	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/ginmodels"
 )
 
 func main() {
@@ -15,7 +16,11 @@ func newRouter() *gin.Engine {
 	r := gin.New()
 
 	r.GET("/", func(c *gin.Context) {
-		c.String(http.StatusOK, "hello, world!")
+		ginmodels.Write(c, models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, gin-02",
+		}).WithMessage("Query successful (Application pattern)"))
 	})
 
 	return r
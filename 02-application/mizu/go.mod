@@ -2,4 +2,12 @@ module github.com/go-mizu/go-fw/02-application/mizu
 
 go 1.26
 
-require github.com/go-mizu/mizu v0.5.26
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/mizumodels v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/mizu v0.5.26
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/mizumodels => ../../pkg/models/mizumodels
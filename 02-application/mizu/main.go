@@ -4,11 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
 
 	// Import the same common model as the net/http version.
 	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/mizumodels"
 	"github.com/go-mizu/mizu"
 )
 
@@ -36,22 +36,18 @@ func newApp() *mizu.App {
 		// Print request path to observe if request comes in
 		fmt.Printf("Received request! Path is: %s\n", c.Request().URL.Path)
 		// Create response data
-		response := models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Query successful (Application pattern)",
-			Data: models.UserData{
-				ID:    2,
-				Email: "app@example.com",
-				Role:  "Admin, mizu-02",
-			},
-		}
+		response := models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, mizu-02",
+		}).WithMessage("Query successful (Application pattern)")
 		// Output JSON to server terminal for development observation
 		if err := json.NewEncoder(os.Stdout).Encode(response); err != nil {
 			log.Println("failed to encode response to stdout:", err)
 		}
 		// Return JSON to client
-		// c.JSON automatically sets Content-Type: application/json
-		return c.JSON(http.StatusOK, response)
+		// mizumodels.Write calls c.JSON, which sets Content-Type: application/json
+		return mizumodels.Write(c, response)
 	})
 	return app
 }
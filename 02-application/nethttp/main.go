@@ -42,24 +42,17 @@ func newRouter() http.Handler {
 	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
 		// Add this line to see who is making the request.
 		fmt.Printf("Received request! Path is: %s\n", r.URL.Path)
-		// Set response format to JSON
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
 		// Create response data
-		response := models.ApiResponse{
-			Code:    http.StatusOK,
-			Message: "Query successful (Application pattern)",
-			Data: models.UserData{
-				ID:    2,
-				Email: "app@example.com",
-				Role:  "Admin, nethttp-02",
-			},
-		}
+		response := models.OK(models.UserData{
+			ID:    2,
+			Email: "app@example.com",
+			Role:  "Admin, nethttp-02",
+		}).WithMessage("Query successful (Application pattern)")
 		json.NewEncoder(os.Stdout).Encode(response)
-		// Encode struct to JSON and write back to Response
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		// Write the envelope as JSON with its status; the header is already
+		// sent if encoding fails, so the error can only be logged
+		if err := models.Write(w, response); err != nil {
 			log.Println("failed to encode response:", err)
-			return
 		}
 	})
 	return mux
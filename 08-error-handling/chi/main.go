@@ -1,21 +1,26 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
 )
 
 func main() {
 	r := chi.NewRouter()
 
-	r.Use(recoverMiddleware)
+	r.Use(problem.Recover)
 
-	r.Get("/error", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusBadRequest)
-		fmt.Fprintln(w, "bad request")
-	})
+	r.Method(http.MethodGet, "/error", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return problem.New(http.StatusBadRequest, "name is required")
+	}))
+
+	r.Method(http.MethodGet, "/internal", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("connect to users db: password authentication failed")
+	}))
 
 	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
 		panic("something went wrong")
@@ -23,15 +28,3 @@ func main() {
 
 	http.ListenAndServe(":8080", r)
 }
-
-func recoverMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if v := recover(); v != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprintln(w, "internal server error")
-			}
-		}()
-		next.ServeHTTP(w, r)
-	})
-}
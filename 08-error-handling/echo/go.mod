@@ -2,9 +2,13 @@ module github.com/go-mizu/go-fw/08-error-handling/echo
 
 go 1.25
 
-require github.com/labstack/echo/v4 v4.14.0
+require (
+	github.com/go-mizu/go-fw/pkg/problem/echoproblem v0.0.0-00010101000000-000000000000
+	github.com/labstack/echo/v4 v4.14.0
+)
 
 require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
@@ -14,5 +18,8 @@ require (
 	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
-	golang.org/x/time v0.14.0 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/problem/echoproblem => ../../pkg/problem/echoproblem
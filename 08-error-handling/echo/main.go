@@ -1,19 +1,26 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+
+	"github.com/go-mizu/go-fw/pkg/problem/echoproblem"
 )
 
 func main() {
 	e := echo.New()
+	e.HTTPErrorHandler = echoproblem.ErrorHandler
 
-	e.Use(middleware.Recover())
+	e.Use(echoproblem.Recover())
 
 	e.GET("/error", func(c echo.Context) error {
-		return echo.NewHTTPError(http.StatusBadRequest, "bad request")
+		return echo.NewHTTPError(http.StatusBadRequest, "name is required")
+	})
+
+	e.GET("/internal", func(c echo.Context) error {
+		return errors.New("connect to users db: password authentication failed")
 	})
 
 	e.GET("/panic", func(c echo.Context) error {
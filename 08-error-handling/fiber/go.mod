@@ -2,10 +2,14 @@ module github.com/go-mizu/go-fw/08-error-handling/fiber
 
 go 1.25
 
-require github.com/gofiber/fiber/v2 v2.52.10
+require (
+	github.com/go-mizu/go-fw/pkg/problem/fiberproblem v0.0.0-00010101000000-000000000000
+	github.com/gofiber/fiber/v2 v2.52.10
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
@@ -17,3 +21,7 @@ require (
 	github.com/valyala/tcplisten v1.0.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/problem/fiberproblem => ../../pkg/problem/fiberproblem
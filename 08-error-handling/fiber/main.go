@@ -1,18 +1,25 @@
 package main
 
 import (
+	"errors"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+
+	"github.com/go-mizu/go-fw/pkg/problem/fiberproblem"
 )
 
 func main() {
-	app := fiber.New(fiber.Config{
-		ErrorHandler: func(c *fiber.Ctx, err error) error {
-			return c.Status(500).SendString("internal server error")
-		},
-	})
+	app := fiber.New(fiber.Config{ErrorHandler: fiberproblem.ErrorHandler})
+
+	app.Use(recover.New())
 
 	app.Get("/error", func(c *fiber.Ctx) error {
-		return fiber.NewError(400, "bad request")
+		return fiber.NewError(fiber.StatusBadRequest, "name is required")
+	})
+
+	app.Get("/internal", func(c *fiber.Ctx) error {
+		return errors.New("connect to users db: password authentication failed")
 	})
 
 	app.Get("/panic", func(c *fiber.Ctx) error {
@@ -1,20 +1,26 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+	"github.com/go-mizu/go-fw/pkg/problem/ginproblem"
 )
 
 func main() {
 	r := gin.New()
 
-	r.Use(gin.Recovery())
+	r.Use(ginproblem.Middleware())
 
 	r.GET("/error", func(c *gin.Context) {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "bad request",
-		})
+		c.Error(problem.New(http.StatusBadRequest, "name is required"))
+	})
+
+	r.GET("/internal", func(c *gin.Context) {
+		c.Error(errors.New("connect to users db: password authentication failed"))
 	})
 
 	r.GET("/panic", func(c *gin.Context) {
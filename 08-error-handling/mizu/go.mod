@@ -2,4 +2,13 @@ module github.com/go-mizu/go-fw/08-error-handling/mizu
 
 go 1.25
 
-require github.com/go-mizu/mizu v0.2.2
+require (
+	github.com/go-mizu/go-fw/pkg/problem/mizuproblem v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/mizu v0.2.2
+)
+
+require github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000 // indirect
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/problem/mizuproblem => ../../pkg/problem/mizuproblem
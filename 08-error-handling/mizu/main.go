@@ -5,21 +5,30 @@ import (
 	"net/http"
 
 	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/problem/mizuproblem"
 )
 
 func main() {
 	app := mizu.New()
 
+	app.Use(mizuproblem.Middleware())
+
 	app.Get("/error", func(c *mizu.Ctx) error {
 		return mizu.HTTPError{
 			Status: http.StatusBadRequest,
-			Err:    errors.New("bad request"),
+			Err:    errors.New("name is required"),
 		}
 	})
 
+	app.Get("/internal", func(c *mizu.Ctx) error {
+		return errors.New("connect to users db: password authentication failed")
+	})
+
 	app.Get("/panic", func(c *mizu.Ctx) error {
 		panic("something went wrong")
 	})
 
-	app.Listen(":8080")
+	// lets the middleware see whether a response was committed
+	http.ListenAndServe(":8080", mizuproblem.Handler(app))
 }
@@ -1,3 +1,7 @@
 module github.com/go-mizu/go-fw/08-error-handling/nethttp
 
 go 1.25
+
+require github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+
+replace github.com/go-mizu/go-fw => ../..
@@ -1,38 +1,32 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
 )
 
 func main() {
 	mux := http.NewServeMux()
 
-	mux.HandleFunc("GET /error", errorHandler)
+	mux.Handle("GET /error", problem.HandlerFunc(errorHandler))
+	mux.Handle("GET /internal", problem.HandlerFunc(internalHandler))
 	mux.HandleFunc("GET /panic", panicHandler)
 
-	handler := recoverMiddleware(mux)
+	handler := problem.Recover(mux)
 
 	http.ListenAndServe(":8080", handler)
 }
 
-func errorHandler(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusBadRequest)
-	fmt.Fprintln(w, "bad request")
+func errorHandler(w http.ResponseWriter, r *http.Request) error {
+	return problem.New(http.StatusBadRequest, "name is required")
 }
 
-func panicHandler(w http.ResponseWriter, r *http.Request) {
-	panic("something went wrong")
+func internalHandler(w http.ResponseWriter, r *http.Request) error {
+	return errors.New("connect to users db: password authentication failed")
 }
 
-func recoverMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if v := recover(); v != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-				fmt.Fprintln(w, "internal server error")
-			}
-		}()
-		next.ServeHTTP(w, r)
-	})
+func panicHandler(w http.ResponseWriter, r *http.Request) {
+	panic("something went wrong")
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"io/fs"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -10,6 +11,9 @@ import (
 //go:embed public/*
 var assets embed.FS
 
+// public is the embedded directory; the paths in assets start with public/
+var public, _ = fs.Sub(assets, "public")
+
 func main() {
 	r := chi.NewRouter()
 
@@ -21,7 +25,7 @@ func main() {
 
 	r.Handle("/embed/*",
 		http.StripPrefix("/embed/",
-			http.FileServer(http.FS(assets)),
+			http.FileServer(http.FS(public)),
 		),
 	)
 
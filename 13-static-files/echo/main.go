@@ -13,7 +13,7 @@ func main() {
 	e := echo.New()
 
 	e.Static("/static", "public")
-	e.StaticFS("/embed", assets)
+	e.StaticFS("/embed", echo.MustSubFS(assets, "public"))
 
 	e.Start(":8080")
 }
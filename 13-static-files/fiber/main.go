@@ -2,8 +2,10 @@ package main
 
 import (
 	"embed"
+	"net/http"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
 )
 
 //go:embed public/*
@@ -13,7 +15,12 @@ func main() {
 	app := fiber.New()
 
 	app.Static("/static", "./public")
-	app.Static("/embed", "./public") // Fiber does not support embed.FS directly
+
+	// Static only reads from disk; embedded files go through the filesystem middleware
+	app.Use("/embed", filesystem.New(filesystem.Config{
+		Root:       http.FS(assets),
+		PathPrefix: "public",
+	}))
 
 	app.Listen(":8080")
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"embed"
+	"io/fs"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
@@ -9,6 +11,9 @@ import (
 //go:embed public/*
 var assets embed.FS
 
+// public is the embedded directory; the paths in assets start with public/
+var public, _ = fs.Sub(assets, "public")
+
 func main() {
 	r := gin.New()
 
@@ -16,7 +21,7 @@ func main() {
 	r.Static("/static", "./public")
 
 	// serve embedded files
-	r.StaticFS("/embed", gin.FS(assets))
+	r.StaticFS("/embed", http.FS(public))
 
 	r.Run(":8080")
 }
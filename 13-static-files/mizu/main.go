@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"io/fs"
 	"net/http"
 
 	"github.com/go-mizu/mizu"
@@ -10,11 +11,14 @@ import (
 //go:embed public/*
 var assets embed.FS
 
+// public is the embedded directory; the paths in assets start with public/
+var public, _ = fs.Sub(assets, "public")
+
 func main() {
 	app := mizu.New()
 
 	app.Static("/static", "./public")
-	app.StaticFS("/embed", http.FS(assets))
+	app.StaticFS("/embed", http.FS(public))
 
 	app.Listen(":8080")
 }
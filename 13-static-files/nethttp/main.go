@@ -2,12 +2,16 @@ package main
 
 import (
 	"embed"
+	"io/fs"
 	"net/http"
 )
 
 //go:embed public/*
 var assets embed.FS
 
+// public is the embedded directory; the paths in assets start with public/
+var public, _ = fs.Sub(assets, "public")
+
 func main() {
 	mux := http.NewServeMux()
 
@@ -19,10 +23,9 @@ func main() {
 	)
 
 	// serve embedded files
-	fs := http.FS(assets)
 	mux.Handle("/embed/",
 		http.StripPrefix("/embed/",
-			http.FileServer(fs),
+			http.FileServer(http.FS(public)),
 		),
 	)
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"html/template"
+	"io"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -11,7 +12,7 @@ type TemplateRenderer struct {
 	t *template.Template
 }
 
-func (r *TemplateRenderer) Render(w http.ResponseWriter, name string, data any, c echo.Context) error {
+func (r *TemplateRenderer) Render(w io.Writer, name string, data any, c echo.Context) error {
 	return r.t.ExecuteTemplate(w, name, data)
 }
 
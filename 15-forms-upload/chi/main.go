@@ -1,39 +1,64 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/problem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
-	r := chi.NewRouter()
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
 
-	r.Post("/login", login)
-	r.Post("/upload", upload)
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
 
-	http.ListenAndServe(":8080", r)
-}
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
 
-func login(w http.ResponseWriter, r *http.Request) {
-	r.ParseForm()
-	fmt.Fprintf(w, "user=%s\n", r.FormValue("user"))
+	http.ListenAndServe(":8080", routes(uploads))
 }
 
-func upload(w http.ResponseWriter, r *http.Request) {
-	r.ParseMultipartForm(10 << 20)
-	defer r.MultipartForm.RemoveAll()
+func routes(uploads upload.Uploader) http.Handler {
+	r := chi.NewRouter()
+
+	r.Post("/login", login)
+	r.Method(http.MethodPost, "/upload", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		form, err := uploads.Form(w, r)
+		if err != nil {
+			return err
+		}
+		defer form.RemoveAll()
 
-	file, header, _ := r.FormFile("file")
-	defer file.Close()
+		files, err := uploads.Save(r.Context(), form, "file")
+		if err != nil {
+			return err
+		}
+		return models.Write(w, models.Created(files))
+	}))
 
-	out, _ := os.Create(header.Filename)
-	defer out.Close()
+	r.Method(http.MethodPost, "/stream", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		files, err := uploads.Stream(w, r, "file")
+		if err != nil {
+			return err
+		}
+		return models.Write(w, models.Created(files))
+	}))
 
-	io.Copy(out, file)
+	return r
+}
 
-	fmt.Fprintf(w, "uploaded %s\n", header.Filename)
+func login(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	fmt.Fprintf(w, "user=%s\n", r.FormValue("user"))
 }
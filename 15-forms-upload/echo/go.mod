@@ -2,7 +2,12 @@ module github.com/go-mizu/go-fw/15-forms-upload/echo
 
 go 1.25
 
-require github.com/labstack/echo/v4 v4.14.0
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/echomodels v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/problem/echoproblem v0.0.0-00010101000000-000000000000
+	github.com/labstack/echo/v4 v4.14.0
+)
 
 require (
 	github.com/labstack/gommon v0.4.2 // indirect
@@ -15,3 +20,9 @@ require (
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/echomodels => ../../pkg/models/echomodels
+
+replace github.com/go-mizu/go-fw/pkg/problem/echoproblem => ../../pkg/problem/echoproblem
@@ -1,15 +1,37 @@
 package main
 
 import (
-	"io"
+	"flag"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/echomodels"
+	"github.com/go-mizu/go-fw/pkg/problem/echoproblem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
+
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+	routes(uploads).Start(":8080")
+}
+
+func routes(uploads upload.Uploader) *echo.Echo {
 	e := echo.New()
+	e.HTTPErrorHandler = echoproblem.ErrorHandler
 
 	e.POST("/login", func(c echo.Context) error {
 		user := c.FormValue("user")
@@ -19,27 +41,26 @@ func main() {
 	})
 
 	e.POST("/upload", func(c echo.Context) error {
-		file, err := c.FormFile("file")
+		form, err := uploads.Form(c.Response(), c.Request())
 		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest)
+			return err
 		}
+		defer form.RemoveAll()
 
-		src, err := file.Open()
+		files, err := uploads.Save(c.Request().Context(), form, "file")
 		if err != nil {
 			return err
 		}
-		defer src.Close()
+		return echomodels.Write(c, models.Created(files))
+	})
 
-		dst, err := os.Create(file.Filename)
+	e.POST("/stream", func(c echo.Context) error {
+		files, err := uploads.Stream(c.Response(), c.Request(), "file")
 		if err != nil {
 			return err
 		}
-		defer dst.Close()
-
-		io.Copy(dst, src)
-
-		return c.String(http.StatusOK, "uploaded "+file.Filename)
+		return echomodels.Write(c, models.Created(files))
 	})
 
-	e.Start(":8080")
+	return e
 }
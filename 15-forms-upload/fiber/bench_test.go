@@ -0,0 +1,42 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+func BenchmarkUpload(b *testing.B) {
+	store, err := upload.NewLocal(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("original", func(b *testing.B) {
+		chaptertest.BenchUploadOriginal(b, chaptertest.Listen(b, original(b.TempDir())))
+	})
+	chaptertest.BenchUpload(b, chaptertest.Listen(b, routes(upload.Uploader{Store: store, MaxFile: 1 << 30, MaxRequest: 1 << 30})), store)
+}
+
+// original is the upload handler of this chapter before pkg/upload, saving
+// into dir rather than the working directory. The body limit is raised
+// from the default 4 MiB, which would refuse the file.
+func original(dir string) *fiber.App {
+	app := fiber.New(fiber.Config{BodyLimit: 1 << 30})
+
+	app.Post("/", func(c *fiber.Ctx) error {
+		file, err := c.FormFile("file")
+		if err != nil {
+			return c.Status(400).SendString("file missing")
+		}
+
+		c.SaveFile(file, filepath.Join(dir, file.Filename))
+		return c.SendString("uploaded " + file.Filename)
+	})
+
+	return app
+}
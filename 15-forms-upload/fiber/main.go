@@ -1,11 +1,40 @@
 package main
 
 import (
+	"flag"
+	"os"
+	"path/filepath"
+
 	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/fibermodels"
+	"github.com/go-mizu/go-fw/pkg/problem/fiberproblem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
-	app := fiber.New()
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
+
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+	routes(uploads).Listen(":8080")
+}
+
+func routes(uploads upload.Uploader) *fiber.App {
+	app := fiber.New(fiber.Config{
+		ErrorHandler: fiberproblem.ErrorHandler,
+		// hand the handlers the body as it arrives, and leave parsing to them
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+	})
 
 	app.Post("/login", func(c *fiber.Ctx) error {
 		user := c.FormValue("user")
@@ -15,14 +44,29 @@ func main() {
 	})
 
 	app.Post("/upload", func(c *fiber.Ctx) error {
-		file, err := c.FormFile("file")
+		form, err := uploads.ReadForm(c.Get(fiber.HeaderContentType), c.Context().RequestBodyStream())
 		if err != nil {
-			return c.Status(400).SendString("file missing")
+			// the rest of the body is still on the connection
+			c.Context().SetConnectionClose()
+			return err
 		}
+		defer form.RemoveAll()
 
-		c.SaveFile(file, "./"+file.Filename)
-		return c.SendString("uploaded " + file.Filename)
+		files, err := uploads.Save(c.UserContext(), form, "file")
+		if err != nil {
+			return err
+		}
+		return fibermodels.Write(c, models.Created(files))
+	})
+
+	app.Post("/stream", func(c *fiber.Ctx) error {
+		files, err := uploads.ReadStream(c.UserContext(), c.Get(fiber.HeaderContentType), c.Context().RequestBodyStream(), "file")
+		if err != nil {
+			c.Context().SetConnectionClose()
+			return err
+		}
+		return fibermodels.Write(c, models.Created(files))
 	})
 
-	app.Listen(":8080")
+	return app
 }
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+func BenchmarkUpload(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+
+	store, err := upload.NewLocal(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	srv := httptest.NewServer(routes(upload.Uploader{Store: store, MaxFile: 1 << 30, MaxRequest: 1 << 30}))
+	defer srv.Close()
+
+	b.Run("original", func(b *testing.B) {
+		orig := httptest.NewServer(original(b.TempDir()))
+		defer orig.Close()
+
+		chaptertest.BenchUploadOriginal(b, orig.URL)
+	})
+	chaptertest.BenchUpload(b, srv.URL, store)
+}
+
+// original is the upload handler of this chapter before pkg/upload, saving
+// into dir rather than the working directory.
+func original(dir string) *gin.Engine {
+	r := gin.New()
+
+	r.POST("/", func(c *gin.Context) {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		c.SaveUploadedFile(file, filepath.Join(dir, file.Filename))
+		c.String(http.StatusOK, "uploaded %s", file.Filename)
+	})
+
+	return r
+}
@@ -2,7 +2,12 @@ module github.com/go-mizu/go-fw/15-forms-upload/gin
 
 go 1.25
 
-require github.com/gin-gonic/gin v1.11.0
+require (
+	github.com/gin-gonic/gin v1.11.0
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/ginmodels v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/problem/ginproblem v0.0.0-00010101000000-000000000000
+)
 
 require (
 	github.com/bytedance/sonic v1.14.0 // indirect
@@ -37,3 +42,9 @@ require (
 	golang.org/x/tools v0.39.0 // indirect
 	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/ginmodels => ../../pkg/models/ginmodels
+
+replace github.com/go-mizu/go-fw/pkg/problem/ginproblem => ../../pkg/problem/ginproblem
@@ -1,12 +1,35 @@
 package main
 
 import (
+	"flag"
 	"net/http"
+	"os"
+	"path/filepath"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/ginmodels"
+	"github.com/go-mizu/go-fw/pkg/problem/ginproblem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
+
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+	routes(uploads).Run(":8080")
+}
+
+func routes(uploads upload.Uploader) *gin.Engine {
 	r := gin.New()
 
 	r.POST("/login", func(c *gin.Context) {
@@ -17,15 +40,29 @@ func main() {
 	})
 
 	r.POST("/upload", func(c *gin.Context) {
-		file, err := c.FormFile("file")
+		form, err := uploads.Form(c.Writer, c.Request)
+		if err != nil {
+			ginproblem.Abort(c, err)
+			return
+		}
+		defer form.RemoveAll()
+
+		files, err := uploads.Save(c.Request.Context(), form, "file")
 		if err != nil {
-			c.AbortWithStatus(http.StatusBadRequest)
+			ginproblem.Abort(c, err)
 			return
 		}
+		ginmodels.Write(c, models.Created(files))
+	})
 
-		c.SaveUploadedFile(file, "./"+file.Filename)
-		c.String(http.StatusOK, "uploaded %s", file.Filename)
+	r.POST("/stream", func(c *gin.Context) {
+		files, err := uploads.Stream(c.Writer, c.Request, "file")
+		if err != nil {
+			ginproblem.Abort(c, err)
+			return
+		}
+		ginmodels.Write(c, models.Created(files))
 	})
 
-	r.Run(":8080")
+	return r
 }
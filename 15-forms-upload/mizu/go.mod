@@ -2,4 +2,15 @@ module github.com/go-mizu/go-fw/15-forms-upload/mizu
 
 go 1.25
 
-require github.com/go-mizu/mizu v0.2.2
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/mizumodels v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/problem/mizuproblem v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/mizu v0.2.2
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/mizumodels => ../../pkg/models/mizumodels
+
+replace github.com/go-mizu/go-fw/pkg/problem/mizuproblem => ../../pkg/problem/mizuproblem
@@ -1,15 +1,37 @@
 package main
 
 import (
-	"io"
+	"flag"
 	"net/http"
 	"os"
+	"path/filepath"
 
 	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/mizumodels"
+	"github.com/go-mizu/go-fw/pkg/problem/mizuproblem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
+
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+	http.ListenAndServe(":8080", routes(uploads))
+}
+
+func routes(uploads upload.Uploader) http.Handler {
 	app := mizu.New()
+	app.Use(mizuproblem.Middleware())
 
 	app.Post("/login", func(c *mizu.Ctx) error {
 		user := c.Form("user")
@@ -19,22 +41,27 @@ func main() {
 	})
 
 	app.Post("/upload", func(c *mizu.Ctx) error {
-		file, header, err := c.FormFile("file")
+		form, err := uploads.Form(c.Writer(), c.Request())
 		if err != nil {
-			return c.Text(http.StatusBadRequest, "file missing")
+			return err
 		}
-		defer file.Close()
+		defer form.RemoveAll()
 
-		out, err := os.Create(header.Filename)
+		files, err := uploads.Save(c.Request().Context(), form, "file")
 		if err != nil {
 			return err
 		}
-		defer out.Close()
-
-		io.Copy(out, file)
+		return mizumodels.Write(c, models.Created(files))
+	})
 
-		return c.Text(http.StatusOK, "uploaded "+header.Filename)
+	app.Post("/stream", func(c *mizu.Ctx) error {
+		files, err := uploads.Stream(c.Writer(), c.Request(), "file")
+		if err != nil {
+			return err
+		}
+		return mizumodels.Write(c, models.Created(files))
 	})
 
-	app.Listen(":8080")
+	// lets the middleware see whether a response was committed
+	return mizuproblem.Handler(app)
 }
@@ -0,0 +1,13 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+func TestUpload(t *testing.T) {
+	uploads := upload.Uploader{Store: upload.NewMemory(), MaxFile: 1 << 20, MaxRequest: 2 << 20}
+	chaptertest.Run(t, chaptertest.Handler(routes(uploads)))
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+func BenchmarkUpload(b *testing.B) {
+	store, err := upload.NewLocal(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	srv := httptest.NewServer(routes(upload.Uploader{Store: store, MaxFile: 1 << 30, MaxRequest: 1 << 30}))
+	defer srv.Close()
+
+	b.Run("original", func(b *testing.B) {
+		orig := httptest.NewServer(original(b.TempDir()))
+		defer orig.Close()
+
+		chaptertest.BenchUploadOriginal(b, orig.URL)
+	})
+	chaptertest.BenchUpload(b, srv.URL, store)
+}
+
+// original is the upload handler of this chapter before pkg/upload, saving
+// into dir rather than the working directory.
+func original(dir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(10 << 20); err != nil {
+			http.Error(w, "bad multipart", http.StatusBadRequest)
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "file missing", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		out, err := os.Create(filepath.Join(dir, header.Filename))
+		if err != nil {
+			http.Error(w, "cannot save file", http.StatusInternalServerError)
+			return
+		}
+		defer out.Close()
+
+		io.Copy(out, file)
+
+		fmt.Fprintf(w, "uploaded %s\n", header.Filename)
+	}
+}
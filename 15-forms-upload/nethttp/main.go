@@ -1,19 +1,59 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
+	"path/filepath"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/problem"
+	"github.com/go-mizu/go-fw/pkg/upload"
 )
 
 func main() {
+	dsn := flag.String("store", filepath.Join(os.TempDir(), "uploads"), "upload store: a directory, an s3:// URL, or empty for memory")
+	flag.Parse()
+
+	store, err := upload.Open(*dsn)
+	if err != nil {
+		panic(err)
+	}
+
+	// images, PDF and plain text, 1 MiB each and 2 MiB per request
+	uploads := upload.Uploader{Store: store, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+	http.ListenAndServe(":8080", routes(uploads))
+}
+
+func routes(uploads upload.Uploader) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("POST /login", login)
-	mux.HandleFunc("POST /upload", upload)
+	mux.Handle("POST /upload", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		form, err := uploads.Form(w, r)
+		if err != nil {
+			return err
+		}
+		defer form.RemoveAll()
+
+		files, err := uploads.Save(r.Context(), form, "file")
+		if err != nil {
+			return err
+		}
+		return models.Write(w, models.Created(files))
+	}))
 
-	http.ListenAndServe(":8080", mux)
+	mux.Handle("POST /stream", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		files, err := uploads.Stream(w, r, "file")
+		if err != nil {
+			return err
+		}
+		return models.Write(w, models.Created(files))
+	}))
+
+	return mux
 }
 
 func login(w http.ResponseWriter, r *http.Request) {
@@ -27,29 +67,3 @@ func login(w http.ResponseWriter, r *http.Request) {
 
 	fmt.Fprintf(w, "user=%s pass=%s\n", user, pass)
 }
-
-func upload(w http.ResponseWriter, r *http.Request) {
-	if err := r.ParseMultipartForm(10 << 20); err != nil {
-		http.Error(w, "bad multipart", http.StatusBadRequest)
-		return
-	}
-	defer r.MultipartForm.RemoveAll()
-
-	file, header, err := r.FormFile("file")
-	if err != nil {
-		http.Error(w, "file missing", http.StatusBadRequest)
-		return
-	}
-	defer file.Close()
-
-	out, err := os.Create("./" + header.Filename)
-	if err != nil {
-		http.Error(w, "cannot save file", http.StatusInternalServerError)
-		return
-	}
-	defer out.Close()
-
-	io.Copy(out, file)
-
-	fmt.Fprintf(w, "uploaded %s\n", header.Filename)
-}
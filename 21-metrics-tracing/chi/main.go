@@ -1,29 +1,97 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
-	chiprom "github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
 )
 
+const addr = ":8080"
+
 func main() {
-	r := chi.NewRouter()
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 
-	r.Use(chiprom.RequestID)
-	r.Use(chiprom.RealIP)
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
 
-	// Chi does not ship Prometheus metrics by default.
-	// This example assumes a standard chi Prometheus middleware is used.
-	r.Use(chiprom.NewWrapResponseWriter)
-	r.Use(chiprom.NewCompressor)
+	http.ListenAndServe(addr, routes(reg, tp, *profiles))
+}
+
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) http.Handler {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
+
+	r := chi.NewRouter()
 
-	r.Handle("/metrics", promhttp.Handler())
+	r.Use(tracer.Middleware(routePattern))
+	r.Use(httpmetrics.New(reg).Middleware(routePattern))
 
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok\n"))
 	})
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + chi.URLParam(r, "id") + "\n"))
+	})
+	r.Get("/users/{id}/profile", func(w http.ResponseWriter, r *http.Request) {
+		profile, err := fetch(r.Context(), client, profiles+"/profiles/"+chi.URLParam(r, "id"))
+		if err != nil {
+			http.Error(w, "profile unavailable", http.StatusBadGateway)
+			return
+		}
+		w.Write(profile)
+	})
+	r.Get("/profiles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "0" {
+			http.Error(w, "no profile", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("profile " + chi.URLParam(r, "id") + "\n"))
+	})
+	r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	return r
+}
+
+// routePattern is the route Chi matched, empty when none did.
+func routePattern(r *http.Request) string {
+	return chi.RouteContext(r.Context()).RoutePattern()
+}
+
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	http.ListenAndServe(":8080", r)
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+)
+
+func TestMetrics(t *testing.T) {
+	// a second instance serves the profiles, as the profile service would
+	profiles := httptest.NewServer(routes(prometheus.NewRegistry(), noop.NewTracerProvider(), ""))
+	defer profiles.Close()
+
+	chaptertest.Run(t, chaptertest.Handler(routes(prometheus.NewRegistry(), noop.NewTracerProvider(), profiles.URL)))
+}
@@ -1,17 +1,50 @@
 module github.com/go-mizu/go-fw/21-metrics-tracing/echo
 
-go 1.25
+go 1.25.0
 
-require github.com/labstack/echo/v4 v4.14.0
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/httpmetrics v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/httpmetrics/echometrics v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/tracing v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/tracing/echotracing v0.0.0-00010101000000-000000000000
+	github.com/labstack/echo/v4 v4.14.0
+	github.com/prometheus/client_golang v1.19.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.44.0
+	go.opentelemetry.io/otel/sdk v1.44.0
+	go.opentelemetry.io/otel/trace v1.44.0
+)
 
 require (
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
 	github.com/labstack/gommon v0.4.2 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasttemplate v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.44.0 // indirect
+	go.opentelemetry.io/otel/metric v1.44.0 // indirect
 	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
-	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
+	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/httpmetrics => ../../pkg/httpmetrics
+
+replace github.com/go-mizu/go-fw/pkg/httpmetrics/echometrics => ../../pkg/httpmetrics/echometrics
+
+replace github.com/go-mizu/go-fw/pkg/tracing => ../../pkg/tracing
+
+replace github.com/go-mizu/go-fw/pkg/tracing/echotracing => ../../pkg/tracing/echotracing
@@ -1,19 +1,92 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo-contrib/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/httpmetrics/echometrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
+	"github.com/go-mizu/go-fw/pkg/tracing/echotracing"
 )
 
+const addr = ":8080"
+
 func main() {
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	routes(reg, tp, *profiles).Start(addr)
+}
+
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) *echo.Echo {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
+
 	e := echo.New()
 
-	p := prometheus.NewPrometheus("echo", nil)
-	p.Use(e)
+	e.Use(echotracing.Middleware(tracer))
+	e.Use(echometrics.Middleware(httpmetrics.New(reg)))
 
 	e.GET("/", func(c echo.Context) error {
 		return c.String(200, "ok\n")
 	})
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(200, "user "+c.Param("id")+"\n")
+	})
+	e.GET("/users/:id/profile", func(c echo.Context) error {
+		profile, err := fetch(c.Request().Context(), client, profiles+"/profiles/"+c.Param("id"))
+		if err != nil {
+			return c.String(http.StatusBadGateway, "profile unavailable\n")
+		}
+		return c.Blob(200, echo.MIMETextPlainCharsetUTF8, profile)
+	})
+	e.GET("/profiles/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return c.String(http.StatusNotFound, "no profile\n")
+		}
+		return c.String(200, "profile "+c.Param("id")+"\n")
+	})
+	e.GET("/metrics", echo.WrapHandler(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+
+	return e
+}
+
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	e.Start(":8080")
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
@@ -1,19 +1,54 @@
 module github.com/go-mizu/go-fw/21-metrics-tracing/fiber
 
-go 1.25
+go 1.25.0
 
-require github.com/gofiber/fiber/v2 v2.52.10
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/httpmetrics v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/httpmetrics/fibermetrics v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/tracing v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/tracing/fibertracing v0.0.0-00010101000000-000000000000
+	github.com/gofiber/fiber/v2 v2.52.10
+	github.com/prometheus/client_golang v1.19.1
+	go.opentelemetry.io/otel/exporters/stdout/stdouttrace v1.44.0
+	go.opentelemetry.io/otel/sdk v1.44.0
+	go.opentelemetry.io/otel/trace v1.44.0
+)
 
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/beorn7/perks v1.0.1 // indirect
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/go-logr/logr v1.4.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	github.com/go-mizu/go-fw/pkg/instrument/fiberinstrument v0.0.0-00010101000000-000000000000 // indirect
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/klauspost/compress v1.17.9 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/prometheus/client_model v0.5.0 // indirect
+	github.com/prometheus/common v0.48.0 // indirect
+	github.com/prometheus/procfs v0.12.0 // indirect
 	github.com/rivo/uniseg v0.2.0 // indirect
 	github.com/valyala/bytebufferpool v1.0.0 // indirect
 	github.com/valyala/fasthttp v1.51.0 // indirect
 	github.com/valyala/tcplisten v1.0.0 // indirect
-	golang.org/x/sys v0.39.0 // indirect
+	go.opentelemetry.io/auto/sdk v1.2.1 // indirect
+	go.opentelemetry.io/otel v1.44.0 // indirect
+	go.opentelemetry.io/otel/metric v1.44.0 // indirect
+	golang.org/x/sys v0.45.0 // indirect
+	google.golang.org/protobuf v1.36.9 // indirect
 )
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/httpmetrics => ../../pkg/httpmetrics
+
+replace github.com/go-mizu/go-fw/pkg/httpmetrics/fibermetrics => ../../pkg/httpmetrics/fibermetrics
+
+replace github.com/go-mizu/go-fw/pkg/instrument/fiberinstrument => ../../pkg/instrument/fiberinstrument
+
+replace github.com/go-mizu/go-fw/pkg/tracing => ../../pkg/tracing
+
+replace github.com/go-mizu/go-fw/pkg/tracing/fibertracing => ../../pkg/tracing/fibertracing
@@ -1,20 +1,93 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/gofiber/fiber/v2"
-	fiberprom "github.com/gofiber/contrib/prometheus"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/httpmetrics/fibermetrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
+	"github.com/go-mizu/go-fw/pkg/tracing/fibertracing"
 )
 
+const addr = ":8080"
+
 func main() {
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	routes(reg, tp, *profiles).Listen(addr)
+}
+
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) *fiber.App {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
+
 	app := fiber.New()
 
-	prom := fiberprom.New("fiber")
-	prom.RegisterAt(app, "/metrics")
-	app.Use(prom.Middleware)
+	app.Use(fibertracing.Middleware(tracer))
+	app.Use(fibermetrics.Middleware(httpmetrics.New(reg)))
 
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.SendString("ok\n")
 	})
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return c.SendString("user " + c.Params("id") + "\n")
+	})
+	app.Get("/users/:id/profile", func(c *fiber.Ctx) error {
+		profile, err := fetch(c.UserContext(), client, profiles+"/profiles/"+c.Params("id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).SendString("profile unavailable\n")
+		}
+		return c.Send(profile)
+	})
+	app.Get("/profiles/:id", func(c *fiber.Ctx) error {
+		if c.Params("id") == "0" {
+			return c.Status(fiber.StatusNotFound).SendString("no profile\n")
+		}
+		return c.SendString("profile " + c.Params("id") + "\n")
+	})
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+
+	return app
+}
+
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	app.Listen(":8080")
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
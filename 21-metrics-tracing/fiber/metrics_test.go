@@ -0,0 +1,17 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+)
+
+func TestMetrics(t *testing.T) {
+	// a second instance serves the profiles, as the profile service would
+	profiles := chaptertest.Listen(t, routes(prometheus.NewRegistry(), noop.NewTracerProvider(), ""))
+
+	chaptertest.Run(t, chaptertest.App(routes(prometheus.NewRegistry(), noop.NewTracerProvider(), profiles)))
+}
@@ -1,22 +1,94 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	ginprom "github.com/zsais/go-gin-prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/httpmetrics/ginmetrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
+	"github.com/go-mizu/go-fw/pkg/tracing/gintracing"
 )
 
+const addr = ":8080"
+
 func main() {
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	routes(reg, tp, *profiles).Run(addr)
+}
+
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) *gin.Engine {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
+
 	r := gin.New()
 
-	p := ginprom.NewPrometheus("gin")
-	p.Use(r)
+	r.Use(gintracing.Middleware(tracer))
+	r.Use(ginmetrics.Middleware(httpmetrics.New(reg)))
 
 	r.GET("/", func(c *gin.Context) {
 		c.String(200, "ok\n")
 	})
+	r.GET("/users/:id", func(c *gin.Context) {
+		c.String(200, "user "+c.Param("id")+"\n")
+	})
+	r.GET("/users/:id/profile", func(c *gin.Context) {
+		profile, err := fetch(c.Request.Context(), client, profiles+"/profiles/"+c.Param("id"))
+		if err != nil {
+			c.String(http.StatusBadGateway, "profile unavailable\n")
+			return
+		}
+		c.Data(200, "text/plain; charset=utf-8", profile)
+	})
+	r.GET("/profiles/:id", func(c *gin.Context) {
+		if c.Param("id") == "0" {
+			c.String(http.StatusNotFound, "no profile\n")
+			return
+		}
+		c.String(200, "profile "+c.Param("id")+"\n")
+	})
+	r.GET("/metrics", gin.WrapH(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})))
+
+	return r
+}
 
-	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	r.Run(":8080")
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
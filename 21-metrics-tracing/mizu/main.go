@@ -1,17 +1,92 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+
 	"github.com/go-mizu/mizu"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
 )
 
+const addr = ":8080"
+
 func main() {
-	app := mizu.New()
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+
+	http.ListenAndServe(addr, routes(reg, tp, *profiles))
+}
+
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) http.Handler {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
 
-	app.Use(mizu.Metrics())
+	app := mizu.New()
 
 	app.Get("/", func(c *mizu.Ctx) error {
 		return c.Text(200, "ok\n")
 	})
+	app.Get("/users/:id", func(c *mizu.Ctx) error {
+		return c.Text(200, "user "+c.Param("id")+"\n")
+	})
+	app.Get("/users/:id/profile", func(c *mizu.Ctx) error {
+		profile, err := fetch(c.Request().Context(), client, profiles+"/profiles/"+c.Param("id"))
+		if err != nil {
+			return c.Text(http.StatusBadGateway, "profile unavailable\n")
+		}
+		return c.Text(200, string(profile))
+	})
+	app.Get("/profiles/:id", func(c *mizu.Ctx) error {
+		if c.Param("id") == "0" {
+			return c.Text(http.StatusNotFound, "no profile\n")
+		}
+		return c.Text(200, "profile "+c.Param("id")+"\n")
+	})
+
+	metrics := promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+	app.Get("/metrics", func(c *mizu.Ctx) error {
+		metrics.ServeHTTP(c.Writer(), c.Request())
+		return nil
+	})
+
+	return tracer.Middleware(httpmetrics.Pattern)(httpmetrics.New(reg).Middleware(httpmetrics.Pattern)(app))
+}
+
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	app.Listen(":8080")
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
@@ -1,73 +1,89 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-)
-
-var (
-	reqTotal = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "http_requests_total",
-			Help: "Total HTTP requests",
-		},
-		[]string{"method", "route", "code"},
-	)
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 
-	reqDuration = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "http_request_duration_seconds",
-			Help:    "HTTP request duration in seconds",
-			Buckets: prometheus.DefBuckets,
-		},
-		[]string{"method", "route", "code"},
-	)
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/tracing"
 )
 
-type statusWriter struct {
-	http.ResponseWriter
-	status int
-}
-
-func (w *statusWriter) WriteHeader(code int) {
-	w.status = code
-	w.ResponseWriter.WriteHeader(code)
-}
+const addr = ":8080"
 
-func metrics(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
-		start := time.Now()
+func main() {
+	profiles := flag.String("profiles", "http://localhost"+addr, "base URL of the profile service")
+	flag.Parse()
 
-		next.ServeHTTP(sw, r)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
 
-		reqTotal.WithLabelValues(
-			r.Method,
-			r.URL.Path,
-			strconv.Itoa(sw.status),
-		).Inc()
+	// every span is printed as it ends; a collector would get them in batches
+	exp, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+	if err != nil {
+		panic(err)
+	}
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
 
-		reqDuration.WithLabelValues(
-			r.Method,
-			r.URL.Path,
-			strconv.Itoa(sw.status),
-		).Observe(time.Since(start).Seconds())
-	})
+	http.ListenAndServe(addr, routes(reg, tp, *profiles))
 }
 
-func main() {
-	prometheus.MustRegister(reqTotal, reqDuration)
+func routes(reg *prometheus.Registry, tp trace.TracerProvider, profiles string) http.Handler {
+	tracer := tracing.New(tp)
+	client := &http.Client{Transport: tracer.Transport(nil)}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(100 * time.Millisecond)
+
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("ok\n"))
 	})
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id") + "\n"))
+	})
+	mux.HandleFunc("GET /users/{id}/profile", func(w http.ResponseWriter, r *http.Request) {
+		profile, err := fetch(r.Context(), client, profiles+"/profiles/"+r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "profile unavailable", http.StatusBadGateway)
+			return
+		}
+		w.Write(profile)
+	})
+	mux.HandleFunc("GET /profiles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			http.Error(w, "no profile", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("profile " + r.PathValue("id") + "\n"))
+	})
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+
+	metrics := httpmetrics.New(reg)
+	return tracer.Middleware(httpmetrics.Pattern)(metrics.Middleware(httpmetrics.Pattern)(mux))
+}
+
+// fetch gets url with ctx, so the call is a child of the span in ctx.
+func fetch(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
 
-	http.ListenAndServe(":8080", metrics(mux))
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, res.Status)
+	}
+	return io.ReadAll(res.Body)
 }
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func main() {
+	db := flag.String("db", "", "SQLite database file; users are kept in memory when empty")
+	flag.Parse()
+
+	store, err := users.Open(*db)
+	if err != nil {
+		panic(err)
+	}
+
+	http.ListenAndServe(":8080", routes(users.NewService(store)))
+}
+
+func routes(svc *users.Service) http.Handler {
+	r := chi.NewRouter()
+
+	r.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		var req models.CreateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			models.Write(w, users.BadBody())
+			return
+		}
+		models.Write(w, svc.Create(r.Context(), req))
+	})
+
+	r.Get("/users", func(w http.ResponseWriter, r *http.Request) {
+		res, links := svc.List(r.Context(), r.URL)
+		links.Set(w.Header())
+		models.Write(w, res)
+	})
+
+	r.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		models.Write(w, svc.Get(r.Context(), chi.URLParam(r, "id")))
+	})
+
+	r.Patch("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		var req models.UpdateUserRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			models.Write(w, users.BadBody())
+			return
+		}
+		models.Write(w, svc.Update(r.Context(), chi.URLParam(r, "id"), req))
+	})
+
+	r.Delete("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		models.Write(w, svc.Delete(r.Context(), chi.URLParam(r, "id")))
+	})
+
+	return r
+}
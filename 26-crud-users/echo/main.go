@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/labstack/echo/v4"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/echomodels"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func main() {
+	db := flag.String("db", "", "SQLite database file; users are kept in memory when empty")
+	flag.Parse()
+
+	store, err := users.Open(*db)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(users.NewService(store)).Start(":8080")
+}
+
+func routes(svc *users.Service) *echo.Echo {
+	e := echo.New()
+
+	e.POST("/users", func(c echo.Context) error {
+		var req models.CreateUserRequest
+		if err := c.Bind(&req); err != nil {
+			return echomodels.Write(c, users.BadBody())
+		}
+		return echomodels.Write(c, svc.Create(c.Request().Context(), req))
+	})
+
+	e.GET("/users", func(c echo.Context) error {
+		res, links := svc.List(c.Request().Context(), c.Request().URL)
+		links.Set(c.Response().Header())
+		return echomodels.Write(c, res)
+	})
+
+	e.GET("/users/:id", func(c echo.Context) error {
+		return echomodels.Write(c, svc.Get(c.Request().Context(), c.Param("id")))
+	})
+
+	e.PATCH("/users/:id", func(c echo.Context) error {
+		var req models.UpdateUserRequest
+		if err := c.Bind(&req); err != nil {
+			return echomodels.Write(c, users.BadBody())
+		}
+		return echomodels.Write(c, svc.Update(c.Request().Context(), c.Param("id"), req))
+	})
+
+	e.DELETE("/users/:id", func(c echo.Context) error {
+		return echomodels.Write(c, svc.Delete(c.Request().Context(), c.Param("id")))
+	})
+
+	return e
+}
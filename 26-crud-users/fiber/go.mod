@@ -0,0 +1,31 @@
+module github.com/go-mizu/go-fw/26-crud-users/fiber
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/models/fibermodels v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/pagination/fiberpagination v0.0.0-00010101000000-000000000000
+	github.com/gofiber/fiber/v2 v2.52.10
+	github.com/mattn/go-sqlite3 v1.14.33
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasthttp v1.51.0 // indirect
+	github.com/valyala/tcplisten v1.0.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/models/fibermodels => ../../pkg/models/fibermodels
+
+replace github.com/go-mizu/go-fw/pkg/pagination/fiberpagination => ../../pkg/pagination/fiberpagination
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/gofiber/fiber/v2"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/fibermodels"
+	"github.com/go-mizu/go-fw/pkg/pagination/fiberpagination"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func main() {
+	db := flag.String("db", "", "SQLite database file; users are kept in memory when empty")
+	flag.Parse()
+
+	store, err := users.Open(*db)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(users.NewService(store)).Listen(":8080")
+}
+
+func routes(svc *users.Service) *fiber.App {
+	app := fiber.New()
+
+	app.Post("/users", func(c *fiber.Ctx) error {
+		var req models.CreateUserRequest
+		if err := c.BodyParser(&req); err != nil {
+			return fibermodels.Write(c, users.BadBody())
+		}
+		return fibermodels.Write(c, svc.Create(c.UserContext(), req))
+	})
+
+	app.Get("/users", func(c *fiber.Ctx) error {
+		res, links := svc.List(c.UserContext(), fiberpagination.URL(c))
+		fiberpagination.SetLinks(c, links)
+		return fibermodels.Write(c, res)
+	})
+
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		return fibermodels.Write(c, svc.Get(c.UserContext(), c.Params("id")))
+	})
+
+	app.Patch("/users/:id", func(c *fiber.Ctx) error {
+		var req models.UpdateUserRequest
+		if err := c.BodyParser(&req); err != nil {
+			return fibermodels.Write(c, users.BadBody())
+		}
+		return fibermodels.Write(c, svc.Update(c.UserContext(), c.Params("id"), req))
+	})
+
+	app.Delete("/users/:id", func(c *fiber.Ctx) error {
+		return fibermodels.Write(c, svc.Delete(c.UserContext(), c.Params("id")))
+	})
+
+	return app
+}
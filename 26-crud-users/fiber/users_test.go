@@ -0,0 +1,20 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func TestUsers(t *testing.T) {
+	for name, dsn := range map[string]string{"memory": "", "sqlite": ":memory:"} {
+		t.Run(name, func(t *testing.T) {
+			store, err := users.Open(dsn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chaptertest.Run(t, chaptertest.App(routes(users.NewService(store))))
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/ginmodels"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func main() {
+	db := flag.String("db", "", "SQLite database file; users are kept in memory when empty")
+	flag.Parse()
+
+	store, err := users.Open(*db)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(users.NewService(store)).Run(":8080")
+}
+
+func routes(svc *users.Service) *gin.Engine {
+	r := gin.New()
+
+	r.POST("/users", func(c *gin.Context) {
+		var req models.CreateUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ginmodels.Write(c, users.BadBody())
+			return
+		}
+		ginmodels.Write(c, svc.Create(c.Request.Context(), req))
+	})
+
+	r.GET("/users", func(c *gin.Context) {
+		res, links := svc.List(c.Request.Context(), c.Request.URL)
+		links.Set(c.Writer.Header())
+		ginmodels.Write(c, res)
+	})
+
+	r.GET("/users/:id", func(c *gin.Context) {
+		ginmodels.Write(c, svc.Get(c.Request.Context(), c.Param("id")))
+	})
+
+	r.PATCH("/users/:id", func(c *gin.Context) {
+		var req models.UpdateUserRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			ginmodels.Write(c, users.BadBody())
+			return
+		}
+		ginmodels.Write(c, svc.Update(c.Request.Context(), c.Param("id"), req))
+	})
+
+	r.DELETE("/users/:id", func(c *gin.Context) {
+		ginmodels.Write(c, svc.Delete(c.Request.Context(), c.Param("id")))
+	})
+
+	return r
+}
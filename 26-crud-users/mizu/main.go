@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/go-mizu/mizu"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/models/mizumodels"
+	"github.com/go-mizu/go-fw/pkg/users"
+)
+
+func main() {
+	db := flag.String("db", "", "SQLite database file; users are kept in memory when empty")
+	flag.Parse()
+
+	store, err := users.Open(*db)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(users.NewService(store)).Listen(":8080")
+}
+
+func routes(svc *users.Service) *mizu.App {
+	app := mizu.New()
+
+	app.Post("/users", func(c *mizu.Ctx) error {
+		var req models.CreateUserRequest
+		if err := c.Bind(&req); err != nil {
+			return mizumodels.Write(c, users.BadBody())
+		}
+		return mizumodels.Write(c, svc.Create(c.Request().Context(), req))
+	})
+
+	app.Get("/users", func(c *mizu.Ctx) error {
+		res, links := svc.List(c.Request().Context(), c.Request().URL)
+		links.Set(c.Writer().Header())
+		return mizumodels.Write(c, res)
+	})
+
+	app.Get("/users/:id", func(c *mizu.Ctx) error {
+		return mizumodels.Write(c, svc.Get(c.Request().Context(), c.Param("id")))
+	})
+
+	app.Patch("/users/:id", func(c *mizu.Ctx) error {
+		var req models.UpdateUserRequest
+		if err := c.Bind(&req); err != nil {
+			return mizumodels.Write(c, users.BadBody())
+		}
+		return mizumodels.Write(c, svc.Update(c.Request().Context(), c.Param("id"), req))
+	})
+
+	app.Delete("/users/:id", func(c *mizu.Ctx) error {
+		return mizumodels.Write(c, svc.Delete(c.Request().Context(), c.Param("id")))
+	})
+
+	return app
+}
@@ -0,0 +1,10 @@
+module github.com/go-mizu/go-fw/26-crud-users/nethttp
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/mattn/go-sqlite3 v1.14.33
+)
+
+replace github.com/go-mizu/go-fw => ../..
@@ -0,0 +1,10 @@
+module github.com/go-mizu/go-fw/27-strict-json/chi
+
+go 1.25
+
+require (
+	github.com/go-chi/chi/v5 v5.2.3
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+)
+
+replace github.com/go-mizu/go-fw => ../..
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/decode"
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+type Payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// a small limit, so the 413 is easy to reach
+var strict = decode.Decoder{Limit: 1 << 10}
+
+func main() {
+	http.ListenAndServe(":8080", routes())
+}
+
+func routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Method(http.MethodPost, "/echo", problem.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var p Payload
+		if err := strict.JSON(w, r, &p); err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		return json.NewEncoder(w).Encode(p)
+	}))
+
+	return r
+}
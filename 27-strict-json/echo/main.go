@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/decode"
+	"github.com/go-mizu/go-fw/pkg/problem/echoproblem"
+)
+
+type Payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// a small limit, so the 413 is easy to reach
+var strict = decode.Decoder{Limit: 1 << 10}
+
+func main() {
+	routes().Start(":8080")
+}
+
+func routes() *echo.Echo {
+	e := echo.New()
+	e.HTTPErrorHandler = echoproblem.ErrorHandler
+
+	e.POST("/echo", func(c echo.Context) error {
+		var p Payload
+		if err := strict.JSON(c.Response(), c.Request(), &p); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, p)
+	})
+
+	return e
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/decode"
+	"github.com/go-mizu/go-fw/pkg/problem/fiberproblem"
+)
+
+type Payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// a small limit, so the 413 is easy to reach
+var strict = decode.Decoder{Limit: 1 << 10}
+
+func main() {
+	routes().Listen(":8080")
+}
+
+func routes() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: fiberproblem.ErrorHandler})
+
+	app.Post("/echo", func(c *fiber.Ctx) error {
+		var p Payload
+		if err := strict.Bytes(c.Get(fiber.HeaderContentType), c.Body(), &p); err != nil {
+			return err
+		}
+
+		return c.JSON(p)
+	})
+
+	return app
+}
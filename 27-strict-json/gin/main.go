@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/decode"
+	"github.com/go-mizu/go-fw/pkg/problem/ginproblem"
+)
+
+type Payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// a small limit, so the 413 is easy to reach
+var strict = decode.Decoder{Limit: 1 << 10}
+
+func main() {
+	routes().Run(":8080")
+}
+
+func routes() *gin.Engine {
+	r := gin.New()
+
+	r.POST("/echo", func(c *gin.Context) {
+		var p Payload
+		if err := strict.JSON(c.Writer, c.Request, &p); err != nil {
+			ginproblem.Abort(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	})
+
+	return r
+}
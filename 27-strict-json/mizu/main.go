@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/decode"
+	"github.com/go-mizu/go-fw/pkg/problem/mizuproblem"
+)
+
+type Payload struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// a small limit, so the 413 is easy to reach
+var strict = decode.Decoder{Limit: 1 << 10}
+
+func main() {
+	http.ListenAndServe(":8080", routes())
+}
+
+func routes() http.Handler {
+	app := mizu.New()
+	app.Use(mizuproblem.Middleware())
+
+	app.Post("/echo", func(c *mizu.Ctx) error {
+		var p Payload
+		if err := strict.JSON(c.Writer(), c.Request(), &p); err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, p)
+	})
+
+	return mizuproblem.Handler(app)
+}
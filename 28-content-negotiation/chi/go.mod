@@ -0,0 +1,23 @@
+module github.com/go-mizu/go-fw/28-content-negotiation/chi
+
+go 1.25
+
+require (
+	github.com/go-chi/chi/v5 v5.2.3
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/cborformat v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.2 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/cborformat => ../../pkg/negotiate/cborformat
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat => ../../pkg/negotiate/msgpackformat
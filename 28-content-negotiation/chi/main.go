@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/cborformat"
+	"github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat"
+)
+
+// formats lists what the server can answer with, in order of preference
+var formats = negotiate.New(
+	negotiate.JSON,
+	negotiate.XML,
+	msgpackformat.Format,
+	cborformat.Format,
+	negotiate.Text,
+)
+
+var user = models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+func main() {
+	http.ListenAndServe(":8080", routes())
+}
+
+func routes() http.Handler {
+	r := chi.NewRouter()
+
+	r.Get("/user", func(w http.ResponseWriter, r *http.Request) {
+		formats.Write(w, r, models.OK(user))
+	})
+
+	r.Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		formats.Write(w, r, models.Fail(http.StatusNotFound, "User not found"))
+	})
+
+	return r
+}
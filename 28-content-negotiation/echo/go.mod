@@ -0,0 +1,32 @@
+module github.com/go-mizu/go-fw/28-content-negotiation/echo
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/cborformat v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat v0.0.0-00010101000000-000000000000
+	github.com/labstack/echo/v4 v4.14.0
+)
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.2 // indirect
+	github.com/labstack/gommon v0.4.2 // indirect
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.2 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/crypto v0.46.0 // indirect
+	golang.org/x/net v0.48.0 // indirect
+	golang.org/x/sys v0.39.0 // indirect
+	golang.org/x/text v0.32.0 // indirect
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/cborformat => ../../pkg/negotiate/cborformat
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat => ../../pkg/negotiate/msgpackformat
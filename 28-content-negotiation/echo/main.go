@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/cborformat"
+	"github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat"
+)
+
+// formats lists what the server can answer with, in order of preference
+var formats = negotiate.New(
+	negotiate.JSON,
+	negotiate.XML,
+	msgpackformat.Format,
+	cborformat.Format,
+	negotiate.Text,
+)
+
+var user = models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+func main() {
+	routes().Start(":8080")
+}
+
+func routes() *echo.Echo {
+	e := echo.New()
+
+	e.GET("/user", func(c echo.Context) error {
+		formats.Write(c.Response(), c.Request(), models.OK(user))
+		return nil
+	})
+
+	e.GET("/missing", func(c echo.Context) error {
+		formats.Write(c.Response(), c.Request(), models.Fail(http.StatusNotFound, "User not found"))
+		return nil
+	})
+
+	return e
+}
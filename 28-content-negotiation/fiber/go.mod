@@ -0,0 +1,40 @@
+module github.com/go-mizu/go-fw/28-content-negotiation/fiber
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/cborformat v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/fibernegotiate v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/go-fw/pkg/problem/fiberproblem v0.0.0-00010101000000-000000000000
+	github.com/gofiber/fiber/v2 v2.52.10
+)
+
+require (
+	github.com/andybalholm/brotli v1.1.0 // indirect
+	github.com/fxamacker/cbor/v2 v2.9.2 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/klauspost/compress v1.17.9 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/mattn/go-runewidth v0.0.16 // indirect
+	github.com/rivo/uniseg v0.2.0 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasthttp v1.51.0 // indirect
+	github.com/valyala/tcplisten v1.0.0 // indirect
+	github.com/vmihailenco/msgpack/v5 v5.4.1 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+)
+
+replace github.com/go-mizu/go-fw => ../..
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/cborformat => ../../pkg/negotiate/cborformat
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/fibernegotiate => ../../pkg/negotiate/fibernegotiate
+
+replace github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat => ../../pkg/negotiate/msgpackformat
+
+replace github.com/go-mizu/go-fw/pkg/problem/fiberproblem => ../../pkg/problem/fiberproblem
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/cborformat"
+	"github.com/go-mizu/go-fw/pkg/negotiate/fibernegotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat"
+	"github.com/go-mizu/go-fw/pkg/problem/fiberproblem"
+)
+
+// formats lists what the server can answer with, in order of preference
+var formats = negotiate.New(
+	negotiate.JSON,
+	negotiate.XML,
+	msgpackformat.Format,
+	cborformat.Format,
+	negotiate.Text,
+)
+
+var user = models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+func main() {
+	routes().Listen(":8080")
+}
+
+func routes() *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: fiberproblem.ErrorHandler})
+
+	app.Get("/user", func(c *fiber.Ctx) error {
+		return fibernegotiate.Write(c, formats, models.OK(user))
+	})
+
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return fibernegotiate.Write(c, formats, models.Fail(http.StatusNotFound, "User not found"))
+	})
+
+	return app
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+)
+
+func TestNegotiation(t *testing.T) {
+	chaptertest.Run(t, chaptertest.App(routes()))
+}
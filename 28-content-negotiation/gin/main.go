@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/cborformat"
+	"github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat"
+)
+
+// formats lists what the server can answer with, in order of preference
+var formats = negotiate.New(
+	negotiate.JSON,
+	negotiate.XML,
+	msgpackformat.Format,
+	cborformat.Format,
+	negotiate.Text,
+)
+
+var user = models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+func main() {
+	routes().Run(":8080")
+}
+
+func routes() *gin.Engine {
+	r := gin.New()
+
+	r.GET("/user", func(c *gin.Context) {
+		formats.Write(c.Writer, c.Request, models.OK(user))
+	})
+
+	r.GET("/missing", func(c *gin.Context) {
+		formats.Write(c.Writer, c.Request, models.Fail(http.StatusNotFound, "User not found"))
+	})
+
+	return r
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/negotiate/cborformat"
+	"github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat"
+)
+
+// formats lists what the server can answer with, in order of preference
+var formats = negotiate.New(
+	negotiate.JSON,
+	negotiate.XML,
+	msgpackformat.Format,
+	cborformat.Format,
+	negotiate.Text,
+)
+
+var user = models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+func main() {
+	routes().Listen(":8080")
+}
+
+func routes() *mizu.App {
+	app := mizu.New()
+
+	app.Get("/user", func(c *mizu.Ctx) error {
+		formats.Write(c.Writer(), c.Request(), models.OK(user))
+		return nil
+	})
+
+	app.Get("/missing", func(c *mizu.Ctx) error {
+		formats.Write(c.Writer(), c.Request(), models.Fail(http.StatusNotFound, "User not found"))
+		return nil
+	})
+
+	return app
+}
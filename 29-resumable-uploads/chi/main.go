@@ -0,0 +1,32 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join(os.TempDir(), "tus"), "directory for the uploads")
+	flag.Parse()
+
+	store, err := tus.NewFileStore(*dir)
+	if err != nil {
+		panic(err)
+	}
+
+	http.ListenAndServe(":8080", routes(&tus.Handler{Store: store, BasePath: "/files/"}))
+}
+
+func routes(uploads *tus.Handler) http.Handler {
+	r := chi.NewRouter()
+
+	r.Handle("/files/*", uploads)
+
+	return r
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/chaptertest"
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func TestTus(t *testing.T) {
+	store, err := tus.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chaptertest.Run(t, chaptertest.Handler(routes(&tus.Handler{Store: store, BasePath: "/files/"})))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join(os.TempDir(), "tus"), "directory for the uploads")
+	flag.Parse()
+
+	store, err := tus.NewFileStore(*dir)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(&tus.Handler{Store: store, BasePath: "/files/"}).Start(":8080")
+}
+
+func routes(uploads *tus.Handler) *echo.Echo {
+	e := echo.New()
+
+	e.Any("/files/*", echo.WrapHandler(uploads))
+
+	return e
+}
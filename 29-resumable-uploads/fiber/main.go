@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join(os.TempDir(), "tus"), "directory for the uploads")
+	flag.Parse()
+
+	store, err := tus.NewFileStore(*dir)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(&tus.Handler{Store: store, BasePath: "/files/"}).Listen(":8080")
+}
+
+func routes(uploads *tus.Handler) *fiber.App {
+	app := fiber.New(fiber.Config{
+		// a PATCH is read whole before the handler runs; room for a
+		// 4 MiB client chunk and more
+		BodyLimit: 16 << 20,
+	})
+
+	app.All("/files/*", adaptor.HTTPHandler(uploads))
+
+	return app
+}
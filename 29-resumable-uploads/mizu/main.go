@@ -0,0 +1,38 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join(os.TempDir(), "tus"), "directory for the uploads")
+	flag.Parse()
+
+	store, err := tus.NewFileStore(*dir)
+	if err != nil {
+		panic(err)
+	}
+
+	routes(&tus.Handler{Store: store, BasePath: "/files/"}).Listen(":8080")
+}
+
+func routes(uploads *tus.Handler) *mizu.App {
+	app := mizu.New()
+
+	serve := func(c *mizu.Ctx) error {
+		uploads.ServeHTTP(c.Writer(), c.Request())
+		return nil
+	}
+	for _, method := range []string{http.MethodOptions, http.MethodPost, http.MethodHead, http.MethodPatch, http.MethodDelete} {
+		app.Handle(method, "/files/*path", serve)
+	}
+
+	return app
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+func main() {
+	dir := flag.String("dir", filepath.Join(os.TempDir(), "tus"), "directory for the uploads")
+	flag.Parse()
+
+	store, err := tus.NewFileStore(*dir)
+	if err != nil {
+		panic(err)
+	}
+
+	http.ListenAndServe(":8080", routes(&tus.Handler{Store: store, BasePath: "/files/"}))
+}
+
+func routes(uploads *tus.Handler) http.Handler {
+	mux := http.NewServeMux()
+
+	// every method; the handler answers the ones tus uses
+	mux.Handle("/files/", uploads)
+
+	return mux
+}
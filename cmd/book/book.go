@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/highlight"
+	"github.com/go-mizu/go-fw/pkg/markdown"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// introFile opens the book; every chapter README follows it in directory
+// order.
+const introFile = "README.md"
+
+// defaultTitle is used when there is no README.md to take the title from.
+const defaultTitle = "Go HTTP Frameworks"
+
+// part is a page of the HTML and EPUB outputs: the introduction, one
+// chapter, or the framework index.
+type part struct {
+	Name   string // file name without extension
+	Title  string
+	Shift  int // added to heading levels when the part is its own page
+	Blocks []*markdown.Block
+
+	Snippets []snippet // the chapter's framework code, for comparison
+}
+
+type book struct {
+	Title string
+	Parts []*part
+	pages map[string]string // heading ID -> part name
+}
+
+// load parses the READMEs and assigns every heading a book-wide unique
+// anchor. Chapter headings move down one level and their anchors are
+// qualified by the chapter directory, so the six "## net/http" sections stay
+// distinct. A contents list follows the book title and a per-framework
+// index closes the book. Each chapter ends with an "At a glance" table
+// comparing the code in its framework directories.
+func load() (*book, error) {
+	b := &book{Title: defaultTitle, pages: map[string]string{}}
+	ids := anchors{}
+
+	intro, err := readDoc(introFile)
+	switch {
+	case os.IsNotExist(err):
+		intro = &markdown.Document{Blocks: []*markdown.Block{{Kind: markdown.Heading, Level: 1, Text: defaultTitle}}}
+	case err != nil:
+		return nil, err
+	}
+	for _, h := range intro.Headings() {
+		h.ID = ids.unique(markdown.Slug(h.Text))
+		if h.Level == 1 && b.Title == defaultTitle {
+			b.Title = h.Text
+		}
+	}
+	introPart := &part{Name: "index", Title: b.Title, Blocks: intro.Blocks}
+	b.Parts = append(b.Parts, introPart)
+
+	readmes, err := filepath.Glob("[0-9][0-9]-*/README.md")
+	if err != nil {
+		return nil, err
+	}
+
+	var chapters []*part
+	for _, readme := range readmes {
+		doc, err := readDoc(readme)
+		if err != nil {
+			return nil, err
+		}
+
+		dir := filepath.Dir(readme)
+		ch := &part{Name: dir, Title: dir, Shift: -1, Blocks: doc.Blocks}
+
+		for _, h := range doc.Headings() {
+			if h.Level == 1 {
+				h.ID = ids.unique(dir)
+				ch.Title = h.Text
+			} else {
+				h.ID = ids.unique(dir + "-" + markdown.Slug(h.Text))
+			}
+			h.Level = min(h.Level+1, 6)
+		}
+
+		ch.Snippets, err = compare(dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(ch.Snippets) > 0 {
+			ch.Blocks = appendPart(ch.Blocks, glance(dir, 3, ch.Snippets, ids))
+		}
+
+		chapters = append(chapters, ch)
+	}
+	b.Parts = append(b.Parts, chapters...)
+	b.Parts = append(b.Parts, index(chapters, ids))
+
+	for _, p := range b.Parts {
+		for _, blk := range p.Blocks {
+			if blk.Kind == markdown.Heading {
+				b.pages[blk.ID] = p.Name
+			}
+		}
+	}
+
+	b.linkChapters()
+
+	// The contents list goes before the first section of the introduction,
+	// after the title and its lead paragraphs.
+	toc := b.contents(ids)
+	at := len(introPart.Blocks)
+	for i, blk := range introPart.Blocks {
+		if blk.Kind == markdown.Heading && blk.Level == 2 {
+			at = i
+			break
+		}
+	}
+	toc = append(toc, introPart.Blocks[at:]...)
+	introPart.Blocks = append(introPart.Blocks[:at], toc...)
+	b.pages[toc[0].ID] = introPart.Name
+
+	return b, nil
+}
+
+func readDoc(path string) (*markdown.Document, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return markdown.Parse(src), nil
+}
+
+// markdown renders the whole book as one Markdown file.
+func (b *book) markdown() []byte {
+	doc := &markdown.Document{}
+	for _, p := range b.Parts {
+		doc.Blocks = appendPart(doc.Blocks, p.Blocks)
+	}
+	return doc.Markdown()
+}
+
+// appendPart adds blocks after a blank line so parts never run together.
+func appendPart(doc, blocks []*markdown.Block) []*markdown.Block {
+	if len(doc) > 0 {
+		last := doc[len(doc)-1]
+		if last.Kind != markdown.Text || last.Lines[len(last.Lines)-1] != "" {
+			doc = append(doc, &markdown.Block{Kind: markdown.Text, Lines: []string{""}})
+		}
+	}
+	return append(doc, blocks...)
+}
+
+// html renders one part as an XHTML fragment. Links to anchors on other
+// pages get the page file name, with ext as its extension.
+func (b *book) html(p *part, ext string) string {
+	return markdown.HTML(p.Blocks, markdown.HTMLOptions{
+		Shift: p.Shift,
+		Link: func(href string) string {
+			id, ok := strings.CutPrefix(href, "#")
+			if !ok {
+				return href
+			}
+			page, ok := b.pages[id]
+			if !ok || page == p.Name {
+				return href
+			}
+			return page + ext + href
+		},
+		Highlight: func(lang, code string) (string, bool) {
+			if lang != "go" {
+				return "", false
+			}
+			return highlight.Go(code), true
+		},
+	})
+}
+
+// sections returns the level 2 and 3 headings of p, the entries of the
+// contents list and the EPUB navigation document.
+func sections(p *part) []*markdown.Block {
+	var out []*markdown.Block
+	for _, blk := range p.Blocks {
+		if blk.Kind == markdown.Heading && (blk.Level == 2 || blk.Level == 3) {
+			out = append(out, blk)
+		}
+	}
+	return out
+}
+
+// anchors hands out IDs that are unique across the book.
+type anchors map[string]bool
+
+func (a anchors) unique(id string) string {
+	out := id
+	for n := 1; a[out]; n++ {
+		out = id + "-" + strconv.Itoa(n)
+	}
+	a[out] = true
+	return out
+}
+
+// contents lists every level 2 heading of the book with its level 3
+// children.
+func (b *book) contents(ids anchors) []*markdown.Block {
+	lines := []string{""}
+	for _, p := range b.Parts {
+		for _, h := range sections(p) {
+			indent := strings.Repeat("  ", h.Level-2)
+			lines = append(lines, fmt.Sprintf("%s- [%s](#%s)", indent, h.Text, h.ID))
+		}
+	}
+	lines = append(lines, "")
+
+	return []*markdown.Block{
+		{Kind: markdown.Heading, Level: 2, Text: "Contents", ID: ids.unique("contents")},
+		{Kind: markdown.Text, Lines: lines},
+	}
+}
+
+// index lists, for each framework, the chapter sections written for it.
+func index(chapters []*part, ids anchors) *part {
+	p := &part{Name: "frameworks", Title: "Index by framework", Shift: -1}
+	p.Blocks = []*markdown.Block{
+		{Kind: markdown.Heading, Level: 2, Text: p.Title, ID: ids.unique("index")},
+		{Kind: markdown.Text, Lines: []string{""}},
+	}
+
+	for _, fw := range registry.Frameworks {
+		lines := []string{""}
+		for _, ch := range chapters {
+			for _, blk := range ch.Blocks {
+				if blk.Kind == markdown.Heading && blk.Level == 3 && blk.Text == fw.Title {
+					lines = append(lines, fmt.Sprintf("- [%s](#%s)", ch.Title, blk.ID))
+				}
+			}
+		}
+		lines = append(lines, "")
+
+		p.Blocks = append(p.Blocks,
+			&markdown.Block{Kind: markdown.Heading, Level: 3, Text: fw.Title, ID: ids.unique("index-" + fw.Dir)},
+			&markdown.Block{Kind: markdown.Text, Lines: lines},
+		)
+	}
+
+	return p
+}
+
+// chapterLink matches links to a chapter README, such as
+// (01-hello-world/README.md) or (../04-routing/#gin).
+var chapterLink = regexp.MustCompile(`\]\((?:\.\./|\./)?([0-9][0-9]-[a-z0-9-]+)/(?:README\.md)?(?:#([^)\s]*))?\)`)
+
+// linkChapters points links between chapters at the anchors inside the book.
+func (b *book) linkChapters() {
+	for _, p := range b.Parts {
+		for _, blk := range p.Blocks {
+			if blk.Kind != markdown.Text {
+				continue
+			}
+			for i, line := range blk.Lines {
+				blk.Lines[i] = chapterLink.ReplaceAllStringFunc(line, func(m string) string {
+					sub := chapterLink.FindStringSubmatch(m)
+					dir, frag := sub[1], sub[2]
+					if _, ok := b.pages[dir]; !ok {
+						return m
+					}
+					if _, ok := b.pages[dir+"-"+frag]; frag != "" && ok {
+						return "](#" + dir + "-" + frag + ")"
+					}
+					return "](#" + dir + ")"
+				})
+			}
+		}
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
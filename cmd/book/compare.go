@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"html"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/highlight"
+	"github.com/go-mizu/go-fw/pkg/markdown"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// snippet is the extracted code of one framework variant of a chapter.
+type snippet struct {
+	Framework registry.Framework
+	Files     []string
+	Source    string   // every file, each introduced by a // file: comment
+	Lines     int      // lines holding code, not blank or comment-only
+	Imports   []string // import paths, sorted
+	APIs      []string // framework identifiers used, e.g. gin.New or Context.JSON
+}
+
+// compare analyzes the extracted code of every framework in dir. Missing
+// variants are left out.
+func compare(dir string) ([]snippet, error) {
+	var out []snippet
+
+	for _, fw := range registry.Frameworks {
+		files, err := filepath.Glob(filepath.Join(dir, fw.Dir, "*.go"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		sort.Strings(files)
+
+		s, err := analyze(fw, files)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+
+	return out, nil
+}
+
+func analyze(fw registry.Framework, files []string) (snippet, error) {
+	s := snippet{Framework: fw}
+	fset := token.NewFileSet()
+
+	imports := map[string]bool{}
+	apis := map[string]bool{}
+	var sources []string
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return s, err
+		}
+
+		f, err := parser.ParseFile(fset, file, src, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return s, err
+		}
+
+		s.Files = append(s.Files, filepath.Base(file))
+		s.Lines += codeLines(src)
+		if len(files) > 1 {
+			sources = append(sources, "// file: "+filepath.Base(file)+"\n"+string(src))
+		} else {
+			sources = append(sources, string(src))
+		}
+
+		for _, imp := range f.Imports {
+			p, _ := strconv.Unquote(imp.Path.Value)
+			imports[p] = true
+		}
+
+		for name := range frameworkAPIs(f, fw) {
+			apis[name] = true
+		}
+	}
+
+	s.Source = strings.Join(sources, "\n")
+	s.Imports = sortedKeys(imports)
+	s.APIs = sortedKeys(apis)
+
+	return s, nil
+}
+
+// codeLines counts the lines that hold at least one token other than a
+// comment.
+func codeLines(src []byte) int {
+	var sc scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	sc.Init(file, src, nil, 0)
+
+	lines := map[int]bool{}
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+
+		start := file.Line(pos)
+		end := start + strings.Count(lit, "\n")
+		for l := start; l <= end; l++ {
+			lines[l] = true
+		}
+	}
+
+	return len(lines)
+}
+
+// frameworkAPIs returns the identifiers of the framework package that f uses: qualified
+// names such as gin.Default, plus methods called on parameters declared
+// with a framework type, such as Context.JSON for c *gin.Context. Without
+// type checking, methods on values of inferred type are not seen.
+func frameworkAPIs(f *ast.File, fw registry.Framework) map[string]bool {
+	name := ""
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		if p != fw.ImportPath() {
+			continue
+		}
+		name = fw.PackageName()
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+	}
+
+	apis := map[string]bool{}
+	if name == "" || name == "_" || name == "." {
+		return apis
+	}
+
+	// typed maps an identifier to the framework type it was declared with
+	typed := map[string]string{}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Field:
+			if t := qualifiedType(n.Type, name); t != "" {
+				for _, id := range n.Names {
+					typed[id.Name] = t
+				}
+			}
+		case *ast.SelectorExpr:
+			id, ok := n.X.(*ast.Ident)
+			if !ok {
+				break
+			}
+			if id.Name == name {
+				apis[name+"."+n.Sel.Name] = true
+			} else if t, ok := typed[id.Name]; ok && n.Sel.IsExported() {
+				apis[t+"."+n.Sel.Name] = true
+			}
+		}
+		return true
+	})
+
+	return apis
+}
+
+// qualifiedType returns T for an expression of type pkg.T or *pkg.T.
+func qualifiedType(expr ast.Expr, pkg string) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	sel, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if id, ok := sel.X.(*ast.Ident); ok && id.Name == pkg {
+		return sel.Sel.Name
+	}
+	return ""
+}
+
+// glance is the "At a glance" section closing every chapter: a table of
+// line counts, imports and framework APIs for each variant.
+func glance(dir string, level int, snippets []snippet, ids anchors) []*markdown.Block {
+	lines := []string{
+		"",
+		"Derived from the extracted code of each framework directory.",
+		"",
+		"| Framework | Code lines | Imports | Framework APIs used |",
+		"|---|---:|---|---|",
+	}
+
+	for _, s := range snippets {
+		lines = append(lines, fmt.Sprintf("| %s | %d | %s | %s |",
+			s.Framework.Title, s.Lines, codeList(s.Imports), codeList(s.APIs)))
+	}
+	lines = append(lines, "")
+
+	return []*markdown.Block{
+		{Kind: markdown.Heading, Level: level, Text: "At a glance", ID: ids.unique(dir + "-at-a-glance")},
+		{Kind: markdown.Text, Lines: lines},
+	}
+}
+
+func codeList(items []string) string {
+	if len(items) == 0 {
+		return "-"
+	}
+	quoted := make([]string, len(items))
+	for i, it := range items {
+		quoted[i] = "`" + it + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// compareStyle widens the page and lays the variants out in columns.
+const compareStyle = `body.compare { max-width: none; }
+.grid { display: grid; grid-template-columns: repeat(auto-fill, minmax(34em, 1fr)); gap: 1em; }
+.grid section { min-width: 0; }
+.grid h2 { font-size: 1.1em; margin-bottom: 0.2em; }
+.grid p.stats { color: #555; font-size: 0.85em; margin: 0 0 0.5em; }
+`
+
+// comparePage renders the side by side view of one chapter for the site.
+func comparePage(b *book, p *part) []byte {
+	var sb strings.Builder
+
+	title := p.Title + ": side by side"
+	sb.WriteString("<!DOCTYPE html>\n")
+	sb.WriteString(`<html lang="en">` + "\n<head>\n")
+	sb.WriteString(`<meta charset="utf-8" />` + "\n")
+	sb.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1" />` + "\n")
+	fmt.Fprintf(&sb, "<title>%s - %s</title>\n", html.EscapeString(title), html.EscapeString(b.Title))
+	sb.WriteString(`<link rel="stylesheet" href="style.css" />` + "\n</head>\n")
+	sb.WriteString(`<body class="compare">` + "\n")
+	fmt.Fprintf(&sb, "<nav class=\"pager top\"><a href=\"%s.html\">&larr; %s</a><a href=\"index.html#contents\">Contents</a><span></span></nav>\n",
+		p.Name, html.EscapeString(p.Title))
+	fmt.Fprintf(&sb, "<h1>%s</h1>\n<div class=\"grid\">\n", html.EscapeString(title))
+
+	for _, s := range p.Snippets {
+		sb.WriteString("<section>\n")
+		fmt.Fprintf(&sb, "<h2><a href=\"%s.html#%s\">%s</a></h2>\n", p.Name, p.Name+"-"+markdown.Slug(s.Framework.Title), html.EscapeString(s.Framework.Title))
+		fmt.Fprintf(&sb, "<p class=\"stats\">%d code lines, %d imports, %d framework APIs</p>\n", s.Lines, len(s.Imports), len(s.APIs))
+		fmt.Fprintf(&sb, "<pre><code class=\"language-go\">%s</code></pre>\n", highlight.Go(s.Source))
+		sb.WriteString("</section>\n")
+	}
+
+	sb.WriteString("</div>\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"strings"
+	"time"
+)
+
+// epubDir holds the publication inside the container.
+const epubDir = "EPUB/"
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="EPUB/package.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// epub returns the book as an EPUB 3 container: a package document, a
+// navigation document and one XHTML content document per part. Entries are
+// written in a fixed order with a fixed timestamp so the archive is
+// reproducible.
+func (b *book) epub(modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	date, clock := dosTime(modified)
+
+	add := func(name string, data []byte, method uint16) error {
+		h := &zip.FileHeader{Name: name, Method: method, ModifiedDate: date, ModifiedTime: clock}
+
+		if method == zip.Store {
+			// Written raw so the entry has no data descriptor; readers
+			// sniff the mimetype at a fixed offset.
+			h.CRC32 = crc32.ChecksumIEEE(data)
+			h.CompressedSize64 = uint64(len(data))
+			h.UncompressedSize64 = uint64(len(data))
+			w, err := zw.CreateRaw(h)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(data)
+			return err
+		}
+
+		w, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	// The mimetype entry must come first and be stored uncompressed.
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"mimetype", []byte("application/epub+zip")},
+		{"META-INF/container.xml", []byte(containerXML)},
+		{epubDir + "package.opf", b.opf(modified)},
+		{epubDir + "nav.xhtml", b.nav()},
+		{epubDir + "style.css", []byte(style)},
+	}
+	for _, p := range b.Parts {
+		files = append(files, struct {
+			name string
+			data []byte
+		}{epubDir + p.Name + ".xhtml", b.xhtml(p)})
+	}
+
+	for i, f := range files {
+		method := zip.Deflate
+		if i == 0 {
+			method = zip.Store
+		}
+		if err := add(f.name, f.data, method); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// dosTime converts t to the MS-DOS date and time fields of a zip header.
+// Setting them directly, rather than FileHeader.Modified, keeps the
+// extended timestamp extra field out of the archive, which EPUB checkers
+// reject on the mimetype entry.
+func dosTime(t time.Time) (date, clock uint16) {
+	t = t.UTC()
+	date = uint16(t.Year()-1980)<<9 | uint16(t.Month())<<5 | uint16(t.Day())
+	clock = uint16(t.Hour())<<11 | uint16(t.Minute())<<5 | uint16(t.Second()/2)
+	return date, clock
+}
+
+// identifier derives a stable name-based UUID (version 5 layout) from the
+// book title, so the package identifier survives regeneration.
+func (b *book) identifier() string {
+	sum := sha1.Sum([]byte("github.com/go-mizu/go-fw/" + b.Title))
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+func (b *book) opf(modified time.Time) []byte {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	sb.WriteString(`<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="uid" xml:lang="en">` + "\n")
+	sb.WriteString(`  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">` + "\n")
+	fmt.Fprintf(&sb, "    <dc:identifier id=\"uid\">%s</dc:identifier>\n", b.identifier())
+	fmt.Fprintf(&sb, "    <dc:title>%s</dc:title>\n", html.EscapeString(b.Title))
+	sb.WriteString("    <dc:language>en</dc:language>\n")
+	fmt.Fprintf(&sb, "    <meta property=\"dcterms:modified\">%s</meta>\n", modified.UTC().Format("2006-01-02T15:04:05Z"))
+	sb.WriteString("  </metadata>\n")
+
+	sb.WriteString("  <manifest>\n")
+	sb.WriteString(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>` + "\n")
+	sb.WriteString(`    <item id="style" href="style.css" media-type="text/css"/>` + "\n")
+	for _, p := range b.Parts {
+		fmt.Fprintf(&sb, "    <item id=\"p-%s\" href=\"%s.xhtml\" media-type=\"application/xhtml+xml\"/>\n", p.Name, p.Name)
+	}
+	sb.WriteString("  </manifest>\n")
+
+	sb.WriteString("  <spine>\n")
+	for _, p := range b.Parts {
+		fmt.Fprintf(&sb, "    <itemref idref=\"p-%s\"/>\n", p.Name)
+	}
+	sb.WriteString("  </spine>\n")
+	sb.WriteString("</package>\n")
+
+	return []byte(sb.String())
+}
+
+// nav is the EPUB navigation document: every part, with its sections nested
+// below it.
+func (b *book) nav() []byte {
+	var sb strings.Builder
+	sb.WriteString(xhtmlHead("Contents", false))
+	sb.WriteString(`<nav epub:type="toc" id="toc">` + "\n<h1>Contents</h1>\n<ol>\n")
+
+	for _, p := range b.Parts {
+		fmt.Fprintf(&sb, "<li><a href=\"%s.xhtml\">%s</a>", p.Name, html.EscapeString(p.Title))
+
+		// the intro's own sections are the book's front matter; only the
+		// chapters and the index list their sections
+		if p.Shift != 0 {
+			var items []string
+			for _, h := range sections(p) {
+				if h.Level == 3 {
+					items = append(items, fmt.Sprintf("<li><a href=\"%s.xhtml#%s\">%s</a></li>", p.Name, h.ID, html.EscapeString(h.Text)))
+				}
+			}
+			if len(items) > 0 {
+				sb.WriteString("\n<ol>\n" + strings.Join(items, "\n") + "\n</ol>\n")
+			}
+		}
+		sb.WriteString("</li>\n")
+	}
+
+	sb.WriteString("</ol>\n</nav>\n</body>\n</html>\n")
+	return []byte(sb.String())
+}
+
+func (b *book) xhtml(p *part) []byte {
+	return []byte(xhtmlHead(p.Title, true) + b.html(p, ".xhtml") + "</body>\n</html>\n")
+}
+
+func xhtmlHead(title string, css bool) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	sb.WriteString("<!DOCTYPE html>\n")
+	sb.WriteString(`<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="en" lang="en">` + "\n")
+	sb.WriteString("<head>\n")
+	fmt.Fprintf(&sb, "<title>%s</title>\n", html.EscapeString(title))
+	if css {
+		sb.WriteString(`<link rel="stylesheet" type="text/css" href="style.css"/>` + "\n")
+	}
+	sb.WriteString("</head>\n<body>\n")
+	return sb.String()
+}
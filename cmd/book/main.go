@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// book renders the book from README.md and the chapter READMEs: BOOK.md,
+// BOOK.epub and, with -html, a static multi-page site. Every output is a
+// pure function of the READMEs and the chapter code, so CI can regenerate
+// and compare them.
+func main() {
+	var (
+		mdOut   = flag.String("o", "BOOK.md", "Markdown output file")
+		epubOut = flag.String("epub", "BOOK.epub", "EPUB 3 output file, empty to skip")
+		htmlOut = flag.String("html", "", "directory for the static HTML site, empty to skip")
+		check   = flag.Bool("check", false, "exit non-zero if an output file is not up to date instead of writing it")
+	)
+	flag.Parse()
+
+	b, err := load()
+	if err != nil {
+		panic(err)
+	}
+
+	outputs := map[string][]byte{*mdOut: b.markdown()}
+
+	if *epubOut != "" {
+		data, err := b.epub(modified())
+		if err != nil {
+			panic(err)
+		}
+		outputs[*epubOut] = data
+	}
+
+	if *htmlOut != "" {
+		for name, data := range b.site() {
+			outputs[filepath.Join(*htmlOut, name)] = data
+		}
+	}
+
+	stale := 0
+	for _, path := range sortedKeys(outputs) {
+		data := outputs[path]
+
+		if *check {
+			current, err := os.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				panic(err)
+			}
+			if !bytes.Equal(current, data) {
+				fmt.Printf("%s is out of date; run go run ./cmd/book\n", path)
+				stale++
+			}
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			panic(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			panic(err)
+		}
+		fmt.Println("generated:", path)
+	}
+
+	if stale > 0 {
+		os.Exit(1)
+	}
+}
+
+// modified is the timestamp stamped into the EPUB. It honors
+// SOURCE_DATE_EPOCH and otherwise uses 1980-01-01, the earliest time a zip
+// entry can hold, so rebuilding unchanged chapters gives identical bytes.
+func modified() time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		var sec int64
+		if _, err := fmt.Sscan(epoch, &sec); err == nil {
+			return time.Unix(sec, 0).UTC()
+		}
+	}
+	return time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// style is shared by the HTML site and the EPUB. The token classes match
+// pkg/highlight.
+const style = `body { font-family: Georgia, serif; line-height: 1.5; margin: 0 auto; max-width: 48em; padding: 0 1em; }
+h1, h2, h3, h4 { font-family: Helvetica, Arial, sans-serif; line-height: 1.2; }
+nav.pager { display: flex; justify-content: space-between; border-bottom: 1px solid #ddd; margin: 1em 0; padding: 0.5em 0; font-family: Helvetica, Arial, sans-serif; }
+nav.pager.bottom { border-bottom: none; border-top: 1px solid #ddd; }
+pre { background: #f6f8fa; overflow-x: auto; padding: 0.75em; font-size: 0.85em; line-height: 1.4; }
+code { font-family: Menlo, Consolas, monospace; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 0.3em 0.6em; vertical-align: top; }
+blockquote { border-left: 4px solid #ddd; color: #555; margin-left: 0; padding-left: 1em; }
+.c { color: #6a737d; font-style: italic; }
+.s { color: #032f62; }
+.n { color: #005cc5; }
+.k { color: #d73a49; font-weight: bold; }
+.b { color: #6f42c1; }
+`
+
+// site returns the files of the static HTML site: one page per part, a side
+// by side page per chapter with code, and a stylesheet.
+func (b *book) site() map[string][]byte {
+	files := map[string][]byte{"style.css": []byte(style + compareStyle)}
+
+	for i, p := range b.Parts {
+		var prev, next *part
+		if i > 0 {
+			prev = b.Parts[i-1]
+		}
+		if i+1 < len(b.Parts) {
+			next = b.Parts[i+1]
+		}
+
+		pager := func(class string) string {
+			var sb strings.Builder
+			sb.WriteString(`<nav class="` + class + `">`)
+			sb.WriteString(pageLink(prev, "&larr; ", ""))
+			sb.WriteString(`<a href="index.html#contents">Contents</a>`)
+			sb.WriteString(pageLink(next, "", " &rarr;"))
+			sb.WriteString("</nav>\n")
+			return sb.String()
+		}
+
+		var sb strings.Builder
+		sb.WriteString("<!DOCTYPE html>\n")
+		sb.WriteString(`<html lang="en">` + "\n<head>\n")
+		sb.WriteString(`<meta charset="utf-8" />` + "\n")
+		sb.WriteString(`<meta name="viewport" content="width=device-width, initial-scale=1" />` + "\n")
+		fmt.Fprintf(&sb, "<title>%s</title>\n", pageTitle(b, p))
+		sb.WriteString(`<link rel="stylesheet" href="style.css" />` + "\n</head>\n<body>\n")
+		sb.WriteString(pager("pager top"))
+		sb.WriteString(b.html(p, ".html"))
+		if len(p.Snippets) > 0 {
+			fmt.Fprintf(&sb, "<p><a href=\"%s-compare.html\">Compare the frameworks side by side</a></p>\n", p.Name)
+			files[p.Name+"-compare.html"] = comparePage(b, p)
+		}
+		sb.WriteString(pager("pager bottom"))
+		sb.WriteString("</body>\n</html>\n")
+
+		files[p.Name+".html"] = []byte(sb.String())
+	}
+
+	return files
+}
+
+// pageLink links to the previous or next part; an empty span keeps the
+// pager layout when there is none.
+func pageLink(p *part, before, after string) string {
+	if p == nil {
+		return "<span></span>"
+	}
+	return `<a href="` + p.Name + `.html">` + before + html.EscapeString(p.Title) + after + "</a>"
+}
+
+func pageTitle(b *book, p *part) string {
+	if p.Title == b.Title {
+		return html.EscapeString(b.Title)
+	}
+	return html.EscapeString(p.Title + " - " + b.Title)
+}
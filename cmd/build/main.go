@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/go-mizu/go-fw/internal/gotool"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// step is one go command run against a module. A module stops at the first
+// step that fails.
+type step string
+
+const (
+	stepBuild step = "build"
+	stepVet   step = "vet"
+	stepTest  step = "test"
+
+	// stepDeps marks a step that failed because the go command could not
+	// fetch a dependency, which says more about the machine than the code.
+	stepDeps step = "deps"
+)
+
+// unfetched are the go command messages for a dependency it could not
+// download.
+var unfetched = []string{
+	"module lookup disabled",
+	"dial tcp",
+	"unrecognized import path",
+	"no such host",
+}
+
+// result is the outcome for one workspace module. Chapter and Framework are
+// empty for the root module.
+type result struct {
+	Dir         string       `json:"dir"`
+	Chapter     string       `json:"chapter,omitempty"`
+	Framework   string       `json:"framework,omitempty"`
+	OK          bool         `json:"ok"`
+	Failed      step         `json:"failed,omitempty"`
+	Diagnostics []diagnostic `json:"diagnostics,omitempty"`
+	Output      string       `json:"output,omitempty"` // failure output not in file:line form
+}
+
+type options struct {
+	chapter  string
+	fw       string
+	parallel int
+	vet      bool
+	test     bool
+	jsonOut  string
+	embed    string
+}
+
+// build compiles every module listed in go.work with a bounded pool of
+// workers and reports each failure against its chapter and framework.
+func main() {
+	var opts options
+	flag.StringVar(&opts.chapter, "chapter", "", "only build chapters whose directory starts with this prefix")
+	flag.StringVar(&opts.fw, "fw", "", "only build this framework directory ("+strings.Join(registry.Dirs(), ", ")+")")
+	flag.IntVar(&opts.parallel, "p", runtime.NumCPU(), "number of modules built concurrently")
+	flag.BoolVar(&opts.vet, "vet", false, "run go vet after a successful build")
+	flag.BoolVar(&opts.test, "test", false, "run go test after a successful build (and vet)")
+	flag.StringVar(&opts.jsonOut, "json", "", "also write the report as JSON to this file")
+	flag.StringVar(&opts.embed, "embed", "", "replace the status grid between the "+gridStart+" markers of this Markdown file")
+	flag.Parse()
+
+	dirs, err := gotool.WorkspaceModules()
+	if err != nil {
+		panic(err)
+	}
+
+	var jobs []result
+	for _, dir := range dirs {
+		r := classify(dir)
+		if opts.chapter != "" && !strings.HasPrefix(r.Chapter, opts.chapter) {
+			continue
+		}
+		if opts.fw != "" && r.Framework != opts.fw {
+			continue
+		}
+		jobs = append(jobs, r)
+	}
+
+	if len(jobs) == 0 {
+		fmt.Fprintln(os.Stderr, "no modules matched")
+		os.Exit(1)
+	}
+
+	results := runAll(jobs, opts)
+
+	if opts.jsonOut != "" {
+		if err := writeJSON(opts.jsonOut, results); err != nil {
+			panic(err)
+		}
+	}
+
+	if opts.embed != "" {
+		if err := embedGrid(opts.embed, results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	printReport(results)
+
+	for _, r := range results {
+		if !r.OK {
+			os.Exit(1)
+		}
+	}
+}
+
+// classify fills in the chapter and framework of an NN-*/<fw> module.
+func classify(dir string) result {
+	r := result{Dir: dir}
+
+	chapter, fw := filepath.Split(dir)
+	chapter = filepath.Clean(chapter)
+	if _, ok := registry.ByDir(fw); ok && filepath.Dir(chapter) == "." && chapter != "." {
+		r.Chapter = chapter
+		r.Framework = fw
+	}
+
+	return r
+}
+
+func runAll(jobs []result, opts options) []result {
+	results := make([]result, len(jobs))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, max(opts.parallel, 1))
+
+	for i, job := range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = buildModule(job, opts)
+			fmt.Fprintf(os.Stderr, "%-8s %s\n", results[i].cell(), job.Dir)
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
+
+func buildModule(r result, opts options) result {
+	steps := []step{stepBuild}
+	if opts.vet {
+		steps = append(steps, stepVet)
+	}
+	if opts.test {
+		steps = append(steps, stepTest)
+	}
+
+	for _, s := range steps {
+		// test-only examples (22-testing, 23-performance) have nothing to build
+		if s == stepBuild && r.Chapter != "" && !hasNonTestFiles(r.Dir) {
+			continue
+		}
+
+		out, err := run(r.Dir, s)
+		if err == nil {
+			continue
+		}
+
+		r.Failed = s
+		if fetchFailed(out) {
+			r.Failed = stepDeps
+		}
+		r.Diagnostics, r.Output = parseDiagnostics(r.Dir, out)
+		if len(r.Diagnostics) == 0 && r.Output == "" {
+			r.Output = err.Error()
+		}
+		return r
+	}
+
+	r.OK = true
+	return r
+}
+
+func run(dir string, s step) ([]byte, error) {
+	var args []string
+	switch s {
+	case stepBuild:
+		args = []string{"build", "-o", os.DevNull, "./..."}
+	case stepVet:
+		args = []string{"vet", "./..."}
+	case stepTest:
+		args = []string{"test", "./..."}
+	}
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	return cmd.CombinedOutput()
+}
+
+func fetchFailed(out []byte) bool {
+	for _, msg := range unfetched {
+		if bytes.Contains(out, []byte(msg)) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasNonTestFiles(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, f := range files {
+		if !strings.HasSuffix(f, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
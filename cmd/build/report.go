@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// Markers delimiting the generated grid in a Markdown file passed to -embed.
+const (
+	gridStart = "<!-- build-grid -->"
+	gridEnd   = "<!-- /build-grid -->"
+)
+
+// diagnostic is one file:line:col message from the go command, with File
+// relative to the repository root.
+type diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Col     int    `json:"col,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d diagnostic) String() string {
+	pos := d.File + ":" + strconv.Itoa(d.Line)
+	if d.Col > 0 {
+		pos += ":" + strconv.Itoa(d.Col)
+	}
+	return pos + ": " + d.Message
+}
+
+var diagRE = regexp.MustCompile(`^(\S+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseDiagnostics splits go build/vet/test output into positioned
+// diagnostics and the remaining lines worth showing.
+func parseDiagnostics(dir string, out []byte) ([]diagnostic, string) {
+	var (
+		diags []diagnostic
+		rest  []string
+	)
+
+	for _, line := range strings.Split(string(out), "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "",
+			strings.HasPrefix(trimmed, "go: downloading "),
+			strings.HasPrefix(trimmed, "# "),
+			strings.HasPrefix(trimmed, "ok "),
+			strings.HasPrefix(trimmed, "?  "):
+			continue
+		}
+
+		if m := diagRE.FindStringSubmatch(trimmed); m != nil {
+			d := diagnostic{
+				File:    filepath.Join(dir, m[1]),
+				Message: m[4],
+			}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Col, _ = strconv.Atoi(m[3])
+			diags = append(diags, d)
+			continue
+		}
+
+		// compiler notes such as "have (...) want (...)" continue the
+		// previous diagnostic on an indented line
+		if line != trimmed && len(diags) > 0 && !strings.HasPrefix(trimmed, "---") {
+			diags[len(diags)-1].Message += "\n" + trimmed
+			continue
+		}
+
+		rest = append(rest, trimmed)
+	}
+
+	return diags, strings.Join(rest, "\n")
+}
+
+func (r result) cell() string {
+	if r.OK {
+		return "ok"
+	}
+	return strings.ToUpper(string(r.Failed))
+}
+
+func writeJSON(path string, results []result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// grid renders the chapter × framework status table.
+func grid(results []result) string {
+	var (
+		sb       strings.Builder
+		chapters []string
+		cells    = map[string]map[string]string{}
+	)
+
+	for _, r := range results {
+		if r.Chapter == "" {
+			continue
+		}
+		if cells[r.Chapter] == nil {
+			cells[r.Chapter] = map[string]string{}
+			chapters = append(chapters, r.Chapter)
+		}
+		cells[r.Chapter][r.Framework] = r.cell()
+	}
+
+	sb.WriteString("| Chapter |")
+	for _, fw := range registry.Frameworks {
+		fmt.Fprintf(&sb, " %s |", fw.Title)
+	}
+	sb.WriteString("\n|---|")
+	for range registry.Frameworks {
+		sb.WriteString("---|")
+	}
+	sb.WriteString("\n")
+
+	for _, chapter := range chapters {
+		fmt.Fprintf(&sb, "| %s |", chapter)
+		for _, fw := range registry.Frameworks {
+			cell, ok := cells[chapter][fw.Dir]
+			if !ok {
+				cell = "-"
+			}
+			fmt.Fprintf(&sb, " %s |", cell)
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+func printReport(results []result) {
+	fmt.Print(grid(results))
+
+	var failed []result
+	for _, r := range results {
+		if !r.OK {
+			failed = append(failed, r)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d of %d modules ok\n", len(results)-len(failed), len(results))
+
+	if len(failed) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("failures:")
+	for _, r := range failed {
+		fmt.Printf("- %s (%s)\n", r.Dir, r.Failed)
+		for _, d := range r.Diagnostics {
+			fmt.Printf("    %s\n", strings.ReplaceAll(d.String(), "\n", "\n        "))
+		}
+		if r.Output != "" {
+			fmt.Printf("    %s\n", strings.ReplaceAll(r.Output, "\n", "\n    "))
+		}
+	}
+}
+
+// embedGrid replaces the text between gridStart and gridEnd in path with the
+// current grid, so a README can carry an up to date status table. It refuses
+// when a module could not fetch its dependencies, as the grid would then
+// describe this machine rather than the code.
+func embedGrid(path string, results []result) error {
+	var unfetched []string
+	for _, r := range results {
+		if r.Failed == stepDeps {
+			unfetched = append(unfetched, r.Dir)
+		}
+	}
+	if len(unfetched) > 0 {
+		return fmt.Errorf("%s not updated, as these modules could not fetch their dependencies: %s; rerun where every module resolves",
+			path, strings.Join(unfetched, ", "))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	start := bytes.Index(data, []byte(gridStart))
+	end := bytes.Index(data, []byte(gridEnd))
+	if start < 0 || end < start {
+		return fmt.Errorf("%s: no %s ... %s section", path, gridStart, gridEnd)
+	}
+
+	var out bytes.Buffer
+	out.Write(data[:start+len(gridStart)])
+	out.WriteString("\n")
+	out.WriteString(grid(results))
+	out.Write(data[end:])
+
+	return os.WriteFile(path, out.Bytes(), 0644)
+}
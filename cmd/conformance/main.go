@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-mizu/go-fw/internal/conformance"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+type status int
+
+const (
+	statusPass status = iota
+	statusFail
+	statusBuild
+	statusStart
+	statusMissing
+)
+
+// result is the outcome of one chapter × framework cell.
+type result struct {
+	Chapter   string
+	Framework registry.Framework
+	Status    status
+	Passed    int
+	Total     int
+	Details   []string
+
+	spec *conformance.Spec // shared by the frameworks of the chapter
+}
+
+func (r result) cell() string {
+	switch r.Status {
+	case statusPass:
+		return fmt.Sprintf("pass %d/%d", r.Passed, r.Total)
+	case statusFail:
+		return fmt.Sprintf("FAIL %d/%d", r.Passed, r.Total)
+	case statusBuild:
+		return "BUILD"
+	case statusStart:
+		return "START"
+	default:
+		return "-"
+	}
+}
+
+type options struct {
+	chapter  string
+	fw       string
+	parallel int
+	startup  time.Duration
+	timeout  time.Duration
+	verbose  bool
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.chapter, "chapter", "", "only run chapters whose directory starts with this prefix")
+	flag.StringVar(&opts.fw, "fw", "", "only run this framework directory ("+strings.Join(registry.Dirs(), ", ")+")")
+	flag.IntVar(&opts.parallel, "p", 4, "number of examples built and run concurrently")
+	flag.DurationVar(&opts.startup, "startup", 10*time.Second, "how long to wait for an example to listen")
+	flag.DurationVar(&opts.timeout, "timeout", 5*time.Second, "per request timeout")
+	flag.BoolVar(&opts.verbose, "v", false, "print build output and every failed expectation")
+	flag.Parse()
+
+	specs, err := filepath.Glob("[0-9][0-9]-*/" + conformance.File)
+	if err != nil {
+		panic(err)
+	}
+	sort.Strings(specs)
+
+	tmp, err := os.MkdirTemp("", "conformance-")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	var (
+		chapters []string
+		jobs     []result
+	)
+
+	for _, path := range specs {
+		chapter := filepath.Dir(path)
+		if !strings.HasPrefix(chapter, opts.chapter) {
+			continue
+		}
+		chapters = append(chapters, chapter)
+
+		// a bad spec stops the run before anything is built
+		s, err := conformance.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		for _, fw := range registry.Frameworks {
+			if opts.fw != "" && fw.Dir != opts.fw {
+				continue
+			}
+			jobs = append(jobs, result{Chapter: chapter, Framework: fw, spec: s})
+		}
+	}
+
+	if len(jobs) == 0 {
+		fmt.Fprintln(os.Stderr, "no conformance specs matched")
+		os.Exit(1)
+	}
+
+	results := runAll(jobs, tmp, opts)
+
+	printMatrix(chapters, results)
+	printFailures(results, opts.verbose)
+
+	for _, r := range results {
+		if r.Status != statusPass && r.Status != statusMissing {
+			os.Exit(1)
+		}
+	}
+}
+
+func runAll(jobs []result, tmp string, opts options) []result {
+	results := make([]result, len(jobs))
+	sem := make(chan struct{}, max(opts.parallel, 1))
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dir := filepath.Join(tmp, fmt.Sprintf("%03d", i))
+			results[i] = run(job, dir, opts)
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+func run(res result, tmp string, opts options) result {
+	dir := filepath.Join(res.Chapter, res.Framework.Dir)
+	if _, err := os.Stat(dir); err != nil {
+		res.Status = statusMissing
+		return res
+	}
+
+	s := res.spec
+	res.Total = len(s.Requests)
+
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		panic(err)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		panic(err)
+	}
+
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		panic(err)
+	}
+	bin := filepath.Join(tmp, "server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	if err := build(ctx, abs, bin, port); err != nil {
+		res.Status = statusBuild
+		res.Details = []string{err.Error()}
+		return res
+	}
+
+	srv, err := start(bin, abs, port, opts.startup)
+	if err != nil {
+		res.Status = statusStart
+		res.Details = []string{err.Error()}
+		return res
+	}
+	defer srv.stop()
+
+	client := &http.Client{
+		Timeout: opts.timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	for _, r := range s.Requests {
+		errs := srv.do(client, r)
+		if len(errs) == 0 {
+			res.Passed++
+			continue
+		}
+		for _, e := range errs {
+			res.Details = append(res.Details, r.Name+": "+e)
+		}
+	}
+
+	res.Status = statusPass
+	if res.Passed != res.Total {
+		res.Status = statusFail
+	}
+
+	return res
+}
+
+func printMatrix(chapters []string, results []result) {
+	cells := map[string]map[string]string{}
+	for _, r := range results {
+		if cells[r.Chapter] == nil {
+			cells[r.Chapter] = map[string]string{}
+		}
+		cells[r.Chapter][r.Framework.Dir] = r.cell()
+	}
+
+	fmt.Print("| Chapter |")
+	for _, fw := range registry.Frameworks {
+		fmt.Printf(" %s |", fw.Title)
+	}
+	fmt.Println()
+
+	fmt.Print("|---|")
+	for range registry.Frameworks {
+		fmt.Print("---|")
+	}
+	fmt.Println()
+
+	for _, chapter := range chapters {
+		fmt.Printf("| %s |", chapter)
+		for _, fw := range registry.Frameworks {
+			cell, ok := cells[chapter][fw.Dir]
+			if !ok {
+				cell = "-"
+			}
+			fmt.Printf(" %s |", cell)
+		}
+		fmt.Println()
+	}
+}
+
+func printFailures(results []result, verbose bool) {
+	var failed []result
+	for _, r := range results {
+		if len(r.Details) > 0 {
+			failed = append(failed, r)
+		}
+	}
+
+	if len(failed) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("failures:")
+	for _, r := range failed {
+		fmt.Printf("- %s/%s\n", r.Chapter, r.Framework.Dir)
+
+		details := r.Details
+		if !verbose {
+			details = firstLines(details)
+		}
+		for _, d := range details {
+			fmt.Printf("    %s\n", strings.ReplaceAll(d, "\n", "\n    "))
+		}
+	}
+}
+
+// firstLines trims multi-line details (compiler output) to their first line.
+func firstLines(details []string) []string {
+	out := make([]string, len(details))
+	for i, d := range details {
+		first, _, _ := strings.Cut(d, "\n")
+		out[i] = first
+	}
+	return out
+}
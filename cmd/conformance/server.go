@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mizu/go-fw/internal/conformance"
+	"github.com/go-mizu/go-fw/internal/gotool"
+)
+
+// defaultAddr is the listen address hard-coded by every example.
+const defaultAddr = `":8080"`
+
+// server is a running example binary.
+type server struct {
+	cmd    *exec.Cmd
+	base   string
+	output *bytes.Buffer
+	exited chan error
+}
+
+// build compiles the example in dir into bin with every ":8080" literal
+// rewritten to the given port. The sources on disk are left untouched; the
+// rewritten files are passed to the compiler through -overlay.
+func build(ctx context.Context, dir, bin string, port int) error {
+	replace, err := portOverlay(dir, filepath.Dir(bin), port)
+	if err != nil {
+		return err
+	}
+
+	overlay := filepath.Join(filepath.Dir(bin), "overlay.json")
+	data, err := json.Marshal(map[string]any{"Replace": replace})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(overlay, data, 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, "go", "build", "-overlay", overlay, "-o", bin, ".")
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := gotool.CompilerOutput(out); msg != "" {
+			// Point diagnostics at the sources, not the rewritten copies.
+			var paths []string
+			for src, patched := range replace {
+				paths = append(paths, patched, filepath.Base(src))
+			}
+			return errors.New(strings.NewReplacer(paths...).Replace(msg))
+		}
+		return fmt.Errorf("go build: %w", err)
+	}
+
+	return nil
+}
+
+// portOverlay returns the overlay replacements for every file in dir that
+// mentions the default listen address.
+func portOverlay(dir, tmp string, port int) (map[string]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	replace := map[string]string{}
+	addr := strconv.Quote(":" + strconv.Itoa(port))
+
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		var offsets []int
+		ast.Inspect(f, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING && lit.Value == defaultAddr {
+				offsets = append(offsets, fset.Position(lit.Pos()).Offset)
+			}
+			return true
+		})
+
+		if len(offsets) == 0 {
+			continue
+		}
+
+		var out bytes.Buffer
+		last := 0
+		for _, off := range offsets {
+			out.Write(src[last:off])
+			out.WriteString(addr)
+			last = off + len(defaultAddr)
+		}
+		out.Write(src[last:])
+
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+
+		patched := filepath.Join(tmp, filepath.Base(file))
+		if err := os.WriteFile(patched, out.Bytes(), 0644); err != nil {
+			return nil, err
+		}
+		replace[abs] = patched
+	}
+
+	if len(replace) == 0 {
+		return nil, fmt.Errorf("no %s listen address found in %s", defaultAddr, dir)
+	}
+
+	return replace, nil
+}
+
+// start runs bin from dir and waits until it accepts connections on port.
+func start(bin, dir string, port int, timeout time.Duration) (*server, error) {
+	s := &server{
+		base:   "http://127.0.0.1:" + strconv.Itoa(port),
+		output: &bytes.Buffer{},
+		exited: make(chan error, 1),
+	}
+
+	s.cmd = exec.Command(bin)
+	s.cmd.Dir = dir
+	s.cmd.Stdout = s.output
+	s.cmd.Stderr = s.output
+
+	if err := s.cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		s.exited <- s.cmd.Wait()
+	}()
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	deadline := time.Now().Add(timeout)
+
+	for {
+		select {
+		case err := <-s.exited:
+			return nil, fmt.Errorf("exited before listening: %v\n%s", err, strings.TrimSpace(s.output.String()))
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", addr, 100*time.Millisecond)
+		if err == nil {
+			conn.Close()
+			return s, nil
+		}
+
+		if time.Now().After(deadline) {
+			s.stop()
+			return nil, fmt.Errorf("not listening on %s after %s", addr, timeout)
+		}
+
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (s *server) stop() {
+	_ = s.cmd.Process.Kill()
+	<-s.exited
+}
+
+// do sends r to the server and returns the mismatches against r.Expect.
+func (s *server) do(client *http.Client, r conformance.Request) []string {
+	req, err := r.NewRequest(s.base)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return r.Check(resp)
+}
+
+// freePort asks the kernel for an unused TCP port.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+type edit struct {
+	Op   byte // ' ', '-' or '+'
+	Line string
+}
+
+// unifiedDiff returns a unified diff turning a into b, or "" when they are
+// equal. Both inputs are compared line by line.
+func unifiedDiff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	edits := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	for _, h := range hunks(edits) {
+		sb.WriteString(h)
+	}
+
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// diffLines computes a minimal line edit script using the longest common
+// subsequence. Snippets are at most a few hundred lines, so the quadratic
+// table is fine.
+func diffLines(a, b []string) []edit {
+	n, m := len(a), len(b)
+
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		edits = append(edits, edit{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		edits = append(edits, edit{'+', b[j]})
+	}
+
+	return edits
+}
+
+// hunks groups an edit script into unified diff hunks.
+func hunks(edits []edit) []string {
+	var out []string
+
+	for start := 0; start < len(edits); {
+		// find the next change
+		for start < len(edits) && edits[start].Op == ' ' {
+			start++
+		}
+		if start == len(edits) {
+			break
+		}
+
+		// extend until diffContext*2 unchanged lines separate us from the next change
+		end := start
+		for end < len(edits) {
+			if edits[end].Op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == ' ' {
+				run++
+			}
+			if run == len(edits) || run-end > 2*diffContext {
+				break
+			}
+			end = run
+		}
+
+		from := max(start-diffContext, 0)
+		to := min(end+diffContext, len(edits))
+
+		// line numbers are 1-based positions in a and b
+		aLine, bLine := 1, 1
+		for _, e := range edits[:from] {
+			if e.Op != '+' {
+				aLine++
+			}
+			if e.Op != '-' {
+				bLine++
+			}
+		}
+
+		var aCount, bCount int
+		var body strings.Builder
+		for _, e := range edits[from:to] {
+			if e.Op != '+' {
+				aCount++
+			}
+			if e.Op != '-' {
+				bCount++
+			}
+			body.WriteByte(e.Op)
+			body.WriteString(e.Line)
+			body.WriteByte('\n')
+		}
+
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)+body.String())
+		start = to
+	}
+
+	return out
+}
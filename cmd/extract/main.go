@@ -1,32 +1,66 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/registry"
 )
 
-type framework struct {
-	Title string
-	Dir   string
-}
+type mode int
 
-var frameworks = []framework{
-	{"net/http", "nethttp"},
-	{"Chi", "chi"},
-	{"Gin", "gin"},
-	{"Echo", "echo"},
-	{"Fiber", "fiber"},
-	{"Mizu", "mizu"},
-}
+const (
+	modeWrite   mode = iota // README -> files
+	modeCheck               // report README/file drift
+	modeReverse             // files -> README
+)
 
 type result struct {
-	Written []string
-	Missing []string
+	Written  []string
+	Missing  []string
+	Drifted  []string
+	Modules  []string
+	Unpinned []string
 }
 
+// fence is the body of a ```go block: lines[Start:End] of a README. File is
+// the name it is extracted to inside the framework directory.
+type fence struct {
+	Start int
+	End   int
+	File  string
+}
+
+// mainFile is where the primary snippet of a section goes unless it is a test.
+const mainFile = "main.go"
+
 func main() {
+	var (
+		check   = flag.Bool("check", false, "report README and extracted file disagreements as unified diffs and exit non-zero")
+		reverse = flag.Bool("reverse", false, "write extracted file contents back into the README fences")
+		runTidy = flag.Bool("tidy", false, "run go mod tidy in every generated module to fill in go.sum; needs network access for modules missing from the module cache")
+	)
+	flag.Parse()
+
+	if *check && *reverse {
+		fmt.Fprintln(os.Stderr, "-check and -reverse are mutually exclusive")
+		os.Exit(2)
+	}
+
+	m := modeWrite
+	switch {
+	case *check:
+		m = modeCheck
+	case *reverse:
+		m = modeReverse
+	}
+
 	chapters, err := filepath.Glob("[0-9][0-9]-*/README.md")
 	if err != nil {
 		panic(err)
@@ -35,17 +69,23 @@ func main() {
 	var res result
 
 	for _, readme := range chapters {
-		processChapter(readme, &res)
+		processChapter(readme, m, &res)
+	}
+
+	if m == modeWrite {
+		if err := syncModules(&res, *runTidy); err != nil {
+			panic(err)
+		}
 	}
 
-	printSummary(res)
+	printSummary(m, res)
 
-	if len(res.Missing) > 0 {
+	if len(res.Missing) > 0 || len(res.Drifted) > 0 {
 		os.Exit(1)
 	}
 }
 
-func processChapter(readme string, res *result) {
+func processChapter(readme string, m mode, res *result) {
 	data, err := os.ReadFile(readme)
 	if err != nil {
 		panic(err)
@@ -53,39 +93,144 @@ func processChapter(readme string, res *result) {
 
 	lines := strings.Split(string(data), "\n")
 	chapterDir := filepath.Dir(readme)
+	changed := false
 
-	for _, fw := range frameworks {
-		code := extractCode(lines, fw.Title)
-		target := filepath.Join(chapterDir, fw.Dir, "main.go")
+	for _, fw := range registry.Frameworks {
+		dir := filepath.Join(chapterDir, fw.Dir)
 
-		if code == "" {
-			res.Missing = append(res.Missing, target)
+		fences := findFences(lines, fw.Title)
+		if len(fences) == 0 {
+			res.Missing = append(res.Missing, filepath.Join(dir, mainFile))
 			continue
 		}
 
-		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-			panic(err)
+		// Replace from the bottom up so earlier fence offsets stay valid.
+		for i := len(fences) - 1; i >= 0; i-- {
+			f := fences[i]
+			target := filepath.Join(dir, f.File)
+			code := f.code(lines)
+
+			switch m {
+			case modeWrite:
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					panic(err)
+				}
+
+				if err := os.WriteFile(target, []byte(code), 0644); err != nil {
+					panic(err)
+				}
+
+				res.Written = append(res.Written, target)
+
+				if i == 0 {
+					res.Modules = append(res.Modules, dir)
+				}
+
+			case modeCheck:
+				current, err := os.ReadFile(target)
+				if err != nil && !os.IsNotExist(err) {
+					panic(err)
+				}
+
+				from := fmt.Sprintf("%s (## %s)", readme, fw.Title)
+				if d := unifiedDiff(from, target, code, string(current)); d != "" {
+					fmt.Print(d)
+					res.Drifted = append(res.Drifted, target)
+				}
+
+			case modeReverse:
+				current, err := os.ReadFile(target)
+				if os.IsNotExist(err) {
+					res.Missing = append(res.Missing, target)
+					continue
+				}
+				if err != nil {
+					panic(err)
+				}
+
+				if string(current) == code {
+					continue
+				}
+
+				lines = f.replace(lines, string(current))
+				changed = true
+				res.Written = append(res.Written, readme+" (## "+fw.Title+") -> "+f.File)
+			}
 		}
 
-		if err := os.WriteFile(target, []byte(code), 0644); err != nil {
+		staleMain(dir, fences, m, res)
+	}
+
+	if changed {
+		if err := os.WriteFile(readme, []byte(strings.Join(lines, "\n")), 0644); err != nil {
 			panic(err)
 		}
+	}
+}
 
-		res.Written = append(res.Written, target)
+// syncModules gives every written framework directory a go.mod pinned from
+// versions.mod and registers it in go.work.
+func syncModules(res *result, runTidy bool) error {
+	pins, err := loadManifest()
+	if err != nil {
+		return err
+	}
+
+	goVersion, err := rootGoVersion()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range res.Modules {
+		unpinned, err := syncModule(dir, pins, goVersion)
+		if err != nil {
+			return err
+		}
+		for _, imp := range unpinned {
+			res.Unpinned = append(res.Unpinned, dir+": "+imp)
+		}
 	}
+
+	if err := registerWork(res.Modules); err != nil {
+		return err
+	}
+
+	if !runTidy {
+		return nil
+	}
+
+	for _, dir := range res.Modules {
+		if err := tidy(dir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	return nil
 }
 
-func extractCode(lines []string, title string) string {
-	inSection := false
-	inCode := false
-	var out []string
+// findFences returns the ```go blocks extracted from the "## <title>"
+// section. The first unannotated block is the primary snippet and goes to
+// main.go, or to main_test.go when it only holds tests. Any further block is
+// extracted only if it names its target, as in ```go file=handler_test.go.
+func findFences(lines []string, title string) []fence {
+	var (
+		out       []fence
+		inSection bool
+		primary   bool
+		open      = -1
+		file      string
+	)
 
 	sectionHeader := "## " + title
 
-	for _, line := range lines {
+	for i, line := range lines {
 		if strings.HasPrefix(line, "## ") {
+			if inSection && open >= 0 {
+				// unterminated fence: the block runs to the end of the section
+				out = appendFence(out, lines, fence{open, i, file}, &primary)
+				open = -1
+			}
 			inSection = line == sectionHeader
-			inCode = false
 			continue
 		}
 
@@ -93,41 +238,167 @@ func extractCode(lines []string, title string) string {
 			continue
 		}
 
-		if strings.HasPrefix(line, "```go") {
-			inCode = true
+		if open < 0 && strings.HasPrefix(line, "```go") {
+			open = i + 1
+			file = fenceAttrs(line)["file"]
 			continue
 		}
 
-		if strings.HasPrefix(line, "```") && inCode {
-			break
+		if open >= 0 && strings.HasPrefix(line, "```") {
+			out = appendFence(out, lines, fence{open, i, file}, &primary)
+			open = -1
 		}
+	}
 
-		if inCode {
-			out = append(out, line)
+	if inSection && open >= 0 {
+		out = appendFence(out, lines, fence{open, len(lines), file}, &primary)
+	}
+
+	return out
+}
+
+func appendFence(out []fence, lines []string, f fence, primary *bool) []fence {
+	if f.Start >= f.End {
+		return out
+	}
+
+	if f.File == "" {
+		if *primary {
+			return out
+		}
+		*primary = true
+
+		f.File = mainFile
+		if isTestOnly(f.code(lines)) {
+			f.File = "main_test.go"
+		}
+	}
+
+	return append(out, f)
+}
+
+// fenceAttrs parses the key=value pairs after the language of a fence info
+// string, e.g. ```go file=handler_test.go.
+func fenceAttrs(line string) map[string]string {
+	attrs := map[string]string{}
+
+	fields := strings.Fields(strings.TrimPrefix(line, "```"))
+	for _, field := range fields[min(1, len(fields)):] {
+		if k, v, ok := strings.Cut(field, "="); ok {
+			attrs[k] = v
+		}
+	}
+
+	return attrs
+}
+
+// isTestOnly reports whether code imports "testing" and has no func main,
+// meaning it only compiles as a _test.go file.
+func isTestOnly(code string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", code, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+
+	testing := false
+	for _, imp := range f.Imports {
+		if imp.Path.Value == `"testing"` {
+			testing = true
+		}
+	}
+
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return false
+		}
+	}
+
+	return testing
+}
+
+// staleMain handles a main.go left behind by an earlier extraction when the
+// section no longer produces one, such as a chapter that moved to _test.go.
+func staleMain(dir string, fences []fence, m mode, res *result) {
+	for _, f := range fences {
+		if f.File == mainFile {
+			return
 		}
 	}
 
-	if len(out) == 0 {
-		return ""
+	target := filepath.Join(dir, mainFile)
+	current, err := os.ReadFile(target)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		panic(err)
 	}
 
-	return strings.Join(out, "\n") + "\n"
+	switch m {
+	case modeWrite:
+		if err := os.Remove(target); err != nil {
+			panic(err)
+		}
+	case modeCheck:
+		fmt.Print(unifiedDiff("/dev/null", target, "", string(current)))
+		res.Drifted = append(res.Drifted, target)
+	}
+}
+
+func (f fence) code(lines []string) string {
+	return strings.Join(lines[f.Start:f.End], "\n") + "\n"
 }
 
-func printSummary(res result) {
+// replace swaps the fence body for code, leaving the surrounding prose and
+// the fence markers untouched.
+func (f fence) replace(lines []string, code string) []string {
+	body := strings.Split(strings.TrimRight(code, "\n"), "\n")
+
+	out := make([]string, 0, len(lines)-(f.End-f.Start)+len(body))
+	out = append(out, lines[:f.Start]...)
+	out = append(out, body...)
+	out = append(out, lines[f.End:]...)
+
+	return out
+}
+
+func printSummary(m mode, res result) {
 	fmt.Println("summary")
 	fmt.Println("-------")
 
-	fmt.Printf("written: %d\n", len(res.Written))
+	switch m {
+	case modeCheck:
+		fmt.Printf("drifted: %d\n", len(res.Drifted))
+	case modeReverse:
+		fmt.Printf("updated: %d\n", len(res.Written))
+	default:
+		fmt.Printf("written: %d\n", len(res.Written))
+		fmt.Printf("modules: %d\n", len(res.Modules))
+	}
 	fmt.Printf("missing: %d\n", len(res.Missing))
 
-	if len(res.Missing) == 0 {
-		fmt.Println("\nall main.go files generated successfully")
-		return
+	if len(res.Drifted) > 0 {
+		fmt.Println("\nREADME and extracted files disagree:")
+		for _, d := range res.Drifted {
+			fmt.Println("-", d)
+		}
+	}
+
+	if len(res.Unpinned) > 0 {
+		fmt.Printf("\nimports without a pin in %s:\n", manifestFile)
+		for _, u := range res.Unpinned {
+			fmt.Println("-", u)
+		}
+	}
+
+	if len(res.Missing) > 0 {
+		fmt.Println("\nmissing files:")
+		for _, m := range res.Missing {
+			fmt.Println("-", m)
+		}
 	}
 
-	fmt.Println("\nmissing main.go files:")
-	for _, m := range res.Missing {
-		fmt.Println("-", m)
+	if len(res.Missing) == 0 && len(res.Drifted) == 0 && m == modeWrite {
+		fmt.Println("\nall files generated successfully")
 	}
 }
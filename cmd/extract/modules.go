@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+const (
+	// rootModule is the repository module that holds pkg/models.
+	rootModule = "github.com/go-mizu/go-fw"
+
+	// manifestFile lists the minimum version of every third-party module
+	// the examples may import.
+	manifestFile = "versions.mod"
+
+	// localVersion is the placeholder version go mod tidy uses for a
+	// requirement satisfied by a directory replacement.
+	localVersion = "v0.0.0-00010101000000-000000000000"
+)
+
+// loadManifest returns the pinned version for each module in versions.mod.
+func loadManifest() (map[string]string, error) {
+	data, err := os.ReadFile(manifestFile)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.ParseLax(manifestFile, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pins := map[string]string{}
+	for _, r := range f.Require {
+		pins[r.Mod.Path] = r.Mod.Version
+	}
+
+	return pins, nil
+}
+
+// pinFor returns the manifest module that provides the import path.
+func pinFor(imp string, pins map[string]string) string {
+	best := ""
+	for mod := range pins {
+		if (imp == mod || strings.HasPrefix(imp, mod+"/")) && len(mod) > len(best) {
+			best = mod
+		}
+	}
+	return best
+}
+
+// snippetImports returns the non-standard-library imports of every .go file
+// in dir.
+func snippetImports(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	fset := token.NewFileSet()
+
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+
+			// standard library paths have no dot in the first element
+			first, _, _ := strings.Cut(path, "/")
+			if strings.Contains(first, ".") {
+				seen[path] = true
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(seen))
+	for imp := range seen {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+
+	return imports, nil
+}
+
+// syncModule creates or updates dir/go.mod so it requires every module the
+// snippets import, at least at the manifest version. Imports of the
+// repository itself (pkg/models, or an adapter module below it) are
+// satisfied by replaces to the local directories. It returns the imports
+// the manifest does not cover.
+func syncModule(dir string, pins map[string]string, goVersion string) ([]string, error) {
+	path := filepath.Join(dir, "go.mod")
+
+	var f *modfile.File
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		f = new(modfile.File)
+		if err := f.AddModuleStmt(rootModule + "/" + filepath.ToSlash(dir)); err != nil {
+			return nil, err
+		}
+		if err := f.AddGoStmt(goVersion); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		f, err = modfile.Parse(path, data, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	imports, err := snippetImports(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var unpinned []string
+
+	for _, imp := range imports {
+		if mod, local, ok := localModule(imp); ok {
+			if err := f.AddRequire(mod, localVersion); err != nil {
+				return nil, err
+			}
+			// a nested module requires the root module in turn, and only
+			// the main module's replacements apply
+			for _, r := range [][2]string{{mod, local}, {rootModule, "."}} {
+				rel, err := filepath.Rel(dir, r[1])
+				if err != nil {
+					return nil, err
+				}
+				if err := f.AddReplace(r[0], "", filepath.ToSlash(rel), ""); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		mod := pinFor(imp, pins)
+		if mod == "" {
+			unpinned = append(unpinned, imp)
+			continue
+		}
+
+		if current := requiredVersion(f, mod); current != "" && semver.Compare(current, pins[mod]) >= 0 {
+			continue
+		}
+		if err := f.AddRequire(mod, pins[mod]); err != nil {
+			return nil, err
+		}
+	}
+
+	f.Cleanup()
+
+	out, err := f.Format()
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(out, data) {
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	return unpinned, nil
+}
+
+// localModule returns the repository module that provides imp, and its
+// directory: a nested module such as pkg/models/ginmodels, or the root.
+func localModule(imp string) (mod, dir string, ok bool) {
+	if imp != rootModule && !strings.HasPrefix(imp, rootModule+"/") {
+		return "", "", false
+	}
+
+	rel := strings.TrimPrefix(strings.TrimPrefix(imp, rootModule), "/")
+	for d := rel; d != "" && d != "."; d = path.Dir(d) {
+		if _, err := os.Stat(filepath.Join(filepath.FromSlash(d), "go.mod")); err == nil {
+			return rootModule + "/" + d, filepath.FromSlash(d), true
+		}
+	}
+
+	return rootModule, ".", true
+}
+
+func requiredVersion(f *modfile.File, mod string) string {
+	for _, r := range f.Require {
+		if r.Mod.Path == mod {
+			return r.Mod.Version
+		}
+	}
+	return ""
+}
+
+// rootGoVersion returns the go directive of the repository go.mod, used for
+// newly created example modules.
+func rootGoVersion() (string, error) {
+	data, err := os.ReadFile("go.mod")
+	if err != nil {
+		return "", err
+	}
+
+	f, err := modfile.ParseLax("go.mod", data, nil)
+	if err != nil {
+		return "", err
+	}
+	if f.Go == nil {
+		return "", fmt.Errorf("go.mod: missing go directive")
+	}
+
+	return f.Go.Version, nil
+}
+
+// registerWork adds every dir missing from go.work as a use directive.
+func registerWork(dirs []string) error {
+	data, err := os.ReadFile("go.work")
+	if err != nil {
+		return err
+	}
+
+	wf, err := modfile.ParseWork("go.work", data, nil)
+	if err != nil {
+		return err
+	}
+
+	have := map[string]bool{}
+	for _, u := range wf.Use {
+		have[u.Path] = true
+	}
+
+	changed := false
+	for _, dir := range dirs {
+		use := "./" + filepath.ToSlash(dir)
+		if have[use] {
+			continue
+		}
+		if err := wf.AddUse(use, ""); err != nil {
+			return err
+		}
+		have[use] = true
+		changed = true
+	}
+
+	if !changed {
+		return nil
+	}
+
+	wf.SortBlocks()
+	wf.Cleanup()
+
+	return os.WriteFile("go.work", modfile.Format(wf.Syntax), 0644)
+}
+
+// tidy fills in go.sum and indirect requirements for the module in dir.
+func tidy(dir string) error {
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: go mod tidy: %v\n%s", dir, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// fragmentPackage names the synthetic package of a fragment whose section
+// has no whole-file fence to join.
+const fragmentPackage = "fence"
+
+// unit is one go/types run: the files of a package and the fences whose
+// errors it reports. A fragment is checked as an extra file of its
+// section's package, so it can use the helpers the full program declares.
+type unit struct {
+	Dir     string // module directory imports resolve from
+	Package string
+	Fences  []*fence // fences that make up the package
+	Report  []*fence // fences whose errors this unit reports
+	Wrapped *fence   // fragment checked through a synthetic file, if any
+	Imports []importSpec
+	Handler *registry.Framework
+}
+
+type problem struct {
+	File string
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (p problem) String() string {
+	if p.Col > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", p.File, p.Line, p.Col, p.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", p.File, p.Line, p.Msg)
+}
+
+// plan splits a section into units. The first whole-file fence is the
+// section's package, joined by later ones that name their file as
+// cmd/extract does; other whole-file fences stand alone. fallback lists
+// the imports fragments get when the section has no package.
+func plan(sec *section, fallback []importSpec) (units []*unit, skipped int) {
+	var (
+		pkg     []*fence
+		pkgName string
+		rest    []*fence
+	)
+
+	for _, f := range sec.Fences {
+		if f.skipped() {
+			skipped++
+			continue
+		}
+		if f.Kind == fileFence {
+			name := packageName(f.Code)
+			if pkg == nil || (f.Attrs["file"] != "" && name == pkgName) {
+				pkg = append(pkg, f)
+				pkgName = name
+				continue
+			}
+		}
+		rest = append(rest, f)
+	}
+
+	var imports []importSpec
+	for _, f := range pkg {
+		imports = mergeImports(imports, fileImports(f)...)
+	}
+	if pkg == nil {
+		imports = fallback
+		pkgName = fragmentPackage
+	}
+	if fw := sec.Framework; fw != nil {
+		imports = mergeImports(imports, importSpec{Path: fw.ImportPath()})
+		if strings.Contains(fw.Handler, "http.") {
+			imports = mergeImports(imports, importSpec{Path: "net/http"})
+		}
+	}
+
+	if pkg != nil {
+		units = append(units, &unit{
+			Dir:     moduleDir(sec, imports),
+			Package: pkgName,
+			Fences:  pkg,
+			Report:  pkg,
+		})
+	}
+
+	for _, f := range rest {
+		u := &unit{Dir: moduleDir(sec, imports), Report: []*fence{f}}
+		if f.Kind == fileFence {
+			u.Package = packageName(f.Code)
+			u.Fences = []*fence{f}
+		} else {
+			u.Package = pkgName
+			u.Fences = pkg
+			u.Wrapped = f
+			u.Imports = imports
+			u.Handler = sec.Framework
+		}
+		units = append(units, u)
+	}
+
+	return units, skipped
+}
+
+func packageName(code string) string {
+	f, err := parser.ParseFile(token.NewFileSet(), "", code, parser.PackageClauseOnly)
+	if err != nil {
+		return ""
+	}
+	return f.Name.Name
+}
+
+// moduleDir picks the framework directory next to the README whose go.mod
+// resolves the imports: the section's own framework first, then any
+// framework imported, then net/http, and the repository root last.
+func moduleDir(sec *section, imports []importSpec) string {
+	chapter := filepath.Dir(sec.Readme)
+
+	var dirs []string
+	if sec.Framework != nil {
+		dirs = append(dirs, sec.Framework.Dir)
+	}
+	for _, fw := range registry.Frameworks {
+		for _, imp := range imports {
+			if !fw.Stdlib() && (imp.Path == fw.Module || strings.HasPrefix(imp.Path, fw.Module+"/")) {
+				dirs = append(dirs, fw.Dir)
+			}
+		}
+	}
+	dirs = append(dirs, "nethttp")
+
+	for _, d := range dirs {
+		dir := filepath.Join(chapter, d)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+	}
+	return "."
+}
+
+// checker type-checks units against the export data of their imports, as
+// built by the go command in the unit's module directory.
+type checker struct {
+	fset *token.FileSet
+}
+
+func newChecker() *checker {
+	return &checker{fset: token.NewFileSet()}
+}
+
+// importer lists paths and their dependencies in dir with go list -export
+// and returns an importer reading the compiled export data. Packages the
+// module does not provide are left out and fail to import.
+func (c *checker) importer(dir string, paths []string) (types.Importer, error) {
+	exports := map[string]string{}
+	reasons := map[string]string{}
+
+	if len(paths) > 0 {
+		args := []string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}\t{{with .Error}}{{.Err}}{{end}}", "--"}
+		cmd := exec.Command("go", append(args, paths...)...)
+		cmd.Dir = dir
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("go list in %s: %v\n%s", dir, err, stderr.String())
+		}
+
+		for _, line := range strings.Split(string(out), "\n") {
+			path, rest, ok := strings.Cut(line, "\t")
+			if !ok {
+				continue
+			}
+			export, reason, _ := strings.Cut(rest, "\t")
+			if export != "" {
+				exports[path] = export
+			} else {
+				reasons[path] = reason
+			}
+		}
+	}
+
+	return importer.ForCompiler(c.fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			if reason := reasons[path]; reason != "" {
+				return nil, errors.New(firstLine(reason))
+			}
+			return nil, fmt.Errorf("not provided by %s", dir)
+		}
+		return os.Open(export)
+	}), nil
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// importPaths returns every path the files of u import.
+func importPaths(u *unit) []string {
+	seen := map[string]bool{}
+	for _, f := range u.Fences {
+		// blank imports too, which fileImports leaves out
+		file, err := parser.ParseFile(token.NewFileSet(), "", f.Code, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range file.Imports {
+			if p, err := strconv.Unquote(imp.Path.Value); err == nil {
+				seen[p] = true
+			}
+		}
+	}
+	if u.Wrapped != nil {
+		for _, imp := range u.Imports {
+			seen[imp.Path] = true
+		}
+		code := u.Wrapped.Code
+		if u.Wrapped.Kind != fileFence {
+			code = "package p\n" + code
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), "", code, parser.ImportsOnly); err == nil {
+			for _, imp := range specs(f) {
+				seen[imp.Path] = true
+			}
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// check type-checks u. Imports that cannot be resolved are returned on
+// their own: every other error of the unit would follow from them.
+func (c *checker) check(u *unit, imp types.Importer) (problems []problem, unresolved []string) {
+	dir, err := filepath.Abs(u.Dir)
+	if err != nil {
+		return []problem{c.charge(u, err.Error())}, nil
+	}
+
+	readmes := map[string]string{} // absolute path -> path as reported
+	var files []*ast.File
+
+	for _, f := range u.Fences {
+		abs, _ := filepath.Abs(f.Readme)
+		readmes[abs] = f.Readme
+
+		src := lineDirective(abs, f.Line) + f.Code
+		file, err := parser.ParseFile(c.fset, filepath.Join(dir, "fence.go"), src, parser.SkipObjectResolution)
+		if err != nil {
+			return c.parseProblems(u, readmes, err), nil
+		}
+		files = append(files, file)
+	}
+
+	synthetic := filepath.Join(dir, "fragment.go")
+	if f := u.Wrapped; f != nil {
+		abs, _ := filepath.Abs(f.Readme)
+		readmes[abs] = f.Readme
+
+		file, err := c.wrap(u, abs, synthetic)
+		if err != nil {
+			return c.parseProblems(u, readmes, err), nil
+		}
+		files = append(files, file)
+	}
+
+	var errs []types.Error
+	conf := types.Config{
+		Importer: imp,
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok {
+				errs = append(errs, e)
+			}
+		},
+	}
+	conf.Check(u.Package, c.fset, files, nil)
+
+	for _, e := range errs {
+		if path, ok := strings.CutPrefix(e.Msg, "could not import "); ok {
+			path, _, _ = strings.Cut(path, " ")
+			unresolved = append(unresolved, path)
+		}
+	}
+	if len(unresolved) > 0 {
+		return nil, unresolved
+	}
+
+	for _, e := range errs {
+		pos := c.fset.Position(e.Pos)
+		name, inReadme := readmes[pos.Filename]
+
+		switch {
+		case !inReadme:
+			// unused synthetic imports are expected; anything else in the
+			// wrapper comes from the fragment
+			if e.Soft || u.Wrapped == nil {
+				continue
+			}
+			problems = append(problems, c.charge(u, e.Msg))
+		case u.Wrapped != nil && u.Wrapped.Kind == stmtFence && e.Soft:
+			// a fragment may declare a variable only to show the call
+		case reported(u, name, pos.Line):
+			problems = append(problems, problem{File: name, Line: pos.Line, Col: pos.Column, Msg: e.Msg})
+		}
+	}
+
+	return problems, nil
+}
+
+// reported reports whether line of readme lies in a fence u reports on.
+func reported(u *unit, readme string, line int) bool {
+	for _, f := range u.Report {
+		if f.Readme == readme && f.contains(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// charge reports msg at the first line of the unit's fence, for errors
+// without a position inside it.
+func (c *checker) charge(u *unit, msg string) problem {
+	f := u.Report[0]
+	return problem{File: f.Readme, Line: f.Line, Msg: msg}
+}
+
+func (c *checker) parseProblems(u *unit, readmes map[string]string, err error) []problem {
+	list, ok := err.(scanner.ErrorList)
+	if !ok {
+		return []problem{c.charge(u, err.Error())}
+	}
+
+	var out []problem
+	for _, e := range list {
+		name, ok := readmes[e.Pos.Filename]
+		if !ok {
+			out = append(out, c.charge(u, e.Msg))
+			continue
+		}
+		if reported(u, name, e.Pos.Line) {
+			out = append(out, problem{File: name, Line: e.Pos.Line, Col: e.Pos.Column, Msg: e.Msg})
+		}
+	}
+	return out
+}
+
+// wrap builds the synthetic file around a fragment: the package clause and
+// the section's imports, then the declarations as they are, or the
+// statements as the body of a handler with the section framework's
+// parameters. The handler returns error when the fragment returns a value.
+func (c *checker) wrap(u *unit, readme, synthetic string) (*ast.File, error) {
+	f := u.Wrapped
+
+	var head strings.Builder
+	fmt.Fprintf(&head, "package %s\n\n", u.Package)
+	if f.Kind == stmtFence || !hasImports(f.Code) {
+		head.WriteString("import (\n")
+		for _, imp := range u.Imports {
+			head.WriteString("\t" + imp.String() + "\n")
+		}
+		head.WriteString(")\n\n")
+	}
+
+	if f.Kind == declFence {
+		return parser.ParseFile(c.fset, synthetic, head.String()+lineDirective(readme, f.Line)+f.Code, parser.SkipObjectResolution)
+	}
+
+	params := ""
+	if u.Handler != nil {
+		params = u.Handler.Handler
+	}
+
+	body := func(results string) string {
+		return head.String() +
+			"func _(" + params + ")" + results + " {\n" +
+			lineDirective(readme, f.Line) + f.Code +
+			lineDirective(synthetic, 1) + "}\n"
+	}
+
+	file, err := parser.ParseFile(c.fset, synthetic, body(""), parser.SkipObjectResolution)
+	if err != nil || !returnsValue(file) {
+		return file, err
+	}
+	return parser.ParseFile(c.fset, synthetic, body(" error"), parser.SkipObjectResolution)
+}
+
+// lineDirective makes the next line report as line of file.
+func lineDirective(file string, line int) string {
+	return fmt.Sprintf("//line %s:%d:1\n", file, line)
+}
+
+func hasImports(code string) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p\n"+code, parser.ImportsOnly)
+	return err == nil && len(f.Imports) > 0
+}
+
+// returnsValue reports whether the wrapper function returns a value
+// outside any function literal.
+func returnsValue(file *ast.File) bool {
+	found := false
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.ReturnStmt:
+				if len(n.Results) > 0 {
+					found = true
+				}
+			}
+			return !found
+		})
+	}
+	return found
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/module"
+
+	"github.com/go-mizu/go-fw/pkg/markdown"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+type fenceKind int
+
+const (
+	fileFence fenceKind = iota // a whole file, starting with a package clause
+	declFence                  // top-level declarations without a package clause
+	stmtFence                  // statements, checked as a function body
+)
+
+// fence is one ```go block of a README.
+type fence struct {
+	Readme string // path relative to the repository root
+	Line   int    // README line of the first body line
+	Lines  int
+	Code   string
+	Attrs  map[string]string // key=value pairs of the info string
+	Kind   fenceKind
+}
+
+// skipped reports whether the fence opts out with ```go check=off.
+func (f *fence) skipped() bool {
+	return f.Attrs["check"] == "off"
+}
+
+// contains reports whether README line lies inside the fence body.
+func (f *fence) contains(line int) bool {
+	return line >= f.Line && line < f.Line+max(f.Lines, 1)
+}
+
+// section is the run of fences under one "## " heading. Framework is nil
+// outside the framework sections.
+type section struct {
+	Readme    string
+	Title     string
+	Framework *registry.Framework
+	Fences    []*fence
+}
+
+// loadSections returns the sections of readme that hold ```go fences.
+func loadSections(readme string) ([]*section, error) {
+	src, err := os.ReadFile(readme)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		out []*section
+		cur = &section{Readme: readme}
+	)
+
+	for _, blk := range markdown.Parse(src).Blocks {
+		switch {
+		case blk.Kind == markdown.Heading && blk.Level == 2:
+			if len(cur.Fences) > 0 {
+				out = append(out, cur)
+			}
+			cur = &section{Readme: readme, Title: blk.Text}
+			for i, fw := range registry.Frameworks {
+				if fw.Title == blk.Text {
+					cur.Framework = &registry.Frameworks[i]
+				}
+			}
+		case blk.Kind == markdown.Fence && blk.Lang() == "go":
+			code := strings.Join(blk.Lines, "\n") + "\n"
+			cur.Fences = append(cur.Fences, &fence{
+				Readme: readme,
+				Line:   blk.Line + 1,
+				Lines:  len(blk.Lines),
+				Code:   code,
+				Attrs:  fenceAttrs(blk.Info),
+				Kind:   classify(code),
+			})
+		}
+	}
+	if len(cur.Fences) > 0 {
+		out = append(out, cur)
+	}
+
+	return out, nil
+}
+
+// fenceAttrs parses the key=value pairs after the language of a fence info
+// string, e.g. ```go file=handler_test.go.
+func fenceAttrs(info string) map[string]string {
+	attrs := map[string]string{}
+
+	fields := strings.Fields(info)
+	for _, field := range fields[min(1, len(fields)):] {
+		if k, v, ok := strings.Cut(field, "="); ok {
+			attrs[k] = v
+		}
+	}
+
+	return attrs
+}
+
+// classify tells files, declarations and statements apart by the first
+// token of the code.
+func classify(code string) fenceKind {
+	var sc scanner.Scanner
+	fset := token.NewFileSet()
+	sc.Init(fset.AddFile("", fset.Base(), len(code)), []byte(code), nil, 0)
+
+	_, tok, _ := sc.Scan()
+	switch tok {
+	case token.PACKAGE:
+		return fileFence
+	case token.IMPORT, token.FUNC, token.TYPE, token.VAR, token.CONST:
+		return declFence
+	}
+	return stmtFence
+}
+
+// importSpec is an import of a fence file, with its explicit name if any.
+type importSpec struct {
+	Name string
+	Path string
+}
+
+// ref is how code refers to the package.
+func (s importSpec) ref() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	for _, fw := range registry.Frameworks {
+		if fw.ImportPath() == s.Path {
+			return fw.PackageName()
+		}
+	}
+	// the last element of the path, leaving out a major version suffix as
+	// in github.com/jackc/pgx/v5 or gopkg.in/yaml.v3
+	prefix, _, ok := module.SplitPathVersion(s.Path)
+	if !ok {
+		prefix = s.Path
+	}
+	return path.Base(prefix)
+}
+
+func (s importSpec) String() string {
+	if s.Name != "" {
+		return s.Name + " " + strconv.Quote(s.Path)
+	}
+	return strconv.Quote(s.Path)
+}
+
+// fileImports returns the imports of a whole-file fence. Blank and dot
+// imports are left out: a fragment never relies on them.
+func fileImports(f *fence) []importSpec {
+	file, err := parser.ParseFile(token.NewFileSet(), "", f.Code, parser.ImportsOnly)
+	if err != nil {
+		return nil
+	}
+	return specs(file)
+}
+
+func specs(file *ast.File) []importSpec {
+	var out []importSpec
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil {
+			continue
+		}
+		spec := importSpec{Path: p}
+		if imp.Name != nil {
+			if imp.Name.Name == "_" || imp.Name.Name == "." {
+				continue
+			}
+			spec.Name = imp.Name.Name
+		}
+		out = append(out, spec)
+	}
+	return out
+}
+
+// mergeImports appends the imports of add that neither repeat a path nor
+// reuse a name already in list.
+func mergeImports(list []importSpec, add ...importSpec) []importSpec {
+	for _, a := range add {
+		clash := false
+		for _, l := range list {
+			if l.Path == a.Path || l.ref() == a.ref() {
+				clash = true
+				break
+			}
+		}
+		if !clash {
+			list = append(list, a)
+		}
+	}
+	return list
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+type result struct {
+	Checked    int // fences type-checked
+	Skipped    int // fences annotated check=off
+	Unchecked  int // fences whose imports did not resolve
+	Problems   []problem
+	Unresolved []string
+}
+
+// typecheck type-checks every ```go fence of the READMEs with go/types, not
+// only the framework programs cmd/extract writes out. Imports resolve to
+// export data the go command builds in the chapter's framework directories.
+// Fragments are checked inside their section's package, wrapped in a
+// handler when they are bare statements; ```go check=off opts a fence out.
+// Errors are reported as README file:line.
+func main() {
+	var (
+		jobs    = flag.Int("p", runtime.NumCPU(), "number of module directories checked in parallel")
+		offline = flag.Bool("offline", false, "list fences whose imports do not resolve without failing")
+	)
+	flag.Parse()
+
+	readmes := flag.Args()
+	if len(readmes) == 0 {
+		var err error
+		readmes, err = filepath.Glob("[0-9][0-9]-*/README.md")
+		if err != nil {
+			panic(err)
+		}
+		if _, err := os.Stat("README.md"); err == nil {
+			readmes = append([]string{"README.md"}, readmes...)
+		}
+	}
+
+	var (
+		res   result
+		units []*unit
+	)
+	for _, readme := range readmes {
+		sections, err := loadSections(readme)
+		if err != nil {
+			panic(err)
+		}
+
+		// fragments outside a framework section may use anything the
+		// README's programs import
+		var fallback []importSpec
+		for _, sec := range sections {
+			for _, f := range sec.Fences {
+				if f.Kind == fileFence {
+					fallback = mergeImports(fallback, fileImports(f)...)
+				}
+			}
+		}
+
+		for _, sec := range sections {
+			us, skipped := plan(sec, fallback)
+			units = append(units, us...)
+			res.Skipped += skipped
+		}
+	}
+
+	run(newChecker(), units, *jobs, &res)
+	printSummary(res)
+
+	if len(res.Problems) > 0 || (len(res.Unresolved) > 0 && !*offline) {
+		os.Exit(1)
+	}
+}
+
+// run checks units grouped by module directory, one directory per worker,
+// with a single go list run for each.
+func run(c *checker, units []*unit, jobs int, res *result) {
+	groups := map[string][]*unit{}
+	for _, u := range units {
+		groups[u.Dir] = append(groups[u.Dir], u)
+	}
+
+	var (
+		mu         sync.Mutex
+		wg         sync.WaitGroup
+		sem        = make(chan struct{}, max(jobs, 1))
+		unresolved = map[string]bool{}
+	)
+
+	for dir, group := range groups {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			paths := map[string]bool{}
+			for _, u := range group {
+				for _, p := range importPaths(u) {
+					paths[p] = true
+				}
+			}
+
+			imp, err := c.importer(dir, sortedKeys(paths))
+			if err != nil {
+				panic(err)
+			}
+
+			for _, u := range group {
+				problems, missing := c.check(u, imp)
+
+				mu.Lock()
+				if len(missing) > 0 {
+					res.Unchecked += len(u.Report)
+					for _, m := range missing {
+						unresolved[m] = true
+					}
+				} else {
+					res.Checked += len(u.Report)
+				}
+				res.Problems = append(res.Problems, problems...)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(res.Problems, func(i, j int) bool {
+		a, b := res.Problems[i], res.Problems[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+	res.Unresolved = sortedKeys(unresolved)
+}
+
+func printSummary(res result) {
+	for _, p := range res.Problems {
+		fmt.Println(p)
+	}
+	if len(res.Problems) > 0 {
+		fmt.Println()
+	}
+
+	fmt.Println("summary")
+	fmt.Println("-------")
+	fmt.Printf("checked: %d\n", res.Checked)
+	fmt.Printf("skipped: %d\n", res.Skipped)
+	fmt.Printf("unchecked: %d\n", res.Unchecked)
+	fmt.Printf("errors: %d\n", len(res.Problems))
+
+	if len(res.Unresolved) > 0 {
+		fmt.Println("\nimports that did not resolve:")
+		for _, u := range res.Unresolved {
+			fmt.Println("-", u)
+		}
+	}
+
+	if len(res.Problems) == 0 && len(res.Unresolved) == 0 {
+		fmt.Println("\nall fences type-check")
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
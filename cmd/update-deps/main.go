@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-mizu/go-fw/internal/gotool"
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+// status is the outcome for one example module.
+type status string
+
+const (
+	statusUpgraded   status = "upgraded"
+	statusUnchanged  status = "unchanged"
+	statusRolledBack status = "rolled back"
+	statusSkipped    status = "skipped"
+)
+
+// upgrade is one framework requirement moved by go get.
+type upgrade struct {
+	Module string `json:"module"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+}
+
+// result is the report entry for one example module.
+type result struct {
+	Dir      string    `json:"dir"`
+	Status   status    `json:"status"`
+	Upgrades []upgrade `json:"upgrades,omitempty"`
+	Step     string    `json:"step,omitempty"` // get, tidy, build or vet when rolled back
+	Error    string    `json:"error,omitempty"`
+}
+
+type options struct {
+	chapter string
+	fw      string
+	report  string
+}
+
+// update-deps moves every go.work module, the examples and the framework
+// adapters under pkg/ alike, to the latest version of the frameworks it
+// imports. A module whose build or vet breaks afterwards gets its go.mod and
+// go.sum restored, and the failure is recorded in the report.
+//
+// Modules are built with GOWORK=off so each one is checked against its own
+// requirements, not the versions the workspace selects. For the same reason
+// there is no go work sync at the end: it would copy the workspace versions
+// back into the modules that were rolled back.
+func main() {
+	var opts options
+	flag.StringVar(&opts.chapter, "chapter", "", "only update modules whose directory starts with this prefix, such as a chapter or pkg/problem")
+	flag.StringVar(&opts.fw, "fw", "", "only update modules that import this framework ("+strings.Join(registry.Dirs(), ", ")+")")
+	flag.StringVar(&opts.report, "report", "tmp/update-deps.json", "where to write the JSON report")
+	flag.Parse()
+
+	dirs, err := gotool.WorkspaceModules()
+	if err != nil {
+		panic(err)
+	}
+
+	var results []result
+
+	for _, dir := range dirs {
+		if opts.chapter != "" && !strings.HasPrefix(dir, opts.chapter) {
+			continue
+		}
+		if opts.fw != "" && !usesFramework(dir, opts.fw) {
+			continue
+		}
+
+		fmt.Fprintln(os.Stderr, "==>", dir)
+		results = append(results, updateModule(dir))
+	}
+
+	if err := writeReport(opts.report, results); err != nil {
+		panic(err)
+	}
+
+	printSummary(results, opts.report)
+
+	for _, r := range results {
+		if r.Status == statusRolledBack {
+			os.Exit(1)
+		}
+	}
+}
+
+func writeReport(path string, results []result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func printSummary(results []result, report string) {
+	counts := map[status]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+
+	fmt.Println("summary")
+	fmt.Println("-------")
+	for _, s := range []status{statusUpgraded, statusUnchanged, statusRolledBack, statusSkipped} {
+		fmt.Printf("%s: %d\n", s, counts[s])
+	}
+	fmt.Println("report:", report)
+
+	var upgraded, failed []result
+	for _, r := range results {
+		switch r.Status {
+		case statusUpgraded:
+			upgraded = append(upgraded, r)
+		case statusRolledBack:
+			failed = append(failed, r)
+		}
+	}
+
+	if len(upgraded) > 0 {
+		fmt.Println()
+		fmt.Println("| Module | Dependency | From | To |")
+		fmt.Println("|---|---|---|---|")
+		for _, r := range upgraded {
+			for _, u := range r.Upgrades {
+				fmt.Printf("| %s | %s | %s | %s |\n", r.Dir, u.Module, u.From, u.To)
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		fmt.Println()
+		fmt.Println("rolled back:")
+		for _, r := range failed {
+			var deps []string
+			for _, u := range r.Upgrades {
+				deps = append(deps, u.Module+"@"+u.To)
+			}
+			fmt.Printf("- %s (%s failed after %s)\n", r.Dir, r.Step, strings.Join(deps, ", "))
+
+			first, _, _ := strings.Cut(r.Error, "\n")
+			fmt.Printf("    %s\n", first)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-mizu/go-fw/internal/gotool"
+	"github.com/go-mizu/go-fw/pkg/registry"
+	"golang.org/x/mod/modfile"
+)
+
+// updateModule upgrades the frameworks dir imports and checks that it still
+// builds, restoring go.mod and go.sum if it does not.
+func updateModule(dir string) result {
+	res := result{Dir: dir, Status: statusUnchanged}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		panic(err)
+	}
+	if len(files) == 0 {
+		res.Status = statusSkipped
+		return res
+	}
+
+	targets, err := importedFrameworks(files)
+	if err != nil {
+		res.Status = statusSkipped
+		res.Error = err.Error()
+		return res
+	}
+	if len(targets) == 0 {
+		return res
+	}
+
+	saved, err := snapshot(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	before, err := requirements(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	// Until go get resolves them, the upgrades are only requests for latest.
+	for _, mod := range targets {
+		res.Upgrades = append(res.Upgrades, upgrade{Module: mod, From: before[mod], To: "latest"})
+	}
+
+	fail := func(step string, err error) result {
+		if rerr := saved.restore(); rerr != nil {
+			panic(rerr)
+		}
+		res.Status = statusRolledBack
+		res.Step = step
+		res.Error = err.Error()
+		return res
+	}
+
+	args := []string{"get"}
+	for _, mod := range targets {
+		args = append(args, mod+"@latest")
+	}
+	if err := gocmd(dir, args...); err != nil {
+		return fail("get", err)
+	}
+	if err := gocmd(dir, "mod", "tidy"); err != nil {
+		return fail("tidy", err)
+	}
+
+	after, err := requirements(dir)
+	if err != nil {
+		panic(err)
+	}
+
+	res.Upgrades = nil
+	for _, mod := range targets {
+		if before[mod] != after[mod] {
+			res.Upgrades = append(res.Upgrades, upgrade{Module: mod, From: before[mod], To: after[mod]})
+		}
+	}
+
+	if len(res.Upgrades) == 0 {
+		// Nothing moved; drop whatever tidy reshuffled.
+		if err := saved.restore(); err != nil {
+			panic(err)
+		}
+		return res
+	}
+
+	if hasNonTestFiles(files) {
+		if err := gocmd(dir, "build", "-o", os.DevNull, "./..."); err != nil {
+			return fail("build", err)
+		}
+	}
+	if err := gocmd(dir, "vet", "./..."); err != nil {
+		return fail("vet", err)
+	}
+
+	res.Status = statusUpgraded
+	return res
+}
+
+// importedFrameworks returns the registry modules imported by files.
+func importedFrameworks(files []string) ([]string, error) {
+	imported := map[string]bool{}
+	fset := token.NewFileSet()
+
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, parser.ImportsOnly)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, imp := range f.Imports {
+			path, err := strconv.Unquote(imp.Path.Value)
+			if err != nil {
+				return nil, err
+			}
+			imported[path] = true
+		}
+	}
+
+	var mods []string
+	for _, mod := range registry.Modules() {
+		for path := range imported {
+			if path == mod || strings.HasPrefix(path, mod+"/") {
+				mods = append(mods, mod)
+				break
+			}
+		}
+	}
+
+	return mods, nil
+}
+
+// usesFramework reports whether the module in dir imports the framework
+// whose directory name is fw; for the examples that is the directory itself.
+func usesFramework(dir, fw string) bool {
+	f, ok := registry.ByDir(fw)
+	if !ok {
+		return false
+	}
+	if filepath.Base(dir) == fw {
+		return true
+	}
+	if f.Stdlib() {
+		return false
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return false
+	}
+	mods, err := importedFrameworks(files)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(mods, f.Module)
+}
+
+func hasNonTestFiles(files []string) bool {
+	for _, f := range files {
+		if !strings.HasSuffix(f, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// requirements returns the required version of every module in dir/go.mod.
+func requirements(dir string) (map[string]string, error) {
+	path := filepath.Join(dir, "go.mod")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := modfile.ParseLax(path, data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	reqs := map[string]string{}
+	for _, r := range f.Require {
+		reqs[r.Mod.Path] = r.Mod.Version
+	}
+
+	return reqs, nil
+}
+
+// saved holds go.mod and go.sum as they were before the upgrade. A nil
+// entry means the file did not exist.
+type saved map[string][]byte
+
+func snapshot(dir string) (saved, error) {
+	s := saved{}
+	for _, name := range []string{"go.mod", "go.sum"} {
+		path := filepath.Join(dir, name)
+
+		data, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		s[path] = data
+	}
+	return s, nil
+}
+
+func (s saved) restore() error {
+	for path, data := range s {
+		if data == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			continue
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gocmd runs the go command in dir outside the workspace and returns its
+// output as the error when it fails.
+func gocmd(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off")
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := gotool.CompilerOutput(out); msg != "" {
+			return fmt.Errorf("go %s: %s", args[0], msg)
+		}
+		return fmt.Errorf("go %s: %w", args[0], err)
+	}
+
+	return nil
+}
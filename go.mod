@@ -1,3 +1,5 @@
 module github.com/go-mizu/go-fw
 
 go 1.25
+
+require golang.org/x/mod v0.25.0
@@ -0,0 +1,174 @@
+package chaptertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+// benchSize is the size of the file BenchUpload uploads, 64 MiB.
+const benchSize = 64 << 20
+
+// pngMagic starts the file BenchUpload uploads, so it is sniffed as a PNG.
+var pngMagic = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// BenchUpload uploads a 64 MiB PNG to /upload and to /stream of the server
+// at baseURL, which must accept files of that size, over a real
+// connection, so the body is never in memory on the client side. Besides
+// time and allocations it reports peak-heap-B, the largest heap seen during
+// the run above the heap before it. Each stored file is deleted from store
+// again, so the run does not fill the disk.
+func BenchUpload(b *testing.B, baseURL string, store upload.ObjectStore) {
+	for _, path := range []string{"/upload", "/stream"} {
+		b.Run(path[1:], func(b *testing.B) {
+			measure(b, func(seq uint64) {
+				res := post(b, baseURL+path, seq)
+				defer res.Body.Close()
+
+				var env struct{ Data []upload.File }
+				if err := json.NewDecoder(res.Body).Decode(&env); err != nil || res.StatusCode != http.StatusCreated {
+					b.Fatalf("status %d: %v", res.StatusCode, err)
+				}
+
+				b.StopTimer()
+				for _, f := range env.Data {
+					store.Delete(context.Background(), f.Key)
+				}
+				b.StartTimer()
+			})
+		})
+	}
+}
+
+// BenchUploadOriginal uploads the same file as BenchUpload to url, the baseline: an
+// upload handler as the chapter wrote it before this package, with
+// ParseMultipartForm or the FormFile of the framework and a copy into a
+// file. Any 2xx response will do. The handler is expected to write the
+// same file every time, so nothing piles up.
+func BenchUploadOriginal(b *testing.B, url string) {
+	measure(b, func(seq uint64) {
+		res := post(b, url, seq)
+		defer res.Body.Close()
+
+		io.Copy(io.Discard, res.Body)
+		if res.StatusCode/100 != 2 {
+			b.Fatalf("status %d", res.StatusCode)
+		}
+	})
+}
+
+// measure runs upload b.N times and reports the throughput, allocations
+// and peak heap of the run.
+func measure(b *testing.B, upload func(seq uint64)) {
+	b.SetBytes(benchSize)
+	b.ReportAllocs()
+
+	peak := watchHeap()
+	for i := 0; i < b.N; i++ {
+		upload(uint64(i))
+	}
+	b.ReportMetric(float64(peak()), "peak-heap-B")
+}
+
+// post sends the form of bigForm to url.
+func post(b *testing.B, url string, seq uint64) *http.Response {
+	ct, body, n := bigForm(seq)
+	req, err := http.NewRequest(http.MethodPost, url, body)
+	if err != nil {
+		b.Fatal(err)
+	}
+	req.Header.Set("Content-Type", ct)
+	req.ContentLength = n
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return res
+}
+
+// bigForm returns a form with one benchSize PNG in the field "file" and the
+// length of its body. seq is written after the magic bytes, so every file
+// has a key of its own.
+func bigForm(seq uint64) (string, io.Reader, int64) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.CreateFormFile("file", "artifact.png")
+	head := bytes.Clone(buf.Bytes())
+
+	buf.Reset()
+	mw.Close()
+	tail := buf.Bytes()
+
+	file := append(bytes.Clone(pngMagic), make([]byte, 8)...)
+	binary.BigEndian.PutUint64(file[len(pngMagic):], seq)
+
+	body := io.MultiReader(
+		bytes.NewReader(head),
+		bytes.NewReader(file),
+		io.LimitReader(zeros{}, benchSize-int64(len(file))),
+		bytes.NewReader(tail),
+	)
+	return mw.FormDataContentType(), body, int64(len(head)) + benchSize + int64(len(tail))
+}
+
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// heapMetric is the memory taken by live and not yet collected objects.
+const heapMetric = "/memory/classes/heap/objects:bytes"
+
+// watchHeap samples the heap every millisecond until the returned function
+// is called, which gives the peak above the heap at the start.
+func watchHeap() func() uint64 {
+	runtime.GC()
+	sample := []metrics.Sample{{Name: heapMetric}}
+	read := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+	base := read()
+
+	var (
+		peak uint64
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			if v := read(); v > base {
+				peak = max(peak, v-base)
+			}
+			select {
+			case <-done:
+				return
+			case <-tick.C:
+			}
+		}
+	}()
+
+	return func() uint64 {
+		close(done)
+		wg.Wait()
+		return peak
+	}
+}
@@ -0,0 +1,121 @@
+// Package chaptertest is what the tests of the example directories share.
+// Run sends the conformance.json of the chapter to the example under test,
+// the same requests cmd/conformance sends to the built binaries, so go test
+// in every directory checks that the six variants answer alike. What a
+// shared package does beyond that is tested next to the package.
+package chaptertest
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-mizu/go-fw/internal/conformance"
+)
+
+// Doer sends one request to the example under test.
+type Doer func(*http.Request) (*http.Response, error)
+
+// Handler serves requests with h in process, which covers every framework
+// but Fiber.
+func Handler(h http.Handler) Doer {
+	return func(r *http.Request) (*http.Response, error) {
+		if r.Host == "" {
+			r.Host = "example.com"
+		}
+		r.RequestURI = r.URL.RequestURI()
+		// a server hands handlers an empty body, never none
+		if r.Body == nil {
+			r.Body = http.NoBody
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Result(), nil
+	}
+}
+
+// App serves requests with app in process through its Test method, as
+// *fiber.App has.
+func App(app interface {
+	Test(r *http.Request, msTimeout ...int) (*http.Response, error)
+}) Doer {
+	return func(r *http.Request) (*http.Response, error) {
+		if r.Host == "" {
+			r.Host = "example.com"
+		}
+		return app.Test(r, -1)
+	}
+}
+
+// URL sends requests over a connection to the server at base, such as
+// http://127.0.0.1:8080, for examples that call themselves. Redirects are
+// returned, not followed, as cmd/conformance does.
+func URL(base string) Doer {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return func(r *http.Request) (*http.Response, error) {
+		u, err := url.Parse(base + r.URL.RequestURI())
+		if err != nil {
+			return nil, err
+		}
+		r = r.Clone(r.Context())
+		r.URL = u
+		return client.Do(r)
+	}
+}
+
+// Listen serves app, a *fiber.App, on a local port until tb ends and
+// returns its URL.
+func Listen(tb testing.TB, app interface {
+	Listener(ln net.Listener) error
+	Shutdown() error
+}) string {
+	tb.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		tb.Fatal(err)
+	}
+	go app.Listener(ln)
+	tb.Cleanup(func() { app.Shutdown() })
+
+	return "http://" + ln.Addr().String()
+}
+
+// Run sends the requests of ../conformance.json, the spec of the chapter
+// when the test runs in an example directory, to do in order, each as a
+// subtest. Requests may depend on the ones before, as in the CRUD chapter,
+// so do must serve a fresh application. The spec is outside the module of
+// the example, so go test does not notice when it changes; run the tests
+// with -count=1 after editing it.
+func Run(t *testing.T, do Doer) {
+	t.Helper()
+
+	spec, err := conformance.Load(filepath.Join("..", conformance.File))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, r := range spec.Requests {
+		t.Run(r.Name, func(t *testing.T) {
+			req, err := r.NewRequest("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, msg := range r.Check(resp) {
+				t.Error(msg)
+			}
+		})
+	}
+}
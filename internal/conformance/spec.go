@@ -0,0 +1,230 @@
+// Package conformance reads the conformance.json of a chapter, the requests
+// every framework variant of it is sent and the expectations the responses
+// must meet. cmd/conformance sends them to the built examples, and
+// internal/chaptertest to each example in process, from its tests.
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// File is the name of the spec in every chapter directory.
+const File = "conformance.json"
+
+// Spec is the contents of NN-*/conformance.json. Every framework variant of
+// a chapter is driven with the same requests and must meet the same
+// expectations.
+type Spec struct {
+	Requests []Request `json:"requests"`
+}
+
+// Request is one request of a spec and what its response must be.
+type Request struct {
+	Name    string            `json:"name"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    string            `json:"body"`
+
+	// Limit stops reading the response body after this many bytes. It is
+	// required for endpoints that stream forever, such as SSE.
+	Limit int64 `json:"limit"`
+
+	Expect Expect `json:"expect"`
+}
+
+// Expect is what a response must be. A zero Status is not checked.
+type Expect struct {
+	Status  int                `json:"status"`
+	Headers map[string]Matcher `json:"headers"`
+	Body    *Matcher           `json:"body"`
+}
+
+// Matcher checks a header value or a response body. All set fields must hold.
+type Matcher struct {
+	Equals   *string `json:"equals"`
+	Contains string  `json:"contains"`
+	Regex    string  `json:"regex"`
+	JSON     any     `json:"json"`
+	Absent   bool    `json:"absent"`
+
+	re *regexp.Regexp // Regex, compiled by Load
+}
+
+// Load reads the spec at path. Requests without a method are GETs, and
+// without a name are named after method and path. A regular expression
+// that does not compile fails the spec.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Spec
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	for i, r := range s.Requests {
+		if r.Method == "" {
+			s.Requests[i].Method = http.MethodGet
+		}
+		if r.Name == "" {
+			s.Requests[i].Name = s.Requests[i].Method + " " + r.Path
+		}
+		if err := s.Requests[i].Expect.compile(); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, s.Requests[i].Name, err)
+		}
+	}
+
+	return &s, nil
+}
+
+// NewRequest returns r as an HTTP request to the server at base, such as
+// http://127.0.0.1:8080, or to no server when base is empty. A Host header
+// sets the Host of the request.
+func (r Request) NewRequest(base string) (*http.Request, error) {
+	var body io.Reader
+	if r.Body != "" {
+		body = strings.NewReader(r.Body)
+	}
+
+	req, err := http.NewRequest(r.Method, base+r.Path, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range r.Headers {
+		if strings.EqualFold(k, "Host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+// Check reads the body of resp, up to Limit bytes when r has one, closes it
+// and returns one message per expectation of r that resp does not meet.
+func (r Request) Check(resp *http.Response) []string {
+	defer resp.Body.Close()
+
+	// After a protocol switch the body is the raw connection; the handshake
+	// is all there is to check.
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		return r.Expect.Check(resp.StatusCode, resp.Header, nil)
+	}
+
+	var rd io.Reader = resp.Body
+	if r.Limit > 0 {
+		rd = io.LimitReader(resp.Body, r.Limit)
+	}
+
+	data, err := io.ReadAll(rd)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return []string{"read body: " + err.Error()}
+	}
+
+	return r.Expect.Check(resp.StatusCode, resp.Header, data)
+}
+
+// compile compiles the regular expressions of every matcher, so a bad
+// pattern fails the spec before any request is sent.
+func (e *Expect) compile() error {
+	for name, m := range e.Headers {
+		if err := m.compile(); err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+		e.Headers[name] = m
+	}
+	if e.Body != nil {
+		if err := e.Body.compile(); err != nil {
+			return fmt.Errorf("body: %w", err)
+		}
+	}
+	return nil
+}
+
+func (m *Matcher) compile() error {
+	if m.Regex == "" {
+		return nil
+	}
+	re, err := regexp.Compile(m.Regex)
+	if err != nil {
+		return err
+	}
+	m.re = re
+	return nil
+}
+
+// Check compares a response against the expectation and returns one
+// message per mismatch.
+func (e Expect) Check(status int, header http.Header, body []byte) []string {
+	var errs []string
+
+	if e.Status != 0 && status != e.Status {
+		errs = append(errs, fmt.Sprintf("status: want %d, got %d", e.Status, status))
+	}
+
+	for name, m := range e.Headers {
+		values, ok := header[http.CanonicalHeaderKey(name)]
+		if m.Absent {
+			if ok {
+				errs = append(errs, fmt.Sprintf("header %s: want absent, got %q", name, strings.Join(values, ", ")))
+			}
+			continue
+		}
+		if !ok {
+			errs = append(errs, fmt.Sprintf("header %s: missing", name))
+			continue
+		}
+		if msg := m.match(strings.Join(values, ", ")); msg != "" {
+			errs = append(errs, "header "+name+": "+msg)
+		}
+	}
+
+	if e.Body != nil {
+		if msg := e.Body.match(string(body)); msg != "" {
+			errs = append(errs, "body: "+msg)
+		}
+	}
+
+	return errs
+}
+
+func (m Matcher) match(got string) string {
+	if m.Equals != nil && got != *m.Equals {
+		return fmt.Sprintf("want %q, got %q", *m.Equals, got)
+	}
+
+	if m.Contains != "" && !strings.Contains(got, m.Contains) {
+		return fmt.Sprintf("want substring %q, got %q", m.Contains, got)
+	}
+
+	if m.re != nil && !m.re.MatchString(got) {
+		return fmt.Sprintf("want match for /%s/, got %q", m.Regex, got)
+	}
+
+	if m.JSON != nil {
+		var v any
+		if err := json.Unmarshal([]byte(got), &v); err != nil {
+			return fmt.Sprintf("want JSON, got %q", got)
+		}
+		want, _ := json.Marshal(m.JSON)
+		have, _ := json.Marshal(v)
+		if !bytes.Equal(want, have) {
+			return fmt.Sprintf("want JSON %s, got %s", want, have)
+		}
+	}
+
+	return ""
+}
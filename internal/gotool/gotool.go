@@ -0,0 +1,47 @@
+// Package gotool holds what the repository tools share about driving the go
+// command over the workspace.
+package gotool
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+)
+
+// WorkspaceModules returns the use directives of the go.work file in the
+// current directory, sorted.
+func WorkspaceModules() ([]string, error) {
+	data, err := os.ReadFile("go.work")
+	if err != nil {
+		return nil, err
+	}
+
+	wf, err := modfile.ParseWork("go.work", data, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	dirs := make([]string, 0, len(wf.Use))
+	for _, u := range wf.Use {
+		dirs = append(dirs, filepath.Clean(filepath.FromSlash(u.Path)))
+	}
+	sort.Strings(dirs)
+
+	return dirs, nil
+}
+
+// CompilerOutput drops download progress and package headers from the
+// output of a failed go command so the first line is the actual error.
+func CompilerOutput(out []byte) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if strings.HasPrefix(line, "go: downloading ") || strings.HasPrefix(line, "# ") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,180 @@
+// Package decode reads JSON request bodies strictly: the Content-Type must
+// be JSON, the body must fit a size limit, unknown fields and trailing data
+// are rejected. Failures are pkg/problem errors with status 400, 413 or 415
+// and a detail naming the offset or field at fault, so the problem adapters
+// of every framework can send them as they are.
+package decode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// DefaultLimit is the body size limit of Default, 1 MiB.
+const DefaultLimit = 1 << 20
+
+// Decoder decodes JSON bodies up to Limit bytes.
+type Decoder struct {
+	Limit int64
+}
+
+// Default is the Decoder JSON and Bytes use.
+var Default = Decoder{Limit: DefaultLimit}
+
+// JSON decodes the body of r into v with Default.
+func JSON(w http.ResponseWriter, r *http.Request, v any) error {
+	return Default.JSON(w, r, v)
+}
+
+// Bytes decodes an already read body into v with Default.
+func Bytes(contentType string, body []byte, v any) error {
+	return Default.Bytes(contentType, body, v)
+}
+
+// JSON decodes the body of r into v. The body is wrapped in
+// http.MaxBytesReader, which also tells the server to close the connection
+// once the limit is hit.
+func (d Decoder) JSON(w http.ResponseWriter, r *http.Request, v any) error {
+	if err := checkType(r.Header.Get("Content-Type")); err != nil {
+		return err
+	}
+	return d.decode(http.MaxBytesReader(w, r.Body, d.limit()), v)
+}
+
+// Bytes decodes body into v, for frameworks such as Fiber that hand the
+// handler the whole body instead of a reader.
+func (d Decoder) Bytes(contentType string, body []byte, v any) error {
+	if err := checkType(contentType); err != nil {
+		return err
+	}
+	if int64(len(body)) > d.limit() {
+		return tooLarge(d.limit())
+	}
+	return d.decode(bytes.NewReader(body), v)
+}
+
+func (d Decoder) limit() int64 {
+	if d.Limit <= 0 {
+		return DefaultLimit
+	}
+	return d.Limit
+}
+
+func (d Decoder) decode(body io.Reader, v any) error {
+	dec := json.NewDecoder(body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return explain(err)
+	}
+
+	// anything but white space after the value, found by reading on from
+	// where the decoder stopped so that the offset is exact whether or not
+	// the rest would decode
+	rest := bufio.NewReader(io.MultiReader(dec.Buffered(), body))
+	for offset := dec.InputOffset(); ; offset++ {
+		c, err := rest.ReadByte()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return explain(err)
+		}
+		if !isSpace(c) {
+			return trailing(offset)
+		}
+	}
+}
+
+// isSpace reports whether c is JSON white space.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// explain maps the errors of encoding/json and http.MaxBytesReader onto
+// problems. Anything else, such as a non-pointer v, is a programming error
+// and returned unchanged.
+func explain(err error) error {
+	var (
+		syntax *json.SyntaxError
+		typ    *json.UnmarshalTypeError
+		size   *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &size):
+		return tooLarge(size.Limit)
+	case errors.Is(err, io.EOF):
+		return bad("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return bad("request body ends in the middle of a JSON value")
+	case errors.As(err, &syntax):
+		return bad(fmt.Sprintf("malformed JSON at offset %d", syntax.Offset)).With("offset", syntax.Offset)
+	case errors.As(err, &typ):
+		if typ.Field == "" {
+			return bad(fmt.Sprintf("request body must be a JSON %s", kind(typ.Type))).With("offset", typ.Offset)
+		}
+		return bad(fmt.Sprintf("field %q must be a JSON %s, not %s", typ.Field, kind(typ.Type), typ.Value)).
+			With("field", typ.Field).
+			With("offset", typ.Offset)
+	}
+
+	// DisallowUnknownFields has no error type of its own
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		field := strings.Trim(name, `"`)
+		return bad(fmt.Sprintf("unknown field %q", field)).With("field", field)
+	}
+
+	return err
+}
+
+// checkType accepts application/json and the +json media types, with any
+// parameters.
+func checkType(contentType string) error {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json")) {
+		return nil
+	}
+	return problem.New(http.StatusUnsupportedMediaType, "Content-Type must be application/json")
+}
+
+func bad(detail string) *problem.Details {
+	return problem.New(http.StatusBadRequest, detail)
+}
+
+func trailing(offset int64) error {
+	return bad(fmt.Sprintf("unexpected data after the JSON value at offset %d", offset)).With("offset", offset)
+}
+
+func tooLarge(limit int64) error {
+	return problem.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit)).With("limit", limit)
+}
+
+// kind names the JSON type a Go type decodes from.
+func kind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Pointer:
+		return kind(t.Elem())
+	}
+	return "object"
+}
@@ -0,0 +1,107 @@
+package decode
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+type user struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int // 0 when the body decodes
+		detail      string
+		offset      any // the offset extension, nil when there is none
+		field       any
+	}{
+		{name: "valid", contentType: "application/json", body: `{"name":"a","age":3}`},
+		{name: "parameters and +json", contentType: "application/merge-patch+json; charset=utf-8", body: `{"name":"a"}`},
+		{name: "trailing white space", contentType: "application/json", body: "{\"name\":\"a\"} \r\n\t"},
+
+		{name: "no content type", body: `{}`, status: 415, detail: "Content-Type must be application/json"},
+		{name: "form", contentType: "application/x-www-form-urlencoded", body: `name=a`, status: 415, detail: "Content-Type must be application/json"},
+
+		{name: "too large", contentType: "application/json", body: `{"name":"` + strings.Repeat("a", 64) + `"}`, status: 413, detail: "request body exceeds 32 bytes"},
+
+		{name: "empty", contentType: "application/json", body: ``, status: 400, detail: "request body is empty"},
+		{name: "truncated", contentType: "application/json", body: `{"name":`, status: 400, detail: "request body ends in the middle of a JSON value"},
+		{name: "malformed", contentType: "application/json", body: `{"name" "a"}`, status: 400, detail: "malformed JSON at offset 9", offset: int64(9)},
+		{name: "not an object", contentType: "application/json", body: `[1]`, status: 400, detail: "request body must be a JSON object", offset: int64(1)},
+		{name: "wrong field type", contentType: "application/json", body: `{"age":"3"}`, status: 400, detail: `field "age" must be a JSON number, not string`, offset: int64(10), field: "age"},
+		{name: "unknown field", contentType: "application/json", body: `{"name":"a","admin":true}`, status: 400, detail: `unknown field "admin"`, field: "admin"},
+
+		{name: "second value", contentType: "application/json", body: `{"name":"a"} {"name":"b"}`, status: 400, detail: "unexpected data after the JSON value at offset 13", offset: int64(13)},
+		{name: "start of a second value", contentType: "application/json", body: `{"name":"a"}  {`, status: 400, detail: "unexpected data after the JSON value at offset 14", offset: int64(14)},
+		{name: "stray bracket", contentType: "application/json", body: `{"name":"a"}}`, status: 400, detail: "unexpected data after the JSON value at offset 12", offset: int64(12)},
+		{name: "garbage", contentType: "application/json", body: "{\"name\":\"a\"}\nxyz", status: 400, detail: "unexpected data after the JSON value at offset 13", offset: int64(13)},
+	}
+
+	d := Decoder{Limit: 32}
+	decoders := map[string]func(contentType, body string, v any) error{
+		"JSON": func(contentType, body string, v any) error {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+			if contentType != "" {
+				r.Header.Set("Content-Type", contentType)
+			}
+			return d.JSON(httptest.NewRecorder(), r, v)
+		},
+		"Bytes": func(contentType, body string, v any) error {
+			return d.Bytes(contentType, []byte(body), v)
+		},
+	}
+
+	for name, decode := range decoders {
+		for _, tt := range tests {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				var u user
+				err := decode(tt.contentType, tt.body, &u)
+
+				if tt.status == 0 {
+					if err != nil {
+						t.Fatalf("error = %v, want nil", err)
+					}
+					if u.Name != "a" {
+						t.Errorf("name = %q, want a", u.Name)
+					}
+					return
+				}
+
+				var p *problem.Details
+				if !errors.As(err, &p) {
+					t.Fatalf("error = %v, want a problem", err)
+				}
+				if p.Status != tt.status || p.Detail != tt.detail {
+					t.Errorf("problem = %d %q, want %d %q", p.Status, p.Detail, tt.status, tt.detail)
+				}
+				if got := p.Extensions["offset"]; got != tt.offset {
+					t.Errorf("offset = %v, want %v", got, tt.offset)
+				}
+				if got := p.Extensions["field"]; got != tt.field {
+					t.Errorf("field = %v, want %v", got, tt.field)
+				}
+			})
+		}
+	}
+}
+
+// Anything that is not a decoding error, such as a non-pointer target, is
+// returned unchanged.
+func TestDecodeNonPointer(t *testing.T) {
+	err := Bytes("application/json", []byte(`{}`), user{})
+
+	var p *problem.Details
+	if err == nil || errors.As(err, &p) {
+		t.Errorf("error = %v, want a plain error", err)
+	}
+}
@@ -0,0 +1,96 @@
+// Package highlight marks up Go source as HTML using go/scanner, so snippets
+// are colored without any JavaScript or external tool. The output only uses
+// <span class="..."> around tokens and is valid XHTML.
+package highlight
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"strings"
+)
+
+// Token classes used in the generated markup.
+const (
+	Comment = "c"
+	String  = "s"
+	Number  = "n"
+	Keyword = "k"
+	Builtin = "b" // predeclared types, constants and functions
+)
+
+// predeclared lists the identifiers of the universe scope.
+var predeclared = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		any bool byte comparable complex64 complex128 error float32 float64
+		int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr
+		true false iota nil
+		append cap clear close complex copy delete imag len make max min new panic
+		print println real recover`) {
+		predeclared[name] = true
+	}
+}
+
+// Go returns src as escaped HTML with tokens wrapped in spans. Fragments
+// that do not parse are still highlighted token by token; scanner errors
+// are ignored.
+func Go(src string) string {
+	var (
+		sb   strings.Builder
+		s    scanner.Scanner
+		last int
+	)
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	s.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+
+		class := classOf(tok, lit)
+		if class == "" {
+			continue
+		}
+
+		start := file.Offset(pos)
+		text := lit
+		if text == "" {
+			text = tok.String()
+		}
+		end := start + len(text)
+		if start < last || end > len(src) {
+			continue
+		}
+
+		sb.WriteString(html.EscapeString(src[last:start]))
+		sb.WriteString(`<span class="` + class + `">`)
+		sb.WriteString(html.EscapeString(src[start:end]))
+		sb.WriteString(`</span>`)
+		last = end
+	}
+
+	sb.WriteString(html.EscapeString(src[last:]))
+	return sb.String()
+}
+
+func classOf(tok token.Token, lit string) string {
+	switch {
+	case tok == token.COMMENT:
+		return Comment
+	case tok == token.STRING, tok == token.CHAR:
+		return String
+	case tok == token.INT, tok == token.FLOAT, tok == token.IMAG:
+		return Number
+	case tok.IsKeyword():
+		return Keyword
+	case tok == token.IDENT && predeclared[lit]:
+		return Builtin
+	}
+	return ""
+}
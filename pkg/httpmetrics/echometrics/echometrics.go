@@ -0,0 +1,25 @@
+// Package echometrics collects the pkg/httpmetrics metrics of Echo handlers.
+package echometrics
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+)
+
+// Middleware records every request with the route c.Path reports, which
+// is empty when no route matched. An error the handlers return is passed
+// to e.HTTPErrorHandler here, so the status recorded is the one sent, and
+// is not returned further. Install it with e.Use, which runs after routing.
+func Middleware(m *httpmetrics.Metrics) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			done := m.Begin(c.Request().Method)
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			done(c.Path(), c.Response().Status, c.Response().Size)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package fibermetrics collects the pkg/httpmetrics metrics of Fiber
+// handlers.
+package fibermetrics
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+	"github.com/go-mizu/go-fw/pkg/instrument/fiberinstrument"
+)
+
+// Middleware records every request with the path of the route that
+// served it. An error the handlers return is passed to the app's
+// ErrorHandler here, so the status recorded is the one sent, and is not
+// returned further. Install it with app.Use before the routes.
+func Middleware(m *httpmetrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		done := m.Begin(c.Method())
+
+		route := fiberinstrument.Next(c)
+		done(route, c.Response().StatusCode(), int64(len(c.Response().Body())))
+		return nil
+	}
+}
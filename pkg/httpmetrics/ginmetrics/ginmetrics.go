@@ -0,0 +1,19 @@
+// Package ginmetrics collects the pkg/httpmetrics metrics of Gin handlers.
+package ginmetrics
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/httpmetrics"
+)
+
+// Middleware records every request with the route c.FullPath reports,
+// which is empty when no route matched. Install it with r.Use before the
+// routes, so it also sees the requests answered with 404.
+func Middleware(m *httpmetrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := m.Begin(c.Request.Method)
+		c.Next()
+		done(c.FullPath(), c.Writer.Status(), int64(max(c.Writer.Size(), 0)))
+	}
+}
@@ -0,0 +1,124 @@
+// Package httpmetrics collects the Prometheus metrics of an HTTP server:
+// request count, duration and response size by method, route and status,
+// and the requests in flight.
+//
+// The route label is the template the router matched, such as
+// /users/{id}, never the raw path: a label value per user ID would grow
+// the series without bound until Prometheus runs out of memory. Requests
+// no route matched share the Unmatched label, so scanners probing random
+// paths add one series, not one per path.
+//
+// Middleware instruments net/http and Chi; ginmetrics, echometrics and
+// fibermetrics below this package do the same for their frameworks, with
+// the route each router resolves.
+package httpmetrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/go-mizu/go-fw/pkg/instrument"
+)
+
+// Unmatched is the route label of requests no route matched.
+const Unmatched = "unmatched"
+
+// SizeBuckets are the response size buckets, from 100 B to 10 MB.
+var SizeBuckets = prometheus.ExponentialBuckets(100, 10, 6)
+
+// Metrics holds the collectors. Create it with New, once per registry.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	size     *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// New registers the collectors with reg. It panics when reg already has
+// them, as prometheus.MustRegister does; tests give each server its own
+// prometheus.NewRegistry.
+func New(reg prometheus.Registerer) *Metrics {
+	labels := []string{"method", "route", "code"}
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total HTTP requests.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request duration in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, labels),
+		size: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "HTTP response body size in bytes.",
+			Buckets: SizeBuckets,
+		}, labels),
+		// the route is not known until the router has run, after the
+		// request entered
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests being served.",
+		}, []string{"method"}),
+	}
+	reg.MustRegister(m.requests, m.duration, m.size, m.inFlight)
+	return m
+}
+
+// Begin counts a request with method as in flight. The returned function
+// ends it, recording the route that served it, "" when none did, its
+// status code and the bytes of its body.
+func (m *Metrics) Begin(method string) func(route string, code int, size int64) {
+	method = normalizeMethod(method)
+	inFlight := m.inFlight.WithLabelValues(method)
+	inFlight.Inc()
+	start := time.Now()
+
+	return func(route string, code int, size int64) {
+		inFlight.Dec()
+		if route == "" {
+			route = Unmatched
+		}
+		labels := prometheus.Labels{"method": method, "route": route, "code": strconv.Itoa(code)}
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+		m.size.With(labels).Observe(float64(size))
+	}
+}
+
+// Middleware instruments net/http handlers. route names the route that
+// served r, and is called after next returns, when the router has set it:
+// Pattern for a ServeMux, chi.RouteContext(r.Context()).RoutePattern for
+// Chi. Install it around the router, not inside it.
+func (m *Metrics) Middleware(route func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			done := m.Begin(r.Method)
+			instrument.Serve(w, r, next, func(code int, size int64) {
+				done(route(r), code, size)
+			})
+		})
+	}
+}
+
+// Pattern is the route of a request served by an http.ServeMux: the
+// pattern that matched, without its method, which is a label of its own.
+func Pattern(r *http.Request) string {
+	p := r.Pattern
+	if method, rest, ok := strings.Cut(p, " "); ok && !strings.Contains(method, "/") {
+		p = strings.TrimLeft(rest, " \t")
+	}
+	return p
+}
+
+// normalizeMethod keeps the standard methods; any other becomes "other".
+func normalizeMethod(method string) string {
+	if instrument.KnownMethod(method) {
+		return method
+	}
+	return "other"
+}
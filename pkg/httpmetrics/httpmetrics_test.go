@@ -0,0 +1,215 @@
+package httpmetrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// routes serves GET /, GET /users/{id}, answering "user <id>\n", and
+// GET /wait, which answers once release is closed.
+func routes(reg prometheus.Registerer, release <-chan struct{}) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id") + "\n"))
+	})
+	mux.HandleFunc("GET /wait", func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	return New(reg).Middleware(Pattern)(mux)
+}
+
+func TestMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	h := routes(reg, nil)
+
+	requests := []struct {
+		method, path string
+		status       int
+	}{
+		{"GET", "/", 200},
+		{"GET", "/users/1", 200},
+		{"GET", "/users/2", 200},
+		{"GET", "/users/3", 200},
+		{"GET", "/nope", 404},
+		{"GET", "/nope/a", 404},
+		{"GET", "/nope/b", 404},
+		{"DELETE", "/users/1", 405},
+		{"BREW", "/users/1", 405},
+		{"brew", "/users/2", 405},
+	}
+	for _, r := range requests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(r.method, r.path, nil))
+		if rec.Code != r.status {
+			t.Fatalf("%s %s: status = %d, want %d", r.method, r.path, rec.Code, r.status)
+		}
+	}
+
+	families := gather(t, reg)
+	users := map[string]string{"method": "GET", "route": "/users/{id}", "code": "200"}
+
+	t.Run("requests by route template", func(t *testing.T) {
+		if got := find(t, families, "http_requests_total", users).GetCounter().GetValue(); got != 3 {
+			t.Errorf("http_requests_total%v = %v, want 3", users, got)
+		}
+	})
+
+	// the routes are the templates and the unmatched label, and the methods
+	// the known ones and "other", whatever the paths and methods were
+	t.Run("bounded labels", func(t *testing.T) {
+		for _, name := range []string{"http_requests_total", "http_request_duration_seconds", "http_response_size_bytes"} {
+			if got, want := labels(families[name], "route"), "/users/{id},/{$},unmatched"; got != want {
+				t.Errorf("%s routes = %s, want %s", name, got, want)
+			}
+			if got, want := labels(families[name], "method"), "DELETE,GET,other"; got != want {
+				t.Errorf("%s methods = %s, want %s", name, got, want)
+			}
+		}
+	})
+
+	t.Run("unmatched routes share a label", func(t *testing.T) {
+		for _, tt := range []struct {
+			labels map[string]string
+			want   float64
+		}{
+			{map[string]string{"method": "GET", "route": Unmatched, "code": "404"}, 3},
+			{map[string]string{"method": "DELETE", "route": Unmatched, "code": "405"}, 1},
+			{map[string]string{"method": "other", "route": Unmatched, "code": "405"}, 2},
+		} {
+			if got := find(t, families, "http_requests_total", tt.labels).GetCounter().GetValue(); got != tt.want {
+				t.Errorf("http_requests_total%v = %v, want %v", tt.labels, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		h := find(t, families, "http_request_duration_seconds", users).GetHistogram()
+		if h.GetSampleCount() != 3 || h.GetSampleSum() <= 0 {
+			t.Errorf("http_request_duration_seconds%v has %d samples summing to %v, want 3 above 0", users, h.GetSampleCount(), h.GetSampleSum())
+		}
+	})
+
+	t.Run("response size", func(t *testing.T) {
+		h := find(t, families, "http_response_size_bytes", users).GetHistogram()
+		// "user 1\n" and the others, 7 bytes each
+		if h.GetSampleCount() != 3 || h.GetSampleSum() != 21 {
+			t.Errorf("http_response_size_bytes%v has %d samples summing to %v, want 3 summing to 21", users, h.GetSampleCount(), h.GetSampleSum())
+		}
+	})
+}
+
+// A request is in flight from the moment it enters until its handler
+// returns, under its method alone.
+func TestInFlight(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	release := make(chan struct{})
+	h := routes(reg, release)
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/wait", nil))
+		close(done)
+	}()
+
+	// the gauge appears when the request enters
+	inFlight := map[string]string{"method": "GET"}
+	for len(gather(t, reg)["http_requests_in_flight"].GetMetric()) == 0 {
+		runtime.Gosched()
+	}
+	if got := find(t, gather(t, reg), "http_requests_in_flight", inFlight).GetGauge().GetValue(); got != 1 {
+		t.Errorf("http_requests_in_flight%v = %v during the request, want 1", inFlight, got)
+	}
+
+	close(release)
+	<-done
+	if got := find(t, gather(t, reg), "http_requests_in_flight", inFlight).GetGauge().GetValue(); got != 0 {
+		t.Errorf("http_requests_in_flight%v = %v after the request, want 0", inFlight, got)
+	}
+}
+
+func TestPattern(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{"GET /users/{id}", "/users/{id}"},
+		{"GET  /users/{id}", "/users/{id}"},
+		{"/users/{id}", "/users/{id}"},
+		{"GET example.com/users/{id}", "example.com/users/{id}"},
+		{"example.com/users/{id}", "example.com/users/{id}"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Pattern = tt.pattern
+		if got := Pattern(r); got != tt.want {
+			t.Errorf("Pattern(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func gather(t *testing.T, reg *prometheus.Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	families := map[string]*dto.MetricFamily{}
+	for _, f := range mfs {
+		families[f.GetName()] = f
+	}
+	return families
+}
+
+// find returns the metric of family name with exactly labels.
+func find(t *testing.T, families map[string]*dto.MetricFamily, name string, labels map[string]string) *dto.Metric {
+	t.Helper()
+
+	for _, m := range families[name].GetMetric() {
+		if matches(m, labels) {
+			return m
+		}
+	}
+	t.Fatalf("no %s%v", name, labels)
+	return nil
+}
+
+func matches(m *dto.Metric, labels map[string]string) bool {
+	if len(m.GetLabel()) != len(labels) {
+		return false
+	}
+	for _, l := range m.GetLabel() {
+		if v, ok := labels[l.GetName()]; !ok || v != l.GetValue() {
+			return false
+		}
+	}
+	return true
+}
+
+// labels lists the values of label name in f, sorted and comma-separated.
+func labels(f *dto.MetricFamily, name string) string {
+	seen := map[string]bool{}
+	for _, m := range f.GetMetric() {
+		for _, l := range m.GetLabel() {
+			if l.GetName() == name {
+				seen[l.GetValue()] = true
+			}
+		}
+	}
+	values := make([]string, 0, len(seen))
+	for v := range seen {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
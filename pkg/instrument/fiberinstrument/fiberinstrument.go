@@ -0,0 +1,25 @@
+// Package fiberinstrument is the part of pkg/instrument that depends on
+// Fiber, shared by fibermetrics and fibertracing.
+package fiberinstrument
+
+import "github.com/gofiber/fiber/v2"
+
+// Next runs the handlers after the calling middleware and returns the path
+// of the route that served the request, "" when none did. An error the
+// handlers return is passed to the app's ErrorHandler here, so the status
+// of c.Response() is the one sent; the middleware returns nil after it.
+func Next(c *fiber.Ctx) string {
+	own := c.Route()
+
+	if err := c.Next(); err != nil {
+		if err := c.App().ErrorHandler(c, err); err != nil {
+			c.Status(fiber.StatusInternalServerError)
+		}
+	}
+
+	// the route stays the middleware's own when no other matched
+	if r := c.Route(); r != own {
+		return r.Path
+	}
+	return ""
+}
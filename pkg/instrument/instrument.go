@@ -0,0 +1,87 @@
+// Package instrument holds what the HTTP instrumentation of httpmetrics and
+// tracing shares: recording the response a handler sends, and the methods
+// that are known by name.
+package instrument
+
+import "net/http"
+
+// Serve calls next with a Recorder around w, then done with the status
+// code and the body size of the response. done runs when next panics as
+// well, with the 500 net/http answers by dropping the connection, unless a
+// status was already sent; the panic then goes on.
+func Serve(w http.ResponseWriter, r *http.Request, next http.Handler, done func(code int, size int64)) {
+	rec := &Recorder{ResponseWriter: w}
+	returned := false
+	defer func() {
+		if !returned && rec.status == 0 {
+			rec.status = http.StatusInternalServerError
+		}
+		done(rec.Status(), rec.Size())
+	}()
+	next.ServeHTTP(rec, r)
+	returned = true
+}
+
+// Recorder records the status code and the body size of a response.
+type Recorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (w *Recorder) WriteHeader(code int) {
+	// 1xx informational responses leave the final status open
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *Recorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// Status is the status code sent, 200 for a handler that wrote nothing,
+// as net/http sends.
+func (w *Recorder) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+// Size is the number of body bytes written.
+func (w *Recorder) Size() int64 {
+	return w.size
+}
+
+// Unwrap lets http.ResponseController reach the flusher and hijacker of the
+// underlying writer.
+func (w *Recorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// methods are the methods of RFC 9110 and PATCH.
+var methods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// KnownMethod reports whether method is one of the standard methods. The
+// method is sent by the client, so anything else must share one label
+// value or attribute, or each made-up method would add a series.
+func KnownMethod(method string) bool {
+	return methods[method]
+}
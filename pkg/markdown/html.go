@@ -0,0 +1,378 @@
+package markdown
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HTMLOptions adjusts how blocks are rendered as HTML.
+type HTMLOptions struct {
+	// Shift is added to every heading level, clamped to 1..6.
+	Shift int
+
+	// Link rewrites link targets, for instance to point an in-book anchor
+	// at the page that holds it. Nil leaves targets unchanged.
+	Link func(href string) string
+
+	// Highlight returns the markup for a fence body, or false to fall back
+	// to escaped text.
+	Highlight func(lang, code string) (string, bool)
+}
+
+// HTML renders blocks as an XHTML fragment: void elements are closed and
+// all text is escaped, so the result also works inside EPUB documents.
+func HTML(blocks []*Block, opts HTMLOptions) string {
+	r := &renderer{opts: opts}
+	for _, b := range blocks {
+		r.block(b)
+	}
+	return r.sb.String()
+}
+
+type renderer struct {
+	sb   strings.Builder
+	opts HTMLOptions
+}
+
+func (r *renderer) block(b *Block) {
+	switch b.Kind {
+	case Heading:
+		level := strconv.Itoa(min(max(b.Level+r.opts.Shift, 1), 6))
+		r.sb.WriteString("<h" + level)
+		if b.ID != "" {
+			r.sb.WriteString(` id="` + html.EscapeString(b.ID) + `"`)
+		}
+		r.sb.WriteString(">" + r.inline(b.Text) + "</h" + level + ">\n")
+
+	case Fence:
+		code := strings.Join(b.Lines, "\n") + "\n"
+		lang := b.Lang()
+
+		body, ok := "", false
+		if r.opts.Highlight != nil {
+			body, ok = r.opts.Highlight(lang, code)
+		}
+		if !ok {
+			body = html.EscapeString(code)
+		}
+
+		r.sb.WriteString("<pre><code")
+		if lang != "" {
+			r.sb.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+		}
+		r.sb.WriteString(">" + body + "</code></pre>\n")
+
+	default:
+		r.text(b.Lines)
+	}
+}
+
+var (
+	listItemRE = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	ruleRE     = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	delimRE    = regexp.MustCompile(`^\s*\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?\s*$`)
+)
+
+// text renders the paragraphs, lists, tables, quotes and rules of a Text
+// block.
+func (r *renderer) text(lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case ruleRE.MatchString(line) && !listItemRE.MatchString(line):
+			r.sb.WriteString("<hr />\n")
+			i++
+
+		case strings.HasPrefix(trimmed, "|") && i+1 < len(lines) && delimRE.MatchString(lines[i+1]):
+			i = r.table(lines, i)
+
+		case strings.HasPrefix(trimmed, ">"):
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			r.sb.WriteString("<blockquote>\n")
+			r.text(quote)
+			r.sb.WriteString("</blockquote>\n")
+
+		case listItemRE.MatchString(line):
+			i = r.list(lines, i)
+
+		default:
+			var para []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				t := strings.TrimSpace(l)
+				if t == "" || listItemRE.MatchString(l) || strings.HasPrefix(t, ">") ||
+					(strings.HasPrefix(t, "|") && i+1 < len(lines) && delimRE.MatchString(lines[i+1])) {
+					break
+				}
+				para = append(para, t)
+			}
+			r.sb.WriteString("<p>" + r.inline(strings.Join(para, "\n")) + "</p>\n")
+		}
+	}
+}
+
+// list renders the list starting at lines[i] and returns the index after it.
+// Items indented deeper than the first marker become nested lists.
+func (r *renderer) list(lines []string, i int) int {
+	m := listItemRE.FindStringSubmatch(lines[i])
+	indent := len(m[1])
+
+	tag := "ul"
+	if m[2][0] >= '0' && m[2][0] <= '9' {
+		tag = "ol"
+	}
+	r.sb.WriteString("<" + tag + ">\n")
+
+	for i < len(lines) {
+		m := listItemRE.FindStringSubmatch(lines[i])
+		if m == nil || len(m[1]) != indent {
+			break
+		}
+
+		item := []string{m[3]}
+		var nested []string
+		for i++; i < len(lines); i++ {
+			l := lines[i]
+			t := strings.TrimSpace(l)
+
+			if t == "" {
+				// a blank line ends the item unless the list goes on
+				j := i + 1
+				for j < len(lines) && strings.TrimSpace(lines[j]) == "" {
+					j++
+				}
+				if j < len(lines) {
+					if n := listItemRE.FindStringSubmatch(lines[j]); n != nil && len(n[1]) >= indent {
+						i = j - 1
+						continue
+					}
+				}
+				break
+			}
+
+			if n := listItemRE.FindStringSubmatch(l); n != nil {
+				if len(n[1]) <= indent {
+					break
+				}
+				nested = append(nested, l)
+				continue
+			}
+
+			if len(nested) > 0 {
+				nested = append(nested, l)
+			} else {
+				item = append(item, t)
+			}
+		}
+
+		r.sb.WriteString("<li>" + r.inline(strings.Join(item, "\n")))
+		if len(nested) > 0 {
+			r.sb.WriteString("\n")
+			r.list(nested, 0)
+		}
+		r.sb.WriteString("</li>\n")
+
+		if i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+			break
+		}
+	}
+
+	r.sb.WriteString("</" + tag + ">\n")
+	return i
+}
+
+// table renders a pipe table whose header is lines[i] and returns the index
+// after its last row.
+func (r *renderer) table(lines []string, i int) int {
+	header := cells(lines[i])
+
+	var align []string
+	for _, d := range cells(lines[i+1]) {
+		switch {
+		case strings.HasPrefix(d, ":") && strings.HasSuffix(d, ":"):
+			align = append(align, "center")
+		case strings.HasSuffix(d, ":"):
+			align = append(align, "right")
+		case strings.HasPrefix(d, ":"):
+			align = append(align, "left")
+		default:
+			align = append(align, "")
+		}
+	}
+
+	row := func(tag string, cols []string) {
+		r.sb.WriteString("<tr>")
+		for c, text := range cols {
+			r.sb.WriteString("<" + tag)
+			if c < len(align) && align[c] != "" {
+				r.sb.WriteString(` style="text-align: ` + align[c] + `"`)
+			}
+			r.sb.WriteString(">" + r.inline(text) + "</" + tag + ">")
+		}
+		r.sb.WriteString("</tr>\n")
+	}
+
+	r.sb.WriteString("<table>\n<thead>\n")
+	row("th", header)
+	r.sb.WriteString("</thead>\n<tbody>\n")
+
+	for i += 2; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "|"); i++ {
+		row("td", cells(lines[i]))
+	}
+
+	r.sb.WriteString("</tbody>\n</table>\n")
+	return i
+}
+
+// cells splits a table row on unescaped pipes outside code spans.
+func cells(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var (
+		out  []string
+		cur  strings.Builder
+		code bool
+	)
+	for k := 0; k < len(line); k++ {
+		c := line[k]
+		switch {
+		case c == '\\' && k+1 < len(line) && line[k+1] == '|':
+			cur.WriteByte('|')
+			k++
+		case c == '`':
+			code = !code
+			cur.WriteByte(c)
+		case c == '|' && !code:
+			out = append(out, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(out, strings.TrimSpace(cur.String()))
+}
+
+// inline renders code spans, emphasis, links and autolinks; everything else
+// is escaped text.
+func (r *renderer) inline(s string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!|<>", s[i+1]) >= 0:
+			sb.WriteString(html.EscapeString(s[i+1 : i+2]))
+			i += 2
+			continue
+
+		case c == '`':
+			n := run(s[i:], '`')
+			fence := s[i : i+n]
+			if end := strings.Index(s[i+n:], fence); end >= 0 {
+				code := strings.TrimSpace(s[i+n : i+n+end])
+				sb.WriteString("<code>" + html.EscapeString(code) + "</code>")
+				i += n + end + n
+				continue
+			}
+			sb.WriteString(fence)
+			i += n
+			continue
+
+		case c == '*' || c == '_':
+			n := min(run(s[i:], c), 2)
+			// _ only emphasizes at word boundaries, so snake_case stays put
+			if c == '_' && i > 0 && isWord(s[i-1]) {
+				break
+			}
+			marker := s[i : i+n]
+			if i+n < len(s) && s[i+n] != ' ' {
+				if end := strings.Index(s[i+n:], marker); end > 0 && s[i+n+end-1] != ' ' {
+					tag := "em"
+					if n == 2 {
+						tag = "strong"
+					}
+					sb.WriteString("<" + tag + ">" + r.inline(s[i+n:i+n+end]) + "</" + tag + ">")
+					i += n + end + n
+					continue
+				}
+			}
+
+		case c == '[':
+			if text, href, n, ok := link(s[i:]); ok {
+				if r.opts.Link != nil {
+					href = r.opts.Link(href)
+				}
+				sb.WriteString(`<a href="` + html.EscapeString(href) + `">` + r.inline(text) + "</a>")
+				i += n
+				continue
+			}
+
+		case c == '<':
+			if end := strings.IndexByte(s[i:], '>'); end > 0 {
+				url := s[i+1 : i+end]
+				if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+					sb.WriteString(`<a href="` + html.EscapeString(url) + `">` + html.EscapeString(url) + "</a>")
+					i += end + 1
+					continue
+				}
+			}
+		}
+
+		sb.WriteString(html.EscapeString(s[i : i+1]))
+		i++
+	}
+
+	return sb.String()
+}
+
+// link parses [text](href) at the start of s.
+func link(s string) (text, href string, n int, ok bool) {
+	depth := 0
+	for k := 0; k < len(s); k++ {
+		switch s[k] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				if k+1 >= len(s) || s[k+1] != '(' {
+					return "", "", 0, false
+				}
+				end := strings.IndexByte(s[k+2:], ')')
+				if end < 0 {
+					return "", "", 0, false
+				}
+				return s[1:k], strings.TrimSpace(s[k+2 : k+2+end]), k + 2 + end + 1, true
+			}
+		}
+	}
+	return "", "", 0, false
+}
+
+func run(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func isWord(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,172 @@
+// Package markdown parses the subset of Markdown the chapters use into a
+// flat list of blocks. Headings and fenced code are structured; everything
+// else is kept as the original lines, so a parsed document renders back
+// byte for byte unless a tool changes it.
+package markdown
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kind is the type of a Block.
+type Kind int
+
+const (
+	// Text is any run of lines that is neither a heading nor a fence:
+	// paragraphs, lists, tables, quotes and blank lines.
+	Text Kind = iota
+	// Heading is an ATX heading such as "## Gin".
+	Heading
+	// Fence is a ``` fenced code block.
+	Fence
+)
+
+// Block is one top-level element of a document.
+type Block struct {
+	Kind  Kind
+	Line  int // 1-based line of the block in the source
+	Level int // heading level, 1 to 6
+
+	// Text is the heading text. ID, when set, is emitted as an HTML anchor
+	// above the heading so links keep working across renderers.
+	Text string
+	ID   string
+
+	Info  string   // fence info string, e.g. "go file=handler_test.go"
+	Lines []string // fence body, or the raw lines of a Text block
+}
+
+// Lang returns the language of a fence, the first word of its info string.
+func (b *Block) Lang() string {
+	lang, _, _ := strings.Cut(b.Info, " ")
+	return lang
+}
+
+// Document is a parsed Markdown file.
+type Document struct {
+	Blocks []*Block
+}
+
+// Parse splits src into blocks. An unterminated fence runs to the end of the
+// document.
+func Parse(src []byte) *Document {
+	lines := strings.Split(strings.TrimSuffix(string(src), "\n"), "\n")
+
+	doc := &Document{}
+	var text *Block
+
+	flush := func() {
+		if text != nil {
+			doc.Blocks = append(doc.Blocks, text)
+			text = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if info, ok := strings.CutPrefix(line, "```"); ok {
+			flush()
+			b := &Block{Kind: Fence, Line: i + 1, Info: strings.TrimSpace(info)}
+			for i++; i < len(lines) && !strings.HasPrefix(lines[i], "```"); i++ {
+				b.Lines = append(b.Lines, lines[i])
+			}
+			doc.Blocks = append(doc.Blocks, b)
+			continue
+		}
+
+		if level, title, ok := heading(line); ok {
+			flush()
+			doc.Blocks = append(doc.Blocks, &Block{Kind: Heading, Line: i + 1, Level: level, Text: title})
+			continue
+		}
+
+		if text == nil {
+			text = &Block{Kind: Text, Line: i + 1}
+		}
+		text.Lines = append(text.Lines, line)
+	}
+	flush()
+
+	return doc
+}
+
+// heading parses an ATX heading line.
+func heading(line string) (int, string, bool) {
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0, "", false
+	}
+
+	rest := line[level:]
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return 0, "", false
+	}
+
+	// drop an optional closing sequence: "## Title ##"
+	title := strings.TrimSpace(rest)
+	if trimmed := strings.TrimRight(title, "#"); trimmed != title && (trimmed == "" || strings.HasSuffix(trimmed, " ")) {
+		title = strings.TrimSpace(trimmed)
+	}
+
+	return level, title, true
+}
+
+// Headings returns the heading blocks in document order.
+func (d *Document) Headings() []*Block {
+	var out []*Block
+	for _, b := range d.Blocks {
+		if b.Kind == Heading {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Markdown renders the document back to Markdown.
+func (d *Document) Markdown() []byte {
+	var sb strings.Builder
+	for _, b := range d.Blocks {
+		b.writeMarkdown(&sb)
+	}
+	return []byte(sb.String())
+}
+
+func (b *Block) writeMarkdown(sb *strings.Builder) {
+	switch b.Kind {
+	case Heading:
+		if b.ID != "" {
+			sb.WriteString(`<a id="` + b.ID + `"></a>` + "\n\n")
+		}
+		sb.WriteString(strings.Repeat("#", b.Level) + " " + b.Text + "\n")
+	case Fence:
+		sb.WriteString("```" + b.Info + "\n")
+		for _, l := range b.Lines {
+			sb.WriteString(l + "\n")
+		}
+		sb.WriteString("```\n")
+	default:
+		for _, l := range b.Lines {
+			sb.WriteString(l + "\n")
+		}
+	}
+}
+
+// Slug turns heading text into an anchor the way GitHub does: lower case,
+// punctuation dropped, spaces turned into hyphens.
+func Slug(s string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			sb.WriteRune(r)
+		case r == ' ':
+			sb.WriteByte('-')
+		}
+	}
+	return sb.String()
+}
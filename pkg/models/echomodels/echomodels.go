@@ -0,0 +1,13 @@
+// Package echomodels writes the pkg/models envelopes from Echo handlers.
+package echomodels
+
+import (
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+// Write sends r as JSON with the status it carries.
+func Write(c echo.Context, r models.Envelope) error {
+	return c.JSON(r.StatusCode(), r)
+}
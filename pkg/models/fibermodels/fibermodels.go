@@ -0,0 +1,13 @@
+// Package fibermodels writes the pkg/models envelopes from Fiber handlers.
+package fibermodels
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+// Write sends r as JSON with the status it carries.
+func Write(c *fiber.Ctx, r models.Envelope) error {
+	return c.Status(r.StatusCode()).JSON(r)
+}
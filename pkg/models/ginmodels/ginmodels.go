@@ -0,0 +1,18 @@
+// Package ginmodels writes the pkg/models envelopes from Gin handlers.
+package ginmodels
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+// Write sends r as JSON with the status it carries. Error statuses abort
+// the handler chain, so later handlers do not write over the envelope.
+func Write(c *gin.Context, r models.Envelope) {
+	if r.StatusCode() >= 400 {
+		c.AbortWithStatusJSON(r.StatusCode(), r)
+		return
+	}
+	c.JSON(r.StatusCode(), r)
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Write sends r as JSON through a net/http handler, with the status the
+// envelope carries. Chi handlers use it as well.
+func Write(w http.ResponseWriter, r Envelope) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(r.StatusCode())
+	return json.NewEncoder(w).Encode(r)
+}
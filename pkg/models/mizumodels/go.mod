@@ -0,0 +1,10 @@
+module github.com/go-mizu/go-fw/pkg/models/mizumodels
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/go-mizu/mizu v0.2.2
+)
+
+replace github.com/go-mizu/go-fw => ../../..
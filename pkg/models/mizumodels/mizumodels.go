@@ -0,0 +1,13 @@
+// Package mizumodels writes the pkg/models envelopes from Mizu handlers.
+package mizumodels
+
+import (
+	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+// Write sends r as JSON with the status it carries.
+func Write(c *mizu.Ctx, r models.Envelope) error {
+	return c.JSON(r.StatusCode(), r)
+}
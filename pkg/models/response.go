@@ -1,30 +1,148 @@
 package models
 
+import "net/http"
+
+// Envelope is implemented by every response body below. StatusCode is the
+// HTTP status the body is sent with; the framework adapters use it so a
+// handler only picks the envelope.
+type Envelope interface {
+	StatusCode() int
+}
+
 // Standard API response structure
-type ApiResponse struct {
-	Code    int         `json:"code"`    // Custom status code
-	Message string      `json:"message"` // Message for frontend display
-	Data    interface{} `json:"data"`    // Actual data content, can be anything
+type Response[T any] struct {
+	Code    int    `json:"code" xml:"code"`       // HTTP status code
+	Message string `json:"message" xml:"message"` // Message for frontend display
+	Data    T      `json:"data" xml:"data"`       // Actual data content
 }
 
+// ApiResponse is the untyped envelope the examples used before Response.
+// It encodes the same as it always did.
+//
+// Deprecated: use Response[T].
+type ApiResponse = Response[any]
+
 // Standard error response structure
 type ErrorResponse struct {
-	Code    int    `json:"code"`    // Error code
-	Message string `json:"message"` // Error message
+	Code    int          `json:"code" xml:"code"`                        // HTTP status code
+	Message string       `json:"message" xml:"message"`                  // Error message
+	Errors  []FieldError `json:"errors,omitempty" xml:"error,omitempty"` // Invalid fields, from Invalid
 }
 
 // Standard pagination information
 type Pagination struct {
-	Page      int `json:"page"`       // Current page
-	PageSize  int `json:"page_size"`  // Number of items per page
-	Total     int `json:"total"`      // Total number of items
-	TotalPage int `json:"total_page"` // Total number of pages
+	Page      int `json:"page" xml:"page"`             // Current page
+	PageSize  int `json:"page_size" xml:"page_size"`   // Number of items per page
+	Total     int `json:"total" xml:"total"`           // Total number of items
+	TotalPage int `json:"total_page" xml:"total_page"` // Total number of pages
 }
 
 // Response structure for paginated data
-type PageResponse struct {
-	Code       int         `json:"code"`
-	Message    string      `json:"message"`
-	Data       interface{} `json:"data"`
-	Pagination Pagination  `json:"pagination"`
+type PageResponse[T any] struct {
+	Code       int        `json:"code" xml:"code"`
+	Message    string     `json:"message" xml:"message"`
+	Data       []T        `json:"data" xml:"data>item"`
+	Pagination Pagination `json:"pagination" xml:"pagination"`
+}
+
+// Cursors holds the tokens of the neighbouring pages in cursor pagination
+type Cursors struct {
+	Next string `json:"next,omitempty" xml:"next,omitempty"` // token of the following page
+	Prev string `json:"prev,omitempty" xml:"prev,omitempty"` // token of the preceding page
+}
+
+// Response structure for cursor paginated data
+type CursorResponse[T any] struct {
+	Code    int     `json:"code" xml:"code"`
+	Message string  `json:"message" xml:"message"`
+	Data    []T     `json:"data" xml:"data>item"`
+	Cursors Cursors `json:"cursors" xml:"cursors"`
+}
+
+// OK wraps data in a 200 response.
+func OK[T any](data T) Response[T] {
+	return Response[T]{Code: http.StatusOK, Message: http.StatusText(http.StatusOK), Data: data}
+}
+
+// Created wraps data in a 201 response, for a resource a handler just made.
+func Created[T any](data T) Response[T] {
+	return Response[T]{Code: http.StatusCreated, Message: http.StatusText(http.StatusCreated), Data: data}
+}
+
+// Fail is an error response with the given HTTP status. An empty message
+// falls back to the status text.
+func Fail(status int, message string) ErrorResponse {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+	return ErrorResponse{Code: status, Message: message}
+}
+
+// Paginated is a 200 response holding one page of items out of total.
+// Data is an empty list rather than null when the page is empty.
+func Paginated[T any](items []T, page, pageSize, total int) PageResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	pages := 0
+	if pageSize > 0 {
+		pages = (total + pageSize - 1) / pageSize
+	}
+
+	return PageResponse[T]{
+		Code:    http.StatusOK,
+		Message: http.StatusText(http.StatusOK),
+		Data:    items,
+		Pagination: Pagination{
+			Page:      page,
+			PageSize:  pageSize,
+			Total:     total,
+			TotalPage: pages,
+		},
+	}
+}
+
+// CursorPaginated is a 200 response holding one page of items and the
+// tokens of the pages around it. Data is an empty list rather than null.
+func CursorPaginated[T any](items []T, next, prev string) CursorResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	return CursorResponse[T]{
+		Code:    http.StatusOK,
+		Message: http.StatusText(http.StatusOK),
+		Data:    items,
+		Cursors: Cursors{Next: next, Prev: prev},
+	}
+}
+
+// WithMessage returns a copy of r with its display message replaced.
+func (r Response[T]) WithMessage(message string) Response[T] {
+	r.Message = message
+	return r
+}
+
+// WithMessage returns a copy of r with its display message replaced.
+func (r PageResponse[T]) WithMessage(message string) PageResponse[T] {
+	r.Message = message
+	return r
+}
+
+func (r Response[T]) StatusCode() int { return statusOr(r.Code, http.StatusOK) }
+
+func (r PageResponse[T]) StatusCode() int { return statusOr(r.Code, http.StatusOK) }
+
+func (r CursorResponse[T]) StatusCode() int { return statusOr(r.Code, http.StatusOK) }
+
+func (r ErrorResponse) StatusCode() int { return statusOr(r.Code, http.StatusInternalServerError) }
+
+// statusOr returns code when it is a valid HTTP status and fallback
+// otherwise, so an application specific code never reaches the wire.
+func statusOr(code, fallback int) int {
+	if code < 100 || code > 599 {
+		return fallback
+	}
+	return code
 }
@@ -2,9 +2,9 @@ package models
 
 // Specific business data structure
 type UserData struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID    int    `json:"id" xml:"id"`
+	Email string `json:"email" xml:"email"`
+	Role  string `json:"role" xml:"role"`
 }
 
 // Request structure for creating a user
@@ -14,8 +14,47 @@ type CreateUserRequest struct {
 	Role     string `json:"role"`
 }
 
-// Request structure for updating a user
+// Request structure for updating a user. Fields are pointers so an absent
+// field, left unchanged, differs from one sent empty.
 type UpdateUserRequest struct {
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	Email *string `json:"email,omitempty"`
+	Role  *string `json:"role,omitempty"`
+}
+
+// Roles a user can have
+var Roles = []string{"admin", "editor", "viewer"}
+
+// Validate checks every field and reports all invalid ones at once.
+func (r CreateUserRequest) Validate() error {
+	var errs ValidationError
+	errs.check("email", validEmail(r.Email))
+	errs.check("password", validPassword(r.Password))
+	errs.check("role", validRole(r.Role))
+	return errs.err()
+}
+
+// Validate checks the fields that are present. An update that changes
+// nothing is invalid as well.
+func (r UpdateUserRequest) Validate() error {
+	var errs ValidationError
+	if r.Email == nil && r.Role == nil {
+		errs.add("", "at least one field is required")
+	}
+	if r.Email != nil {
+		errs.check("email", validEmail(*r.Email))
+	}
+	if r.Role != nil {
+		errs.check("role", validRole(*r.Role))
+	}
+	return errs.err()
+}
+
+// Apply copies the fields present in r onto u.
+func (r UpdateUserRequest) Apply(u *UserData) {
+	if r.Email != nil {
+		u.Email = *r.Email
+	}
+	if r.Role != nil {
+		u.Role = *r.Role
+	}
 }
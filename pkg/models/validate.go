@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Validator is implemented by request bodies that check themselves after
+// decoding. Validate returns a ValidationError listing the invalid fields.
+type Validator interface {
+	Validate() error
+}
+
+// Field level validation error
+type FieldError struct {
+	Field   string `json:"field,omitempty" xml:"field,omitempty"` // JSON name of the field, empty for the whole body
+	Message string `json:"message" xml:"message"`                 // What is wrong with it
+}
+
+// ValidationError lists the invalid fields of a request.
+type ValidationError []FieldError
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, f := range e {
+		msgs[i] = f.Message
+		if f.Field != "" {
+			msgs[i] = f.Field + ": " + f.Message
+		}
+	}
+	return "invalid request: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// check records message for field unless it is empty.
+func (e *ValidationError) check(field, message string) {
+	if message != "" {
+		e.add(field, message)
+	}
+}
+
+// err returns e as an error, or nil when no field is invalid.
+func (e ValidationError) err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Invalid is the error response for a failed Validate: 422 with the field
+// errors when err holds a ValidationError, 400 with err's message
+// otherwise.
+func Invalid(err error) ErrorResponse {
+	var verr ValidationError
+	if !errors.As(err, &verr) {
+		return Fail(http.StatusBadRequest, err.Error())
+	}
+
+	r := Fail(http.StatusUnprocessableEntity, "Validation failed")
+	r.Errors = verr
+	return r
+}
+
+func validEmail(s string) string {
+	if s == "" {
+		return "is required"
+	}
+	// ParseAddress also accepts "Name <addr>"; only a bare address is valid
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+func validPassword(s string) string {
+	switch {
+	case s == "":
+		return "is required"
+	case utf8.RuneCountInString(s) < 8:
+		return "must be at least 8 characters"
+	case len(s) > 72:
+		// bcrypt ignores anything longer
+		return "must be at most 72 bytes"
+	}
+
+	var letter, digit bool
+	for _, r := range s {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	if !letter || !digit {
+		return "must contain a letter and a digit"
+	}
+	return ""
+}
+
+func validRole(s string) string {
+	if s == "" {
+		return "is required"
+	}
+	if !slices.Contains(Roles, s) {
+		return "must be one of " + strings.Join(Roles, ", ")
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestCreateUserRequestValidate(t *testing.T) {
+	valid := CreateUserRequest{Email: "ann@example.com", Password: "hunter22", Role: "admin"}
+
+	tests := []struct {
+		name string
+		edit func(r *CreateUserRequest)
+		want ValidationError
+	}{
+		{name: "valid", edit: func(r *CreateUserRequest) {}},
+		{
+			name: "everything missing",
+			edit: func(r *CreateUserRequest) { *r = CreateUserRequest{} },
+			want: ValidationError{{"email", "is required"}, {"password", "is required"}, {"role", "is required"}},
+		},
+		{name: "display name", edit: func(r *CreateUserRequest) { r.Email = "Ann <ann@example.com>" }, want: ValidationError{{"email", "must be a valid email address"}}},
+		{name: "no domain", edit: func(r *CreateUserRequest) { r.Email = "ann" }, want: ValidationError{{"email", "must be a valid email address"}}},
+		{name: "non-ASCII email", edit: func(r *CreateUserRequest) { r.Email = "zoë@example.com" }},
+
+		{name: "seven characters", edit: func(r *CreateUserRequest) { r.Password = "hunter2" }, want: ValidationError{{"password", "must be at least 8 characters"}}},
+		{name: "eight characters in more bytes", edit: func(r *CreateUserRequest) { r.Password = "пароль12" }},
+		{name: "seven characters in eight bytes", edit: func(r *CreateUserRequest) { r.Password = "pässwö1" }, want: ValidationError{{"password", "must be at least 8 characters"}}},
+		{name: "72 bytes", edit: func(r *CreateUserRequest) { r.Password = strings.Repeat("a", 71) + "1" }},
+		{name: "73 bytes", edit: func(r *CreateUserRequest) { r.Password = strings.Repeat("a", 72) + "1" }, want: ValidationError{{"password", "must be at most 72 bytes"}}},
+		{name: "25 characters in 75 bytes", edit: func(r *CreateUserRequest) { r.Password = strings.Repeat("€", 24) + "1" }, want: ValidationError{{"password", "must be at most 72 bytes"}}},
+		{name: "no digit", edit: func(r *CreateUserRequest) { r.Password = "password" }, want: ValidationError{{"password", "must contain a letter and a digit"}}},
+		{name: "no letter", edit: func(r *CreateUserRequest) { r.Password = "12345678" }, want: ValidationError{{"password", "must contain a letter and a digit"}}},
+
+		{name: "unknown role", edit: func(r *CreateUserRequest) { r.Role = "root" }, want: ValidationError{{"role", "must be one of admin, editor, viewer"}}},
+		{name: "role is case sensitive", edit: func(r *CreateUserRequest) { r.Role = "Admin" }, want: ValidationError{{"role", "must be one of admin, editor, viewer"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid
+			tt.edit(&r)
+			checkValidation(t, r.Validate(), tt.want)
+		})
+	}
+}
+
+func TestUpdateUserRequestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		r    UpdateUserRequest
+		want ValidationError
+	}{
+		{name: "nothing to change", r: UpdateUserRequest{}, want: ValidationError{{"", "at least one field is required"}}},
+		{name: "email only", r: UpdateUserRequest{Email: ptr("zoë@example.com")}},
+		{name: "role only", r: UpdateUserRequest{Role: ptr("viewer")}},
+		{name: "empty email sent", r: UpdateUserRequest{Email: ptr("")}, want: ValidationError{{"email", "is required"}}},
+		{
+			name: "both invalid",
+			r:    UpdateUserRequest{Email: ptr("nope"), Role: ptr("owner")},
+			want: ValidationError{{"email", "must be a valid email address"}, {"role", "must be one of admin, editor, viewer"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkValidation(t, tt.r.Validate(), tt.want)
+		})
+	}
+}
+
+func TestUpdateUserRequestApply(t *testing.T) {
+	u := UserData{ID: 7, Email: "ann@example.com", Role: "viewer"}
+
+	UpdateUserRequest{Role: ptr("editor")}.Apply(&u)
+	if want := (UserData{ID: 7, Email: "ann@example.com", Role: "editor"}); u != want {
+		t.Errorf("after a role update: %+v, want %+v", u, want)
+	}
+
+	UpdateUserRequest{Email: ptr("zoë@example.com")}.Apply(&u)
+	if want := (UserData{ID: 7, Email: "zoë@example.com", Role: "editor"}); u != want {
+		t.Errorf("after an email update: %+v, want %+v", u, want)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	verr := ValidationError{{"email", "is required"}, {"", "at least one field is required"}}
+
+	tests := []struct {
+		name string
+		err  error
+		want ErrorResponse
+	}{
+		{
+			name: "validation error",
+			err:  verr,
+			want: ErrorResponse{Code: http.StatusUnprocessableEntity, Message: "Validation failed", Errors: verr},
+		},
+		{
+			name: "wrapped validation error",
+			err:  fmt.Errorf("create user: %w", verr),
+			want: ErrorResponse{Code: http.StatusUnprocessableEntity, Message: "Validation failed", Errors: verr},
+		},
+		{
+			name: "other error",
+			err:  errors.New("body is not JSON"),
+			want: ErrorResponse{Code: http.StatusBadRequest, Message: "body is not JSON"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Invalid(tt.err)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Invalid = %+v, want %+v", got, tt.want)
+			}
+			if got.StatusCode() != tt.want.Code {
+				t.Errorf("StatusCode = %d, want %d", got.StatusCode(), tt.want.Code)
+			}
+		})
+	}
+}
+
+func TestValidationErrorError(t *testing.T) {
+	err := ValidationError{{"", "at least one field is required"}, {"email", "is required"}}
+	if got, want := err.Error(), "invalid request: at least one field is required; email: is required"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// checkValidation compares the fields err reports with want, nil meaning
+// err must be nil.
+func checkValidation(t *testing.T, err error, want ValidationError) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Errorf("Validate() = %v, want nil", err)
+		}
+		return
+	}
+
+	var got ValidationError
+	if !errors.As(err, &got) {
+		t.Fatalf("Validate() = %v, want a ValidationError", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("fields = %v, want %v", got, want)
+	}
+}
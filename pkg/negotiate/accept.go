@@ -0,0 +1,83 @@
+package negotiate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MediaRange is one element of an Accept header, such as text/* or
+// application/json;q=0.5.
+type MediaRange struct {
+	Type    string // "*" for */*
+	Subtype string // "*" for type/*
+	Q       float64
+}
+
+// ParseAccept returns the media ranges of an Accept header, most preferred
+// first: by q-value, then by specificity. Malformed elements are skipped and
+// an empty header accepts everything.
+func ParseAccept(header string) []MediaRange {
+	if strings.TrimSpace(header) == "" {
+		return []MediaRange{{Type: "*", Subtype: "*", Q: 1}}
+	}
+
+	var out []MediaRange
+	for _, part := range strings.Split(header, ",") {
+		mt, params, _ := strings.Cut(part, ";")
+		typ, sub, ok := strings.Cut(strings.ToLower(strings.TrimSpace(mt)), "/")
+		if !ok || typ == "" || sub == "" || (typ == "*" && sub != "*") {
+			continue
+		}
+
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, _ := strings.Cut(strings.TrimSpace(p), "=")
+			if strings.EqualFold(k, "q") {
+				f, err := strconv.ParseFloat(v, 64)
+				if err != nil || f < 0 || f > 1 {
+					f = 0
+				}
+				q = f
+			}
+		}
+
+		out = append(out, MediaRange{Type: typ, Subtype: sub, Q: q})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Q != out[j].Q {
+			return out[i].Q > out[j].Q
+		}
+		return out[i].specificity() > out[j].specificity()
+	})
+	return out
+}
+
+// Match reports whether the range covers mediaType, e.g. application/json.
+func (m MediaRange) Match(mediaType string) bool {
+	typ, sub, _ := strings.Cut(strings.ToLower(mediaType), "/")
+	return (m.Type == "*" || m.Type == typ) && (m.Subtype == "*" || m.Subtype == sub)
+}
+
+func (m MediaRange) specificity() int {
+	switch {
+	case m.Type == "*":
+		return 0
+	case m.Subtype == "*":
+		return 1
+	}
+	return 2
+}
+
+// quality is the q-value the ranges give mediaType: that of the most
+// specific range matching it, 0 when none does.
+func quality(ranges []MediaRange, mediaType string) float64 {
+	best, q := -1, 0.0
+	for _, m := range ranges {
+		if m.Match(mediaType) && m.specificity() > best {
+			best, q = m.specificity(), m.Q
+		}
+	}
+	return q
+}
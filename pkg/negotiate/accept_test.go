@@ -0,0 +1,101 @@
+package negotiate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAccept(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []MediaRange
+	}{
+		{
+			name:   "empty accepts everything",
+			header: "",
+			want:   []MediaRange{{"*", "*", 1}},
+		},
+		{
+			name:   "white space only",
+			header: " \t",
+			want:   []MediaRange{{"*", "*", 1}},
+		},
+		{
+			name:   "sorted by q-value",
+			header: "text/plain;q=0.5, application/json, application/xml;q=0.9",
+			want:   []MediaRange{{"application", "json", 1}, {"application", "xml", 0.9}, {"text", "plain", 0.5}},
+		},
+		{
+			name:   "ties broken by specificity",
+			header: "*/*, text/*, text/plain",
+			want:   []MediaRange{{"text", "plain", 1}, {"text", "*", 1}, {"*", "*", 1}},
+		},
+		{
+			name:   "equal ranges keep their order",
+			header: "application/xml, application/json",
+			want:   []MediaRange{{"application", "xml", 1}, {"application", "json", 1}},
+		},
+		{
+			name:   "case and white space",
+			header: " Application/JSON ; Q=0.8 ",
+			want:   []MediaRange{{"application", "json", 0.8}},
+		},
+		{
+			name:   "other parameters ignored",
+			header: "text/html;level=1;q=0.7",
+			want:   []MediaRange{{"text", "html", 0.7}},
+		},
+		{
+			name:   "malformed q is 0",
+			header: "application/json;q=high, application/xml;q=1.5, text/plain;q=-1, text/csv;q=",
+			want:   []MediaRange{{"application", "json", 0}, {"application", "xml", 0}, {"text", "plain", 0}, {"text", "csv", 0}},
+		},
+		{
+			name:   "malformed ranges skipped",
+			header: "json, /json, application/, */json, , text/plain",
+			want:   []MediaRange{{"text", "plain", 1}},
+		},
+		{
+			name:   "nothing usable",
+			header: "garbage",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseAccept(tt.header); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseAccept(%q)\ngot  %v\nwant %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuality(t *testing.T) {
+	tests := []struct {
+		accept    string
+		mediaType string
+		want      float64
+	}{
+		{"", "application/json", 1},
+		{"application/json", "application/json", 1},
+		{"application/json", "Application/JSON", 1},
+		{"application/json", "application/xml", 0},
+		{"application/*;q=0.4", "application/xml", 0.4},
+		{"*/*;q=0.1", "text/plain", 0.1},
+
+		// the most specific match wins, whatever its q-value
+		{"text/*;q=0.9, text/plain;q=0.2", "text/plain", 0.2},
+		{"*/*, application/json;q=0", "application/json", 0},
+		{"*/*, application/json;q=0", "application/xml", 1},
+		{"text/*;q=0, text/plain", "text/plain", 1},
+		{"text/*;q=0, text/plain", "text/html", 0},
+
+		{"application/json;q=oops", "application/json", 0},
+	}
+	for _, tt := range tests {
+		if got := quality(ParseAccept(tt.accept), tt.mediaType); got != tt.want {
+			t.Errorf("quality(%q, %s) = %v, want %v", tt.accept, tt.mediaType, got, tt.want)
+		}
+	}
+}
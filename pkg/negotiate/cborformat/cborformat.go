@@ -0,0 +1,19 @@
+// Package cborformat adds CBOR, RFC 8949, to a negotiate.Registry.
+package cborformat
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+)
+
+// Format encodes with github.com/fxamacker/cbor, which names fields after
+// their json tags when they have no cbor tag.
+var Format = negotiate.Format{
+	Type: "application/cbor",
+	Encode: func(w io.Writer, v any) error {
+		return cbor.NewEncoder(w).Encode(v)
+	},
+}
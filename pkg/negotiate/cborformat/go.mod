@@ -0,0 +1,12 @@
+module github.com/go-mizu/go-fw/pkg/negotiate/cborformat
+
+go 1.25
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.2
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+)
+
+require github.com/x448/float16 v0.8.4 // indirect
+
+replace github.com/go-mizu/go-fw => ../../..
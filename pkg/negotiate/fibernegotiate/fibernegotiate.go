@@ -0,0 +1,27 @@
+// Package fibernegotiate sends negotiated responses from Fiber handlers,
+// which have no http.ResponseWriter.
+package fibernegotiate
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Write sends env in the format the request accepts, like
+// negotiate.Registry.Write. A 406 or an encoding failure is returned as a
+// problem error for the app's ErrorHandler, such as
+// fiberproblem.ErrorHandler.
+func Write(c *fiber.Ctx, reg *negotiate.Registry, env models.Envelope) error {
+	c.Vary(fiber.HeaderAccept)
+
+	ct, body, err := reg.Render(c.Get(fiber.HeaderAccept), env)
+	if err != nil {
+		return problem.From(err)
+	}
+
+	c.Set(fiber.HeaderContentType, ct)
+	return c.Status(env.StatusCode()).Send(body)
+}
@@ -0,0 +1,31 @@
+package negotiate
+
+import (
+	"net/http"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Write sends env with Default.
+func Write(w http.ResponseWriter, r *http.Request, env models.Envelope) {
+	Default.Write(w, r, env)
+}
+
+// Write sends env in the format the request accepts, with the status the
+// envelope carries. The body is encoded before anything is written, so a
+// 406 or an encoding failure is still sent as a problem. Vary: Accept tells
+// caches the response depends on the header.
+func (reg *Registry) Write(w http.ResponseWriter, r *http.Request, env models.Envelope) {
+	w.Header().Add("Vary", "Accept")
+
+	ct, body, err := reg.Render(r.Header.Get("Accept"), env)
+	if err != nil {
+		problem.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.WriteHeader(env.StatusCode())
+	w.Write(body)
+}
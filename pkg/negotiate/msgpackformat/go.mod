@@ -0,0 +1,12 @@
+module github.com/go-mizu/go-fw/pkg/negotiate/msgpackformat
+
+go 1.25
+
+require (
+	github.com/go-mizu/go-fw v0.0.0-00010101000000-000000000000
+	github.com/vmihailenco/msgpack/v5 v5.4.1
+)
+
+require github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+
+replace github.com/go-mizu/go-fw => ../../..
@@ -0,0 +1,21 @@
+// Package msgpackformat adds MessagePack to a negotiate.Registry.
+package msgpackformat
+
+import (
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/go-mizu/go-fw/pkg/negotiate"
+)
+
+// Format encodes with github.com/vmihailenco/msgpack, naming fields after
+// their json tags so the keys match the JSON output.
+var Format = negotiate.Format{
+	Type: "application/msgpack",
+	Encode: func(w io.Writer, v any) error {
+		enc := msgpack.NewEncoder(w)
+		enc.SetCustomStructTag("json")
+		return enc.Encode(v)
+	},
+}
@@ -0,0 +1,154 @@
+// Package negotiate picks the response format from the Accept header. A
+// Registry holds the formats the server offers, in order of preference;
+// JSON, XML and plain text are built in, and the msgpackformat and
+// cborformat modules next to this package add MessagePack and CBOR.
+// Requests nothing satisfies get a 406 problem from pkg/problem.
+package negotiate
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Format is a media type the server can answer with.
+type Format struct {
+	Type        string // media type matched against Accept
+	ContentType string // header value, Type when empty
+	Encode      func(w io.Writer, v any) error
+}
+
+func (f Format) contentType() string {
+	if f.ContentType != "" {
+		return f.ContentType
+	}
+	return f.Type
+}
+
+// JSON encodes with encoding/json.
+var JSON = Format{
+	Type:        "application/json",
+	ContentType: "application/json; charset=utf-8",
+	Encode: func(w io.Writer, v any) error {
+		return json.NewEncoder(w).Encode(v)
+	},
+}
+
+// XML encodes with encoding/xml inside a <response> element, since the
+// generic envelope types have no usable XML name of their own.
+var XML = Format{
+	Type:        "application/xml",
+	ContentType: "application/xml; charset=utf-8",
+	Encode: func(w io.Writer, v any) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		if err := xml.NewEncoder(w).EncodeElement(v, xml.StartElement{Name: xml.Name{Local: "response"}}); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "\n")
+		return err
+	},
+}
+
+// Text writes values that implement encoding.TextMarshaler or fmt.Stringer
+// as such, and anything else with the %+v verb.
+var Text = Format{
+	Type:        "text/plain",
+	ContentType: "text/plain; charset=utf-8",
+	Encode: func(w io.Writer, v any) error {
+		switch v := v.(type) {
+		case encoding.TextMarshaler:
+			b, err := v.MarshalText()
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", b)
+			return err
+		case fmt.Stringer:
+			_, err := fmt.Fprintln(w, v.String())
+			return err
+		}
+		_, err := fmt.Fprintf(w, "%+v\n", v)
+		return err
+	},
+}
+
+// Registry is an ordered set of formats. The first one answers requests
+// without an Accept header, and wins ties.
+type Registry struct {
+	formats []Format
+}
+
+// New returns a registry offering formats in the given order.
+func New(formats ...Format) *Registry {
+	reg := &Registry{}
+	for _, f := range formats {
+		reg.Register(f)
+	}
+	return reg
+}
+
+// Default offers JSON, XML and plain text.
+var Default = New(JSON, XML, Text)
+
+// Register adds f last, or replaces the format with the same Type in place.
+func (reg *Registry) Register(f Format) {
+	for i, have := range reg.formats {
+		if have.Type == f.Type {
+			reg.formats[i] = f
+			return
+		}
+	}
+	reg.formats = append(reg.formats, f)
+}
+
+// Types lists the media types offered.
+func (reg *Registry) Types() []string {
+	types := make([]string, len(reg.formats))
+	for i, f := range reg.formats {
+		types[i] = f.Type
+	}
+	return types
+}
+
+// Negotiate returns the format with the highest q-value under accept,
+// false when every format has q=0.
+func (reg *Registry) Negotiate(accept string) (Format, bool) {
+	ranges := ParseAccept(accept)
+
+	var (
+		best  Format
+		bestQ float64
+	)
+	for _, f := range reg.formats {
+		if q := quality(ranges, f.Type); q > bestQ {
+			best, bestQ = f, q
+		}
+	}
+	return best, bestQ > 0
+}
+
+// Render encodes v in the format accept prefers and returns the
+// Content-Type and the body. It fails with a 406 problem listing the
+// offered types under "available" when nothing is acceptable, and with the
+// encoder's error otherwise.
+func (reg *Registry) Render(accept string, v any) (string, []byte, error) {
+	f, ok := reg.Negotiate(accept)
+	if !ok {
+		return "", nil, problem.New(http.StatusNotAcceptable, "none of the available media types is acceptable").
+			With("available", reg.Types())
+	}
+
+	var buf bytes.Buffer
+	if err := f.Encode(&buf, v); err != nil {
+		return "", nil, err
+	}
+	return f.contentType(), buf.Bytes(), nil
+}
@@ -0,0 +1,65 @@
+package negotiate
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		want   string // "" when nothing is acceptable
+	}{
+		{name: "no Accept is the first format", accept: "", want: "application/json"},
+		{name: "any", accept: "*/*", want: "application/json"},
+		{name: "exact", accept: "application/xml", want: "application/xml"},
+		{name: "type wildcard", accept: "text/*", want: "text/plain"},
+		{name: "highest q-value", accept: "application/json;q=0.5, text/plain;q=0.8", want: "text/plain"},
+		{name: "tie goes to the registry order", accept: "text/plain, application/xml", want: "application/xml"},
+		{name: "wildcard tie goes to the registry order", accept: "application/*", want: "application/json"},
+		{name: "q=0 excludes", accept: "*/*, application/json;q=0", want: "application/xml"},
+		{name: "q=0 for everything", accept: "*/*;q=0", want: ""},
+		{name: "specific range beats the excluding wildcard", accept: "*/*;q=0, text/plain;q=0.1", want: "text/plain"},
+		{name: "malformed q excludes", accept: "application/json;q=x, application/xml;q=2", want: ""},
+		{name: "unknown type", accept: "image/png", want: ""},
+		{name: "only malformed ranges", accept: "garbage", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := Default.Negotiate(tt.accept)
+			if ok != (tt.want != "") || f.Type != tt.want {
+				t.Errorf("Negotiate(%q) = %q, %v, want %q", tt.accept, f.Type, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	reg := New(JSON, XML)
+	reg.Register(Text)
+	reg.Register(Format{Type: "application/xml", Encode: XML.Encode})
+
+	if got, want := reg.Types(), []string{"application/json", "application/xml", "text/plain"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Types() = %v, want %v", got, want)
+	}
+	if f, _ := reg.Negotiate("application/xml"); f.contentType() != "application/xml" {
+		t.Errorf("content type = %q, want the replacement's", f.contentType())
+	}
+}
+
+func TestRenderNotAcceptable(t *testing.T) {
+	_, _, err := New(JSON, XML).Render("text/html", map[string]int{"id": 1})
+
+	var p *problem.Details
+	if !errors.As(err, &p) || p.Status != http.StatusNotAcceptable {
+		t.Fatalf("Render error = %v, want a 406 problem", err)
+	}
+	if got, want := p.Extensions["available"], []string{"application/json", "application/xml"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("available = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,107 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// ErrNoSecret is returned when cursors are encoded or decoded without a
+// Config.Secret.
+var ErrNoSecret = errors.New("pagination: cursor secret not configured")
+
+// Cursor is a page in cursor mode. Token is empty on the first page.
+type Cursor struct {
+	Token string
+	Limit int
+}
+
+// Cursor reads cursor and limit from u. The token is checked with Decode.
+func (c Config) Cursor(u *url.URL) (Cursor, error) {
+	q := u.Query()
+
+	limit, err := c.size(q, LimitParam)
+	if err != nil {
+		return Cursor{}, err
+	}
+
+	return Cursor{Token: q.Get(CursorParam), Limit: limit}, nil
+}
+
+// Encode turns key, the position after the last item sent, into an opaque
+// token: its JSON form and an HMAC-SHA256 of it, base64url encoded, so
+// clients cannot forge or edit positions.
+func (c Config) Encode(key any) (string, error) {
+	if len(c.Secret) == 0 {
+		return "", ErrNoSecret
+	}
+
+	payload, err := json.Marshal(key)
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode verifies token and stores its key in the value v points to. A
+// token that is malformed or not signed with Secret is a 400 problem.
+func (c Config) Decode(token string, v any) error {
+	if len(c.Secret) == 0 {
+		return ErrNoSecret
+	}
+
+	bad := invalid(CursorParam, "cursor is invalid")
+
+	p, s, ok := strings.Cut(token, ".")
+	if !ok {
+		return bad
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(p)
+	if err != nil {
+		return bad
+	}
+	sig, err := enc.DecodeString(s)
+	if err != nil || !hmac.Equal(sig, c.sign(payload)) {
+		return bad
+	}
+
+	if err := json.Unmarshal(payload, v); err != nil {
+		return bad
+	}
+	return nil
+}
+
+func (c Config) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.Secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Links returns the first link and, for non-empty tokens, the next and
+// prev links, built from u with cursor and limit replaced. Cursor mode
+// has no last link.
+func (cur Cursor) Links(u *url.URL, next, prev string) Links {
+	at := func(token string) string {
+		return with(u, map[string]string{
+			CursorParam: token,
+			LimitParam:  strconv.Itoa(cur.Limit),
+		})
+	}
+
+	l := Links{First: at("")}
+	if next != "" {
+		l.Next = at(next)
+	}
+	if prev != "" {
+		l.Prev = at(prev)
+	}
+	return l
+}
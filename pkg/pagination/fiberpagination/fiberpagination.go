@@ -0,0 +1,37 @@
+// Package fiberpagination reads pagination parameters and sets Link
+// headers from Fiber handlers, which have no *url.URL or http.Header.
+package fiberpagination
+
+import (
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+// URL returns the path and query of the request.
+func URL(c *fiber.Ctx) *url.URL {
+	u, err := url.ParseRequestURI(c.OriginalURL())
+	if err != nil {
+		return &url.URL{Path: c.Path()}
+	}
+	return u
+}
+
+// Offset reads page and page_size with cfg.
+func Offset(c *fiber.Ctx, cfg pagination.Config) (pagination.Offset, error) {
+	return cfg.Offset(URL(c))
+}
+
+// Cursor reads cursor and limit with cfg.
+func Cursor(c *fiber.Ctx, cfg pagination.Config) (pagination.Cursor, error) {
+	return cfg.Cursor(URL(c))
+}
+
+// SetLinks sets the Link header of the response, unless l is empty.
+func SetLinks(c *fiber.Ctx, l pagination.Links) {
+	if v := l.String(); v != "" {
+		c.Set(fiber.HeaderLink, v)
+	}
+}
@@ -0,0 +1,41 @@
+package pagination
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Links are the RFC 8288 navigation links of a page. Empty ones are left
+// out of the header.
+type Links struct {
+	First string
+	Prev  string
+	Next  string
+	Last  string
+}
+
+// String formats l as a Link header value, e.g.
+// </users?page=2&page_size=20>; rel="next".
+func (l Links) String() string {
+	var parts []string
+	for _, link := range []struct{ rel, uri string }{
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+		{"last", l.Last},
+	} {
+		if link.uri != "" {
+			parts = append(parts, "<"+link.uri+`>; rel="`+link.rel+`"`)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Set sets the Link header of h, or removes it when l is empty.
+func (l Links) Set(h http.Header) {
+	if v := l.String(); v != "" {
+		h.Set("Link", v)
+	} else {
+		h.Del("Link")
+	}
+}
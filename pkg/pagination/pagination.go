@@ -0,0 +1,169 @@
+// Package pagination reads page and cursor parameters from a request URL,
+// computes the pkg/models pagination block, signs opaque cursors and builds
+// RFC 8288 Link headers. It works on *url.URL and http.Header, which Gin,
+// Echo, Chi and Mizu expose directly; fiberpagination adapts Fiber.
+//
+// Offset mode uses ?page=N&page_size=N, cursor mode ?cursor=T&limit=N.
+// Invalid parameters are reported as 400 problems from pkg/problem.
+package pagination
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Query parameter names.
+const (
+	PageParam     = "page"
+	PageSizeParam = "page_size"
+	CursorParam   = "cursor"
+	LimitParam    = "limit"
+)
+
+// Config holds the size limits and the cursor signing key.
+type Config struct {
+	DefaultSize int    // size when the request names none
+	MaxSize     int    // larger requested sizes are clamped to it
+	Secret      []byte // HMAC key for cursors; cursor mode needs it
+}
+
+// Default serves 20 items per page and at most 100.
+var Default = Config{DefaultSize: 20, MaxSize: 100}
+
+// Offset is a page in offset mode. Page counts from 1.
+type Offset struct {
+	Page int
+	Size int
+}
+
+// Offset reads page and page_size from u.
+func (c Config) Offset(u *url.URL) (Offset, error) {
+	q := u.Query()
+
+	page, err := positive(q, PageParam, 1)
+	if err != nil {
+		return Offset{}, err
+	}
+	size, err := c.size(q, PageSizeParam)
+	if err != nil {
+		return Offset{}, err
+	}
+	// past this, the items before the page overflow an int
+	if page-1 > math.MaxInt/size {
+		return Offset{}, invalid(PageParam, PageParam+" is too large")
+	}
+
+	return Offset{Page: page, Size: size}, nil
+}
+
+// Skip is the number of items before the page.
+func (o Offset) Skip() int {
+	return (o.Page - 1) * o.Size
+}
+
+// Pagination returns the pagination block for total items.
+func (o Offset) Pagination(total int) models.Pagination {
+	return models.Pagination{Page: o.Page, PageSize: o.Size, Total: total, TotalPage: TotalPages(total, o.Size)}
+}
+
+// Links returns the first, prev, next and last links of the page, built
+// from u with page and page_size replaced. Prev and next are left out at
+// the ends.
+func (o Offset) Links(u *url.URL, total int) Links {
+	last := max(TotalPages(total, o.Size), 1)
+	at := func(page int) string {
+		return with(u, map[string]string{
+			PageParam:     strconv.Itoa(page),
+			PageSizeParam: strconv.Itoa(o.Size),
+		})
+	}
+
+	l := Links{First: at(1), Last: at(last)}
+	if o.Page > 1 {
+		l.Prev = at(min(o.Page-1, last))
+	}
+	if o.Page < last {
+		l.Next = at(o.Page + 1)
+	}
+	return l
+}
+
+// TotalPages is the number of pages of size holding total items.
+func TotalPages(total, size int) int {
+	if size <= 0 || total <= 0 {
+		return 0
+	}
+	return (total + size - 1) / size
+}
+
+// Slice returns the items of page o out of an in-memory list, none when
+// the page is past the end or not a valid page, such as page 0.
+func Slice[T any](items []T, o Offset) []T {
+	// compared before multiplying, as Skip may overflow
+	if o.Page < 1 || o.Size < 1 || o.Page-1 > len(items)/o.Size {
+		return items[:0]
+	}
+	start := min(o.Skip(), len(items))
+	end := min(start+o.Size, len(items))
+	return items[start:end]
+}
+
+// Paginated wraps one page of items in a models.PageResponse.
+func Paginated[T any](items []T, o Offset, total int) models.PageResponse[T] {
+	return models.Paginated(items, o.Page, o.Size, total)
+}
+
+// size reads a page size, clamped to MaxSize.
+func (c Config) size(q url.Values, name string) (int, error) {
+	def := c.DefaultSize
+	if def <= 0 {
+		def = Default.DefaultSize
+	}
+
+	n, err := positive(q, name, def)
+	if err != nil {
+		return 0, err
+	}
+	if c.MaxSize > 0 {
+		n = min(n, c.MaxSize)
+	}
+	return n, nil
+}
+
+// positive reads a positive integer parameter, def when it is absent.
+func positive(q url.Values, name string, def int) (int, error) {
+	s := q.Get(name)
+	if s == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, invalid(name, name+" must be a positive integer")
+	}
+	return n, nil
+}
+
+func invalid(param, detail string) error {
+	return problem.New(http.StatusBadRequest, detail).With("param", param)
+}
+
+// with returns u as a path and query with the given parameters set.
+func with(u *url.URL, set map[string]string) string {
+	q := u.Query()
+	for k, v := range set {
+		if v == "" {
+			q.Del(k)
+		} else {
+			q.Set(k, v)
+		}
+	}
+
+	ref := url.URL{Path: u.Path, RawQuery: q.Encode()}
+	return ref.String()
+}
@@ -0,0 +1,79 @@
+package pagination
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+func TestOffset(t *testing.T) {
+	items := make([]int, 45)
+	for i := range items {
+		items[i] = i
+	}
+	huge := strconv.Itoa(math.MaxInt/20 + 2)
+
+	tests := []struct {
+		query  string
+		status int // 0 when the page is valid
+		skip   int
+		slice  []int
+	}{
+		{query: "", skip: 0, slice: items[0:20]},
+		{query: "page=1", skip: 0, slice: items[0:20]},
+		{query: "page=3", skip: 40, slice: items[40:45]},
+		{query: "page=4", skip: 60, slice: []int{}},
+		{query: "page=2&page_size=500", skip: 100, slice: []int{}},
+		{query: "page=0", status: http.StatusBadRequest},
+		{query: "page=-2", status: http.StatusBadRequest},
+		{query: "page=abc", status: http.StatusBadRequest},
+		{query: "page=922337203685477581&page_size=20", status: http.StatusBadRequest},
+		{query: "page=" + huge, status: http.StatusBadRequest},
+		{query: "page=99999999999999999999", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			o, err := Default.Offset(&url.URL{Path: "/users", RawQuery: tt.query})
+			if tt.status != 0 {
+				var p *problem.Details
+				if !errors.As(err, &p) || p.Status != tt.status {
+					t.Fatalf("Offset = %+v, %v, want a %d problem", o, err, tt.status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := o.Skip(); got != tt.skip {
+				t.Errorf("Skip = %d, want %d", got, tt.skip)
+			}
+			if got := Slice(items, o); !slices.Equal(got, tt.slice) {
+				t.Errorf("Slice = %v, want %v", got, tt.slice)
+			}
+		})
+	}
+}
+
+// Offsets built by hand skip the checks of Config.Offset.
+func TestSliceInvalidPage(t *testing.T) {
+	items := []int{1, 2, 3}
+
+	for _, o := range []Offset{
+		{Page: 0, Size: 20},
+		{Page: -1, Size: 20},
+		{Page: math.MinInt, Size: 20},
+		{Page: 922337203685477581, Size: 20},
+		{Page: math.MaxInt, Size: math.MaxInt},
+		{Page: 1, Size: 0},
+	} {
+		if got := Slice(items, o); len(got) != 0 {
+			t.Errorf("Slice(%+v) = %v, want an empty page", o, got)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package echoproblem returns problem details from Echo handlers.
+package echoproblem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// ErrorHandler is an echo.HTTPErrorHandler writing every error as a
+// problem; *echo.HTTPError keeps its status and message. Install it with
+// e.HTTPErrorHandler = echoproblem.ErrorHandler.
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	problem.Write(c.Response(), c.Request(), convert(err))
+}
+
+// Recover turns a panic in the handlers after it into a 500 problem, which
+// it returns to ErrorHandler. Install it in place of middleware.Recover. A
+// panic after the response is committed is re-panicked, so net/http drops
+// the connection instead of ending the partial response as if it were
+// complete.
+func Recover() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler || c.Response().Committed {
+					panic(v)
+				}
+				err = problem.New(http.StatusInternalServerError, "")
+			}()
+			return next(c)
+		}
+	}
+}
+
+func convert(err error) error {
+	var p *problem.Details
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		cause := he.Internal
+		if cause == nil {
+			cause = err
+		}
+		return problem.FromStatus(he.Code, fmt.Sprint(he.Message), cause)
+	}
+
+	return err
+}
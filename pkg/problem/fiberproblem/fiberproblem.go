@@ -0,0 +1,39 @@
+// Package fiberproblem returns problem details from Fiber handlers.
+package fiberproblem
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// ErrorHandler is a fiber.ErrorHandler writing every error as a problem;
+// *fiber.Error keeps its status and message. Install it with
+// fiber.Config{ErrorHandler: fiberproblem.ErrorHandler}, and add the
+// recover middleware so panics reach it.
+//
+// Fiber sends nothing until the handler returns, so no response is
+// committed when ErrorHandler runs, and a panic after a partial write
+// still becomes a 500 problem, replacing what the handler wrote.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	p := problem.Resolve(convert(err), c.Path())
+
+	c.Set(fiber.HeaderContentType, problem.ContentType)
+	return c.Status(p.StatusCode()).Send(problem.Body(p))
+}
+
+func convert(err error) error {
+	var p *problem.Details
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var fe *fiber.Error
+	if errors.As(err, &fe) {
+		return problem.FromStatus(fe.Code, fe.Message, err)
+	}
+
+	return err
+}
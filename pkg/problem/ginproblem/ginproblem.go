@@ -0,0 +1,44 @@
+// Package ginproblem returns problem details from Gin handlers.
+package ginproblem
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Middleware writes a problem for the last error a handler attached with
+// c.Error, when the handler wrote nothing, and turns panics into a 500
+// problem. Install it first, in place of gin.Recovery.
+//
+// A panic after the handler wrote is re-panicked, so net/http drops the
+// connection instead of ending the partial response as if it were
+// complete.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler || c.Writer.Written() {
+				panic(v)
+			}
+			Abort(c, problem.New(http.StatusInternalServerError, ""))
+		}()
+
+		c.Next()
+
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			Abort(c, c.Errors.Last().Err)
+		}
+	}
+}
+
+// Abort writes the problem for err and stops the handler chain.
+func Abort(c *gin.Context, err error) {
+	problem.Write(c.Writer, c.Request, err)
+	c.Abort()
+}
@@ -0,0 +1,108 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Resolve returns the problem for err with instance filled in when it has
+// none. The problem err holds is not modified.
+func Resolve(err error, instance string) *Details {
+	p := From(err)
+	if p.Instance == "" {
+		c := *p
+		c.Instance = instance
+		p = &c
+	}
+	return p
+}
+
+// Write sends the problem for err. Instance defaults to the request path.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	p := Resolve(err, r.URL.Path)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(p.StatusCode())
+	w.Write(Body(p))
+}
+
+// Body returns the JSON body of p, newline included, for adapters that
+// send it themselves. When an extension cannot be encoded the standard
+// members are sent without the extensions, so the client still gets a
+// problem.
+func Body(p *Details) []byte {
+	body, err := json.Marshal(p)
+	if err != nil {
+		body, _ = json.Marshal(&Details{Type: p.Type, Title: p.Title, Status: p.Status, Detail: p.Detail, Instance: p.Instance})
+	}
+	return append(body, '\n')
+}
+
+// HandlerFunc is a net/http handler that returns its error instead of
+// writing it. Chi routes take it as well, since it is an http.Handler.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP runs f and writes a problem when it fails before writing a
+// response.
+func (f HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	cw := &CommitWriter{ResponseWriter: w}
+	if err := f(cw, r); err != nil && !cw.Committed() {
+		Write(w, r, err)
+	}
+}
+
+// Recover turns a panic in next into a 500 problem. Once the response is
+// committed nothing can replace it, so the panic is re-panicked and
+// net/http drops the connection instead of ending the partial response as
+// if it were complete. http.ErrAbortHandler is always re-panicked, as
+// net/http uses it to abort a response on purpose.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &CommitWriter{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler || cw.Committed() {
+				panic(v)
+			}
+			Write(w, r, New(http.StatusInternalServerError, ""))
+		}()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// CommitWriter records whether the response is committed, that is whether
+// its final status or any of its body has been sent, after which a problem
+// can no longer replace it. HandlerFunc and Recover wrap the writer in one,
+// and so do the adapters of frameworks built on an http.ResponseWriter.
+type CommitWriter struct {
+	http.ResponseWriter
+	committed bool
+}
+
+// Committed reports whether the response is committed.
+func (w *CommitWriter) Committed() bool {
+	return w.committed
+}
+
+func (w *CommitWriter) WriteHeader(code int) {
+	// 1xx informational responses leave the final status open
+	if code >= 200 {
+		w.committed = true
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *CommitWriter) Write(b []byte) (int, error) {
+	w.committed = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the flusher and hijacker of the
+// underlying writer.
+func (w *CommitWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,180 @@
+package problem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCommitWriter(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter)
+		want  bool
+	}{
+		{name: "nothing", write: func(w http.ResponseWriter) {}},
+		{name: "header only", write: func(w http.ResponseWriter) { w.Header().Set("X-A", "1") }},
+		{name: "103 Early Hints", write: func(w http.ResponseWriter) { w.WriteHeader(http.StatusEarlyHints) }},
+		{name: "status", write: func(w http.ResponseWriter) { w.WriteHeader(http.StatusCreated) }, want: true},
+		{name: "body", write: func(w http.ResponseWriter) { w.Write([]byte("x")) }, want: true},
+		{
+			name: "1xx then status",
+			write: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusEarlyHints)
+				w.WriteHeader(http.StatusOK)
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cw := &CommitWriter{ResponseWriter: httptest.NewRecorder()}
+			tt.write(cw)
+			if cw.Committed() != tt.want {
+				t.Errorf("Committed = %v, want %v", cw.Committed(), tt.want)
+			}
+		})
+	}
+}
+
+func TestHandlerFunc(t *testing.T) {
+	fail := New(http.StatusConflict, "taken")
+
+	tests := []struct {
+		name   string
+		h      HandlerFunc
+		status int
+		ctype  string
+	}{
+		{
+			name:   "error",
+			h:      func(w http.ResponseWriter, r *http.Request) error { return fail },
+			status: http.StatusConflict,
+			ctype:  ContentType,
+		},
+		{
+			name: "error after 1xx",
+			h: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusEarlyHints)
+				return fail
+			},
+			status: http.StatusConflict,
+			ctype:  ContentType,
+		},
+		{
+			name: "error after a response",
+			h: func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(http.StatusAccepted)
+				return fail
+			},
+			status: http.StatusAccepted,
+			ctype:  "text/plain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := newRecorder()
+			tt.h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users", nil))
+			if rec.Code != tt.status || rec.Header().Get("Content-Type") != tt.ctype {
+				t.Errorf("response = %d %q, want %d %q", rec.Code, rec.Header().Get("Content-Type"), tt.status, tt.ctype)
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	t.Run("before the response", func(t *testing.T) {
+		rec := serve(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusEarlyHints)
+			panic("boom")
+		})
+		if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Type") != ContentType {
+			t.Errorf("response = %d %q, want a 500 problem", rec.Code, rec.Header().Get("Content-Type"))
+		}
+	})
+
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+	}{
+		{
+			name: "after the response",
+			h: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte("partial"))
+				panic("boom")
+			},
+		},
+		{
+			name: "abort",
+			h:    func(w http.ResponseWriter, r *http.Request) { panic(http.ErrAbortHandler) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("the panic was recovered, want it re-panicked")
+				}
+			}()
+			serve(t, tt.h)
+		})
+	}
+}
+
+func serve(t *testing.T, h http.HandlerFunc) *recorder {
+	t.Helper()
+	rec := newRecorder()
+	Recover(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestWrite(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set("Content-Length", "3")
+	Write(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil), fmt.Errorf("lookup: %w", New(http.StatusNotFound, "no user 7")))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Length"); got != "" {
+		t.Errorf("Content-Length = %q, want none", got)
+	}
+	want := `{"type":"about:blank","title":"Not Found","status":404,"detail":"no user 7","instance":"/users/7"}` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("body = %s, want %s", rec.Body, want)
+	}
+}
+
+// An extension that cannot be encoded leaves the standard members, which
+// adapters sending Body themselves get as well.
+func TestBodyUnsupportedExtension(t *testing.T) {
+	p := New(http.StatusBadRequest, "bad").With("ch", make(chan int)).WithInstance("/x")
+
+	body := Body(p)
+	want := `{"type":"about:blank","title":"Bad Request","status":400,"detail":"bad","instance":"/x"}` + "\n"
+	if string(body) != want {
+		t.Errorf("body = %s, want %s", body, want)
+	}
+	if !json.Valid(body) {
+		t.Errorf("%s is not valid JSON", body)
+	}
+}
+
+// recorder is an httptest.ResponseRecorder that passes over 1xx
+// responses, which the plain one records as the final status.
+type recorder struct {
+	*httptest.ResponseRecorder
+}
+
+func newRecorder() *recorder {
+	return &recorder{httptest.NewRecorder()}
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if code >= 200 {
+		r.ResponseRecorder.WriteHeader(code)
+	}
+}
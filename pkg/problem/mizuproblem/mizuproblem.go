@@ -0,0 +1,86 @@
+// Package mizuproblem returns problem details from Mizu handlers.
+package mizuproblem
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-mizu/mizu"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Middleware writes the errors returned by the handlers after it, and
+// panics, as problems; mizu.HTTPError keeps its status and message.
+// Install it first with app.Use(mizuproblem.Middleware()), and serve the
+// app through Handler, which tells it whether the response is committed.
+//
+// Nothing is written once it is: an error is logged, and a panic is
+// re-panicked so net/http drops the connection instead of ending the
+// partial response as if it were complete.
+func Middleware() mizu.Middleware {
+	return func(next mizu.Handler) mizu.Handler {
+		return func(c *mizu.Ctx) (err error) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler || committed(c) {
+					panic(v)
+				}
+				problem.Write(c.Writer(), c.Request(), problem.New(http.StatusInternalServerError, ""))
+				err = nil
+			}()
+
+			if err := next(c); err != nil {
+				if committed(c) {
+					log.Printf("mizuproblem: %s %s: %v, after the response was committed", c.Request().Method, c.Request().URL.Path, err)
+					return nil
+				}
+				problem.Write(c.Writer(), c.Request(), convert(err))
+			}
+			return nil
+		}
+	}
+}
+
+// Handler serves app, a Mizu app, recording whether each response has been
+// committed, for Middleware:
+//
+//	http.ListenAndServe(":8080", mizuproblem.Handler(app))
+//
+// Without it, Middleware cannot tell, and writes every problem.
+func Handler(app http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cw := &problem.CommitWriter{ResponseWriter: w}
+		app.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), commitKey{}, cw)))
+	})
+}
+
+type commitKey struct{}
+
+func committed(c *mizu.Ctx) bool {
+	cw, ok := c.Request().Context().Value(commitKey{}).(*problem.CommitWriter)
+	return ok && cw.Committed()
+}
+
+func convert(err error) error {
+	var p *problem.Details
+	if errors.As(err, &p) {
+		return p
+	}
+
+	var he mizu.HTTPError
+	if errors.As(err, &he) {
+		msg := ""
+		if he.Err != nil {
+			msg = he.Err.Error()
+		}
+		return problem.FromStatus(he.Status, msg, err)
+	}
+
+	return err
+}
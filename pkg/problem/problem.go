@@ -0,0 +1,228 @@
+// Package problem implements RFC 9457 problem details, the
+// application/problem+json error body every example service returns. The
+// net/http integration is here; Gin, Echo, Fiber and Mizu have adapter
+// modules next to this package that map their native errors onto Details.
+package problem
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+// ContentType is the media type of a problem details body.
+const ContentType = "application/problem+json"
+
+// DefaultType is the type of a problem that has no more specific URI; its
+// title is then the HTTP status text.
+const DefaultType = "about:blank"
+
+// Details is a problem details object. It is an error, so handlers can
+// return it or wrap it, and Unwrap gives the cause, which is never sent.
+type Details struct {
+	Type     string // URI reference identifying the problem type
+	Title    string // short summary of the problem type
+	Status   int    // HTTP status code
+	Detail   string // explanation specific to this occurrence
+	Instance string // URI reference identifying this occurrence
+
+	// Extensions are extra members of the object, such as a list of
+	// invalid fields.
+	Extensions map[string]any
+
+	err error
+}
+
+// New returns a problem with the given status and detail, titled with the
+// status text.
+func New(status int, detail string) *Details {
+	return &Details{Type: DefaultType, Title: http.StatusText(status), Status: status, Detail: detail}
+}
+
+// Wrap returns a problem with the given status whose detail is err's
+// message and whose cause is err.
+func Wrap(status int, err error) *Details {
+	p := New(status, err.Error())
+	p.err = err
+	return p
+}
+
+// From turns any error into a problem. A *Details found with errors.As is
+// returned as is and a models.ValidationError becomes a 422 listing the
+// fields under "errors"; anything else becomes a 500 without detail, so
+// internal messages do not leak to clients.
+func From(err error) *Details {
+	var p *Details
+	if errors.As(err, &p) {
+		return p
+	}
+	var verr models.ValidationError
+	if errors.As(err, &verr) {
+		p = New(http.StatusUnprocessableEntity, "The request has invalid fields.").With("errors", []models.FieldError(verr))
+		p.err = err
+		return p
+	}
+	p = New(http.StatusInternalServerError, "")
+	p.err = err
+	return p
+}
+
+// FromStatus returns a problem for a framework error that carries a status
+// and a message, such as *echo.HTTPError. A message that only repeats the
+// status text is left out of the detail.
+func FromStatus(status int, message string, cause error) *Details {
+	if message == http.StatusText(status) {
+		message = ""
+	}
+	p := New(status, message)
+	p.err = cause
+	return p
+}
+
+// WithType sets the type URI and title of p.
+func (p *Details) WithType(uri, title string) *Details {
+	p.Type = uri
+	p.Title = title
+	return p
+}
+
+// WithInstance sets the URI of this occurrence.
+func (p *Details) WithInstance(uri string) *Details {
+	p.Instance = uri
+	return p
+}
+
+// With sets an extension member. The standard members cannot be replaced.
+func (p *Details) With(key string, value any) *Details {
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Details) Error() string {
+	msg := fmt.Sprintf("%d %s", p.Status, p.Title)
+	if p.Detail != "" {
+		msg += ": " + p.Detail
+	}
+	return msg
+}
+
+func (p *Details) Unwrap() error {
+	return p.err
+}
+
+// StatusCode is the HTTP status to send p with: Status when it is valid,
+// 500 otherwise.
+func (p *Details) StatusCode() int {
+	if p.Status < 100 || p.Status > 599 {
+		return http.StatusInternalServerError
+	}
+	return p.Status
+}
+
+// members are the standard members, which extensions never override.
+var members = map[string]bool{"type": true, "title": true, "status": true, "detail": true, "instance": true}
+
+// MarshalJSON writes the standard members in RFC order, leaving out empty
+// ones, then the extensions sorted by name. An empty type is written as
+// about:blank.
+func (p *Details) MarshalJSON() ([]byte, error) {
+	typ := p.Type
+	if typ == "" {
+		typ = DefaultType
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	write := func(key string, value any) error {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(data)
+		return nil
+	}
+
+	write("type", typ)
+	if p.Title != "" {
+		write("title", p.Title)
+	}
+	if p.Status != 0 {
+		write("status", p.Status)
+	}
+	if p.Detail != "" {
+		write("detail", p.Detail)
+	}
+	if p.Instance != "" {
+		write("instance", p.Instance)
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for k := range p.Extensions {
+		if !members[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := write(k, p.Extensions[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON reads the standard members and keeps every other member as
+// an extension. Members of the wrong type are ignored, as RFC 9457 asks.
+func (p *Details) UnmarshalJSON(data []byte) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*p = Details{}
+	for k, raw := range obj {
+		// a standard member of the wrong type is left at its zero value
+		switch k {
+		case "type":
+			_ = json.Unmarshal(raw, &p.Type)
+		case "title":
+			_ = json.Unmarshal(raw, &p.Title)
+		case "status":
+			_ = json.Unmarshal(raw, &p.Status)
+		case "detail":
+			_ = json.Unmarshal(raw, &p.Detail)
+		case "instance":
+			_ = json.Unmarshal(raw, &p.Instance)
+		default:
+			var v any
+			if err := json.Unmarshal(raw, &v); err != nil {
+				return err
+			}
+			p.With(k, v)
+		}
+	}
+	if p.Type == "" {
+		p.Type = DefaultType
+	}
+
+	return nil
+}
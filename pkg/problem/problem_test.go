@@ -0,0 +1,198 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *Details
+		want string
+	}{
+		{
+			name: "standard members in order",
+			p:    New(http.StatusNotFound, "no user 7").WithInstance("/users/7"),
+			want: `{"type":"about:blank","title":"Not Found","status":404,"detail":"no user 7","instance":"/users/7"}`,
+		},
+		{
+			name: "empty members left out",
+			p:    &Details{Status: http.StatusInternalServerError},
+			want: `{"type":"about:blank","status":500}`,
+		},
+		{
+			name: "extensions sorted by name",
+			p:    New(http.StatusBadRequest, "").With("param", "page").With("max", 100),
+			want: `{"type":"about:blank","title":"Bad Request","status":400,"max":100,"param":"page"}`,
+		},
+		{
+			name: "keys escaped as JSON",
+			p:    New(http.StatusBadRequest, "").With("a\x01\"b", 1).With("é", 2),
+			want: `{"type":"about:blank","title":"Bad Request","status":400,"a\u0001\"b":1,"é":2}`,
+		},
+		{
+			name: "extensions never replace standard members",
+			p:    New(http.StatusConflict, "taken").With("status", 200).With("type", "x").With("detail", "leak"),
+			want: `{"type":"about:blank","title":"Conflict","status":409,"detail":"taken"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("got  %s\nwant %s", data, tt.want)
+			}
+			if !json.Valid(data) {
+				t.Errorf("%s is not valid JSON", data)
+			}
+		})
+	}
+}
+
+func TestMarshalJSONUnsupportedExtension(t *testing.T) {
+	p := New(http.StatusBadRequest, "").With("ch", make(chan int))
+	if _, err := json.Marshal(p); err == nil {
+		t.Error("marshaling a channel extension succeeded, want an error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	in := New(http.StatusUnprocessableEntity, "The request has invalid fields.").
+		WithType("https://example.com/probs/invalid", "Invalid request").
+		WithInstance("/users").
+		With("errors", []any{map[string]any{"field": "email", "message": "is required"}}).
+		With("a\x01b", "control character").
+		With("retry", true)
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Details
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+
+	want := *in
+	want.err = nil
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("round trip of %s\ngot  %+v\nwant %+v", data, out, want)
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Details
+	}{
+		{
+			name: "missing type is about:blank",
+			data: `{"status":404}`,
+			want: Details{Type: DefaultType, Status: 404},
+		},
+		{
+			name: "standard member of the wrong type ignored",
+			data: `{"type":"about:blank","status":"404","title":7}`,
+			want: Details{Type: DefaultType},
+		},
+		{
+			name: "unknown members kept as extensions",
+			data: `{"type":"about:blank","balance":30,"accounts":["/a/1"]}`,
+			want: Details{Type: DefaultType, Extensions: map[string]any{"balance": 30.0, "accounts": []any{"/a/1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Details
+			if err := json.Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFrom(t *testing.T) {
+	p := New(http.StatusNotFound, "no user 7")
+	verr := models.ValidationError{{Field: "email", Message: "is required"}}
+	cause := errors.New("connect: connection refused")
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		detail string
+		same   bool // From returns p itself
+	}{
+		{name: "problem", err: p, status: 404, detail: "no user 7", same: true},
+		{name: "wrapped problem", err: fmt.Errorf("load user: %w", p), status: 404, detail: "no user 7", same: true},
+		{name: "validation error", err: fmt.Errorf("create: %w", verr), status: 422, detail: "The request has invalid fields."},
+		{name: "other error", err: cause, status: 500},
+		{name: "wrapped cause", err: Wrap(http.StatusBadGateway, cause), status: 502, detail: cause.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := From(tt.err)
+			if got.Status != tt.status || got.Detail != tt.detail {
+				t.Errorf("From = %d %q, want %d %q", got.Status, got.Detail, tt.status, tt.detail)
+			}
+			if tt.same && got != p {
+				t.Errorf("From returned a copy, want the wrapped *Details")
+			}
+		})
+	}
+
+	t.Run("validation fields", func(t *testing.T) {
+		got := From(verr)
+		if fields, _ := got.Extensions["errors"].([]models.FieldError); len(fields) != 1 || fields[0].Field != "email" {
+			t.Errorf("errors = %v, want the email field", got.Extensions["errors"])
+		}
+		if !errors.As(got, &verr) {
+			t.Error("the problem does not unwrap to the validation error")
+		}
+	})
+
+	t.Run("cause kept and never sent", func(t *testing.T) {
+		got := From(fmt.Errorf("handler: %w", cause))
+		if !errors.Is(got, cause) {
+			t.Error("errors.Is(From(err), cause) = false")
+		}
+		data, _ := json.Marshal(got)
+		if string(data) != `{"type":"about:blank","title":"Internal Server Error","status":500}` {
+			t.Errorf("500 body = %s, want no detail", data)
+		}
+	})
+}
+
+func TestErrorsAs(t *testing.T) {
+	err := fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", New(http.StatusConflict, "taken")))
+
+	var p *Details
+	if !errors.As(err, &p) || p.Status != http.StatusConflict {
+		t.Fatalf("errors.As(%v) = %v, want the 409", err, p)
+	}
+	if got, want := p.Error(), "409 Conflict: taken"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestStatusCode(t *testing.T) {
+	for status, want := range map[int]int{0: 500, 99: 500, 404: 404, 599: 599, 600: 500} {
+		if got := (&Details{Status: status}).StatusCode(); got != want {
+			t.Errorf("StatusCode of %d = %d, want %d", status, got, want)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Package registry describes the frameworks covered by the book. The
+// extractor, the conformance harness and the version and dependency tools
+// all iterate over Frameworks, so covering another framework is one entry
+// here plus its README sections.
+package registry
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Capability is a property of a framework that tools and chapters compare.
+type Capability uint
+
+const (
+	// NetHTTP means the router is an http.Handler and accepts standard
+	// middleware.
+	NetHTTP Capability = 1 << iota
+	// FastHTTP means the framework is built on valyala/fasthttp and needs an
+	// adaptor to interoperate with net/http.
+	FastHTTP
+	// ErrorReturn means handlers return an error instead of writing it.
+	ErrorReturn
+)
+
+var capabilityNames = []struct {
+	Cap  Capability
+	Name string
+}{
+	{NetHTTP, "net/http compatible"},
+	{FastHTTP, "fasthttp based"},
+	{ErrorReturn, "handlers return error"},
+}
+
+func (c Capability) String() string {
+	var names []string
+	for _, n := range capabilityNames {
+		if c&n.Cap != 0 {
+			names = append(names, n.Name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Framework is one framework variant of every chapter.
+type Framework struct {
+	Title       string // README section heading, as in "## Gin"
+	Dir         string // directory inside each chapter
+	Module      string // module path, empty for the standard library
+	Major       int    // major version of Module
+	URL         string // source repository
+	Description string
+	Caps        Capability
+	Handler     string // handler parameters, as in "c *gin.Context"
+}
+
+// Frameworks lists every framework in the order chapters present them.
+var Frameworks = []Framework{
+	{
+		Title:       "net/http",
+		Dir:         "nethttp",
+		URL:         "https://github.com/golang/go",
+		Description: "Go standard library HTTP server",
+		Caps:        NetHTTP,
+		Handler:     "w http.ResponseWriter, r *http.Request",
+	},
+	{
+		Title:       "Chi",
+		Dir:         "chi",
+		Module:      "github.com/go-chi/chi/v5",
+		Major:       5,
+		URL:         "https://github.com/go-chi/chi",
+		Description: "Router built on net/http",
+		Caps:        NetHTTP,
+		Handler:     "w http.ResponseWriter, r *http.Request",
+	},
+	{
+		Title:       "Gin",
+		Dir:         "gin",
+		Module:      "github.com/gin-gonic/gin",
+		Major:       1,
+		URL:         "https://github.com/gin-gonic/gin",
+		Description: "API focused HTTP framework",
+		Caps:        NetHTTP,
+		Handler:     "c *gin.Context",
+	},
+	{
+		Title:       "Echo",
+		Dir:         "echo",
+		Module:      "github.com/labstack/echo/v4",
+		Major:       4,
+		URL:         "https://github.com/labstack/echo",
+		Description: "HTTP framework with error returns",
+		Caps:        NetHTTP | ErrorReturn,
+		Handler:     "c echo.Context",
+	},
+	{
+		Title:       "Fiber",
+		Dir:         "fiber",
+		Module:      "github.com/gofiber/fiber/v2",
+		Major:       2,
+		URL:         "https://github.com/gofiber/fiber",
+		Description: "fasthttp based framework",
+		Caps:        FastHTTP | ErrorReturn,
+		Handler:     "c *fiber.Ctx",
+	},
+	{
+		Title:       "Mizu",
+		Dir:         "mizu",
+		Module:      "github.com/go-mizu/mizu",
+		Major:       0,
+		URL:         "https://github.com/go-mizu/mizu",
+		Description: "net/http aligned framework",
+		Caps:        NetHTTP | ErrorReturn,
+		Handler:     "c *mizu.Ctx",
+	},
+}
+
+// Has reports whether f has every capability in c.
+func (f Framework) Has(c Capability) bool {
+	return f.Caps&c == c
+}
+
+// Stdlib reports whether f is the standard library rather than a module.
+func (f Framework) Stdlib() bool {
+	return f.Module == ""
+}
+
+// ImportPath is the package handlers are written against: the module root,
+// or net/http for the standard library.
+func (f Framework) ImportPath() string {
+	if f.Stdlib() {
+		return "net/http"
+	}
+	return f.Module
+}
+
+// PackageName is the name ImportPath is referred to by in code, as in
+// "gin" or "http".
+func (f Framework) PackageName() string {
+	base := path.Base(f.ImportPath())
+	if f.Major >= 2 && base == "v"+strconv.Itoa(f.Major) {
+		base = path.Base(path.Dir(f.ImportPath()))
+	}
+	return base
+}
+
+// ByDir returns the framework whose chapter directory is dir.
+func ByDir(dir string) (Framework, bool) {
+	for _, f := range Frameworks {
+		if f.Dir == dir {
+			return f, true
+		}
+	}
+	return Framework{}, false
+}
+
+// Dirs returns the chapter directory of every framework.
+func Dirs() []string {
+	dirs := make([]string, len(Frameworks))
+	for i, f := range Frameworks {
+		dirs[i] = f.Dir
+	}
+	return dirs
+}
+
+// Modules returns the module path of every framework that is not the
+// standard library.
+func Modules() []string {
+	var mods []string
+	for _, f := range Frameworks {
+		if !f.Stdlib() {
+			mods = append(mods, f.Module)
+		}
+	}
+	return mods
+}
@@ -0,0 +1,30 @@
+// Package echotracing creates the pkg/tracing spans of Echo handlers.
+package echotracing
+
+import (
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-mizu/go-fw/pkg/tracing"
+)
+
+// Middleware starts the server span of every request and names it after
+// the route c.Path reports. Handlers find the span in the context of
+// c.Request(), which they pass to outgoing calls. An error the handlers
+// return is passed to e.HTTPErrorHandler here, so the status recorded is
+// the one sent, and is not returned further. Install it with e.Use.
+func Middleware(t *tracing.Tracer) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			r := c.Request()
+			ctx, done := t.Begin(r.Context(), propagation.HeaderCarrier(r.Header), r.Method, tracing.RequestAttributes(r)...)
+			c.SetRequest(r.WithContext(ctx))
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+			done(c.Path(), c.Response().Status)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package echotracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/tracing"
+)
+
+const callerTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+var callerSpanID, _ = trace.SpanIDFromHex("00f067aa0ba902b7")
+
+func TestMiddleware(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tracer := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+
+	// the span the handler would pass to an outgoing call
+	var inHandler trace.SpanContext
+	e := echo.New()
+	e.Use(Middleware(tracer))
+	e.GET("/users/:id", func(c echo.Context) error {
+		inHandler = trace.SpanContextFromContext(c.Request().Context())
+		return c.String(http.StatusOK, "user "+c.Param("id")+"\n")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusServiceUnavailable)
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		name   string
+	}{
+		{"/users/1", http.StatusOK, "GET /users/:id"},
+		{"/nope", http.StatusNotFound, "GET"},
+		// the status of a returned error is the one recorded
+		{"/fail", http.StatusServiceUnavailable, "GET /fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			exp.Reset()
+			inHandler = trace.SpanContext{}
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("traceparent", callerTraceParent)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+
+			spans := exp.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("%d spans, want 1", len(spans))
+			}
+			s := spans[0]
+			if s.Name != tt.name {
+				t.Errorf("name = %q, want %q", s.Name, tt.name)
+			}
+			if got := s.Parent.SpanID(); got != callerSpanID {
+				t.Errorf("parent = %s, want the caller's %s", got, callerSpanID)
+			}
+			if got := attr(s, "http.response.status_code"); got != attribute.IntValue(tt.status) {
+				t.Errorf("http.response.status_code = %s, want %d", got.Emit(), tt.status)
+			}
+			if failed := s.Status.Code == codes.Error; failed != (tt.status >= 500) {
+				t.Errorf("span status = %v for a %d", s.Status.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && !inHandler.Equal(s.SpanContext) {
+				t.Errorf("the handler's context holds span %s, want %s", inHandler.SpanID(), s.SpanContext.SpanID())
+			}
+		})
+	}
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,75 @@
+// Package fibertracing creates the pkg/tracing spans of Fiber handlers.
+package fibertracing
+
+import (
+	"net"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+
+	"github.com/go-mizu/go-fw/pkg/instrument/fiberinstrument"
+	"github.com/go-mizu/go-fw/pkg/tracing"
+)
+
+// Middleware starts the server span of every request and names it after
+// the path of the route that served it. Fiber has no request context, so
+// handlers find the span in c.UserContext(), which they pass to outgoing
+// calls. An error the handlers return is passed to the app's ErrorHandler
+// here, so the status recorded is the one sent, and is not returned
+// further. Install it with app.Use before the routes.
+func Middleware(t *tracing.Tracer) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, done := t.Begin(c.UserContext(), headerCarrier{c}, c.Method(), requestAttributes(c)...)
+		c.SetUserContext(ctx)
+
+		route := fiberinstrument.Next(c)
+		done(route, c.Response().StatusCode())
+		return nil
+	}
+}
+
+// requestAttributes are those of tracing.RequestAttributes, read from
+// fasthttp.
+func requestAttributes(c *fiber.Ctx) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.URLPath(c.Path()),
+		semconv.URLScheme(c.Protocol()),
+		semconv.ClientAddress(c.IP()),
+	}
+	if host, p, err := net.SplitHostPort(c.Hostname()); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if port, err := strconv.Atoi(p); err == nil {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	} else if c.Hostname() != "" {
+		attrs = append(attrs, semconv.ServerAddress(c.Hostname()))
+	}
+	if ua := c.Get(fiber.HeaderUserAgent); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(ua))
+	}
+	return attrs
+}
+
+// headerCarrier reads the trace context from the request headers of c.
+type headerCarrier struct {
+	c *fiber.Ctx
+}
+
+func (h headerCarrier) Get(key string) string {
+	return h.c.Get(key)
+}
+
+func (h headerCarrier) Set(key, value string) {
+	h.c.Request().Header.Set(key, value)
+}
+
+func (h headerCarrier) Keys() []string {
+	headers := h.c.GetReqHeaders()
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,107 @@
+package fibertracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/tracing"
+)
+
+const callerTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+var callerSpanID, _ = trace.SpanIDFromHex("00f067aa0ba902b7")
+
+func TestMiddleware(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tracer := tracing.New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+
+	// the span the handler would pass to an outgoing call
+	var inHandler trace.SpanContext
+	app := fiber.New()
+	app.Use(Middleware(tracer))
+	app.Get("/users/:id", func(c *fiber.Ctx) error {
+		inHandler = trace.SpanContextFromContext(c.UserContext())
+		return c.SendString("user " + c.Params("id") + "\n")
+	})
+	app.Get("/fail", func(c *fiber.Ctx) error {
+		return fiber.ErrServiceUnavailable
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		name   string
+	}{
+		{"/users/1", http.StatusOK, "GET /users/:id"},
+		{"/nope", http.StatusNotFound, "GET"},
+		// the status of a returned error is the one recorded
+		{"/fail", http.StatusServiceUnavailable, "GET /fail"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			exp.Reset()
+			inHandler = trace.SpanContext{}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com:8080"+tt.path, nil)
+			req.Header.Set("traceparent", callerTraceParent)
+			req.Header.Set("User-Agent", "tracing-test")
+			res, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res.Body.Close()
+			if res.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", res.StatusCode, tt.status)
+			}
+
+			spans := exp.GetSpans()
+			if len(spans) != 1 {
+				t.Fatalf("%d spans, want 1", len(spans))
+			}
+			s := spans[0]
+			if s.Name != tt.name {
+				t.Errorf("name = %q, want %q", s.Name, tt.name)
+			}
+			if got := s.Parent.SpanID(); got != callerSpanID {
+				t.Errorf("parent = %s, want the caller's %s", got, callerSpanID)
+			}
+			if failed := s.Status.Code == codes.Error; failed != (tt.status >= 500) {
+				t.Errorf("span status = %v for a %d", s.Status.Code, tt.status)
+			}
+			if tt.status == http.StatusOK && !inHandler.Equal(s.SpanContext) {
+				t.Errorf("the handler's context holds span %s, want %s", inHandler.SpanID(), s.SpanContext.SpanID())
+			}
+
+			want := map[attribute.Key]attribute.Value{
+				"http.response.status_code": attribute.IntValue(tt.status),
+				"url.path":                  attribute.StringValue(tt.path),
+				"url.scheme":                attribute.StringValue("http"),
+				"server.address":            attribute.StringValue("example.com"),
+				"server.port":               attribute.IntValue(8080),
+				"user_agent.original":       attribute.StringValue("tracing-test"),
+			}
+			for k, v := range want {
+				if got := attr(s, k); got != v {
+					t.Errorf("%s = %q, want %q", k, got.Emit(), v.Emit())
+				}
+			}
+		})
+	}
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
@@ -0,0 +1,23 @@
+// Package gintracing creates the pkg/tracing spans of Gin handlers.
+package gintracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/go-mizu/go-fw/pkg/tracing"
+)
+
+// Middleware starts the server span of every request and names it after
+// the route c.FullPath reports. Handlers find the span in the context of
+// c.Request, which they pass to outgoing calls. Install it with r.Use
+// before the routes.
+func Middleware(t *tracing.Tracer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, done := t.Begin(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header), c.Request.Method, tracing.RequestAttributes(c.Request)...)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+		done(c.FullPath(), c.Writer.Status())
+	}
+}
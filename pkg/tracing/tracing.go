@@ -0,0 +1,228 @@
+// Package tracing creates OpenTelemetry spans for HTTP servers and clients
+// and propagates the W3C Trace Context, the traceparent and tracestate
+// headers, between them.
+//
+// Every request gets a server span, a child of the caller's span when the
+// request carries a traceparent. It is named after the route template the
+// router matched, such as GET /users/{id}, never the raw path, and carries
+// the attributes of the OpenTelemetry semantic conventions for HTTP. Calls
+// made through Transport get a client span, and the traceparent of that
+// span, so the server they reach continues the same trace.
+//
+// Middleware traces net/http and Chi; gintracing, echotracing and
+// fibertracing below this package do the same for their frameworks.
+package tracing
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.41.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/go-mizu/go-fw/pkg/instrument"
+)
+
+// ScopeName is the instrumentation scope of the spans of this package.
+const ScopeName = "github.com/go-mizu/go-fw/pkg/tracing"
+
+// Tracer starts the spans of one server. Create it with New.
+type Tracer struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// New returns a Tracer whose spans go to tp. The W3C Trace Context is the
+// only format read and written; tp decides what is sampled and where the
+// spans are exported.
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{
+		tracer:     tp.Tracer(ScopeName, trace.WithSchemaURL(semconv.SchemaURL)),
+		propagator: propagation.TraceContext{},
+	}
+}
+
+// Begin starts the server span of a request with method, as a child of the
+// trace context in header, and returns a context holding the span. The
+// returned function ends it, naming it after the route that served the
+// request, "" when none did, and recording the status code.
+func (t *Tracer) Begin(ctx context.Context, header propagation.TextMapCarrier, method string, attrs ...attribute.KeyValue) (context.Context, func(route string, code int)) {
+	ctx = t.propagator.Extract(ctx, header)
+
+	method, original := normalizeMethod(method)
+	attrs = append(attrs, semconv.HTTPRequestMethodKey.String(method))
+	if original != "" {
+		attrs = append(attrs, semconv.HTTPRequestMethodOriginal(original))
+	}
+	// renamed once the route is known
+	ctx, span := t.tracer.Start(ctx, spanName(method, ""),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+
+	return ctx, func(route string, code int) {
+		span.SetName(spanName(method, route))
+		if route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(code))
+		// a 4xx is the client's mistake, not a failure of the server
+		if code >= 500 {
+			span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(code)))
+			span.SetStatus(codes.Error, http.StatusText(code))
+		}
+		span.End()
+	}
+}
+
+// Middleware traces net/http handlers; the request the handlers get holds
+// the server span in its context. route names the route that served r, as
+// for httpmetrics.Middleware: httpmetrics.Pattern for a ServeMux,
+// chi.RouteContext(r.Context()).RoutePattern for Chi.
+func (t *Tracer) Middleware(route func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, done := t.Begin(r.Context(), propagation.HeaderCarrier(r.Header), r.Method, RequestAttributes(r)...)
+			r = r.WithContext(ctx)
+
+			instrument.Serve(w, r, next, func(code int, _ int64) {
+				done(route(r), code)
+			})
+		})
+	}
+}
+
+// RequestAttributes are the semantic convention attributes of a server
+// request known before it is routed.
+func RequestAttributes(r *http.Request) []attribute.KeyValue {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	attrs := []attribute.KeyValue{
+		semconv.URLPath(r.URL.Path),
+		semconv.URLScheme(scheme),
+		semconv.NetworkProtocolVersion(protocolVersion(r.ProtoMajor, r.ProtoMinor)),
+	}
+	if host, port := splitHostPort(r.Host); host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if port > 0 {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	}
+	if ua := r.UserAgent(); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(ua))
+	}
+	if host, _ := splitHostPort(r.RemoteAddr); host != "" {
+		attrs = append(attrs, semconv.ClientAddress(host))
+	}
+	return attrs
+}
+
+// Transport returns a RoundTripper that starts a client span for each
+// request, as a child of the span in the request's context, and sends its
+// traceparent and tracestate with the request. base sends the requests;
+// http.DefaultTransport when nil. Handlers pass their request's context to
+// the outgoing request, or the call starts a trace of its own.
+func (t *Tracer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{t: t, base: base}
+}
+
+type transport struct {
+	t    *Tracer
+	base http.RoundTripper
+}
+
+func (tr *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method, original := normalizeMethod(req.Method)
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLFull(req.URL.Redacted()),
+	}
+	if original != "" {
+		attrs = append(attrs, semconv.HTTPRequestMethodOriginal(original))
+	}
+	if host, port := splitHostPort(req.URL.Host); host != "" {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if port > 0 {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	}
+
+	ctx, span := tr.t.tracer.Start(req.Context(), spanName(method, ""),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer span.End()
+
+	// a RoundTripper must not modify the request it was given
+	req = req.Clone(ctx)
+	tr.t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := tr.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetAttributes(semconv.ErrorType(err))
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+	// for a client, any answer but success is a failed call
+	if res.StatusCode >= 400 {
+		span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(res.StatusCode)))
+		span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+	}
+	return res, nil
+}
+
+// spanName is "{method} {route}" as the semantic conventions name server
+// spans, the method alone without a route, and HTTP for an unknown method.
+func spanName(method, route string) string {
+	if method == "_OTHER" {
+		method = "HTTP"
+	}
+	if route == "" {
+		return method
+	}
+	return method + " " + route
+}
+
+// normalizeMethod keeps the methods the semantic conventions know; any
+// other is recorded as _OTHER, with the original in its own attribute.
+func normalizeMethod(method string) (normalized, original string) {
+	if instrument.KnownMethod(method) {
+		return method, ""
+	}
+	return "_OTHER", method
+}
+
+// protocolVersion is the network.protocol.version of HTTP major.minor,
+// such as 1.1 or 2.
+func protocolVersion(major, minor int) string {
+	if minor == 0 && major >= 2 {
+		return strconv.Itoa(major)
+	}
+	return strconv.Itoa(major) + "." + strconv.Itoa(minor)
+}
+
+// splitHostPort splits host[:port]; port is 0 when missing or invalid.
+func splitHostPort(hostport string) (string, int) {
+	host, p, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, 0
+	}
+	port, err := strconv.Atoi(p)
+	if err != nil {
+		return host, 0
+	}
+	return host, port
+}
@@ -0,0 +1,440 @@
+package tracing
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The trace context of the client in the traced requests of the tests,
+// from the examples of the W3C Trace Context specification.
+const (
+	callerTraceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	callerTraceState  = "congo=t61rcWkgMzE"
+)
+
+var (
+	callerTraceID, _ = trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	callerSpanID, _  = trace.SpanIDFromHex("00f067aa0ba902b7")
+)
+
+// serve starts a traced server with GET /, GET /users/{id}, GET
+// /profiles/{id}, answering "profile <id>\n" or 404 for the ID 0, GET
+// /users/{id}/profile, which fetches the profile from the server itself
+// through Transport and answers 502 when that fails, and GET /panic. It
+// returns the URL of the server and the exporter its spans end up in.
+func serve(t *testing.T) (string, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exp := tracetest.NewInMemoryExporter()
+	tracer := New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+	client := &http.Client{Transport: tracer.Transport(nil)}
+
+	srv := httptest.NewUnstartedServer(nil)
+	base := "http://" + srv.Listener.Addr().String()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("user " + r.PathValue("id") + "\n"))
+	})
+	mux.HandleFunc("GET /users/{id}/profile", func(w http.ResponseWriter, r *http.Request) {
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, base+"/profiles/"+r.PathValue("id"), nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		res, err := client.Do(req)
+		if err != nil || res.StatusCode != http.StatusOK {
+			http.Error(w, "profile unavailable", http.StatusBadGateway)
+			return
+		}
+		defer res.Body.Close()
+		io.Copy(w, res.Body)
+	})
+	mux.HandleFunc("GET /profiles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") == "0" {
+			http.Error(w, "no profile", http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("profile " + r.PathValue("id") + "\n"))
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	route := func(r *http.Request) string {
+		return strings.TrimPrefix(r.Pattern, "GET ")
+	}
+	srv.Config.Handler = tracer.Middleware(route)(mux)
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return srv.URL, exp
+}
+
+func TestServerSpan(t *testing.T) {
+	url, exp := serve(t)
+	get(t, url+"/users/1", true, http.StatusOK)
+
+	s := find(t, wait(t, exp, 1), trace.SpanKindServer, "GET /users/{id}")
+	checkParent(t, s, callerSpanID, true)
+	if got := s.SpanContext.TraceState().String(); got != callerTraceState {
+		t.Errorf("tracestate = %q, want %q", got, callerTraceState)
+	}
+	checkAttrs(t, s, map[attribute.Key]attribute.Value{
+		"http.request.method":       attribute.StringValue("GET"),
+		"http.route":                attribute.StringValue("/users/{id}"),
+		"http.response.status_code": attribute.IntValue(200),
+		"url.path":                  attribute.StringValue("/users/1"),
+		"url.scheme":                attribute.StringValue("http"),
+		"network.protocol.version":  attribute.StringValue("1.1"),
+		"server.address":            attribute.StringValue("127.0.0.1"),
+		"client.address":            attribute.StringValue("127.0.0.1"),
+		"user_agent.original":       attribute.StringValue("tracing-test"),
+	})
+	if attr(s, "server.port").AsInt64() == 0 {
+		t.Errorf("%s has no server.port", s.Name)
+	}
+	if s.Status.Code != codes.Unset {
+		t.Errorf("status = %v, want unset", s.Status.Code)
+	}
+}
+
+func TestNewTrace(t *testing.T) {
+	url, exp := serve(t)
+	get(t, url+"/", false, http.StatusOK)
+
+	s := find(t, wait(t, exp, 1), trace.SpanKindServer, "GET /{$}")
+	if s.Parent.IsValid() {
+		t.Errorf("%s has parent %s, want a root span", s.Name, s.Parent.SpanID())
+	}
+	if s.SpanContext.TraceID() == callerTraceID {
+		t.Errorf("%s continues a trace that was not sent", s.Name)
+	}
+}
+
+// A request no route matched is named after its method alone, and one
+// with an unknown method after HTTP, so neither adds a span name per path.
+func TestUnmatched(t *testing.T) {
+	url, exp := serve(t)
+
+	t.Run("path", func(t *testing.T) {
+		exp.Reset()
+		get(t, url+"/nope/42", true, http.StatusNotFound)
+
+		s := find(t, wait(t, exp, 1), trace.SpanKindServer, "GET")
+		if attr(s, "http.route").Type() != attribute.INVALID {
+			t.Errorf("%s has http.route %q, want none", s.Name, attr(s, "http.route").Emit())
+		}
+		checkAttrs(t, s, map[attribute.Key]attribute.Value{
+			"http.response.status_code": attribute.IntValue(404),
+		})
+		if s.Status.Code != codes.Unset {
+			t.Errorf("status of a 404 = %v, want unset", s.Status.Code)
+		}
+	})
+
+	t.Run("method", func(t *testing.T) {
+		exp.Reset()
+		send(t, "BREW", url+"/users/1", true, http.StatusMethodNotAllowed)
+
+		s := find(t, wait(t, exp, 1), trace.SpanKindServer, "HTTP")
+		checkAttrs(t, s, map[attribute.Key]attribute.Value{
+			"http.request.method":          attribute.StringValue("_OTHER"),
+			"http.request.method_original": attribute.StringValue("BREW"),
+			"http.response.status_code":    attribute.IntValue(405),
+		})
+	})
+}
+
+func TestOutgoingCall(t *testing.T) {
+	url, exp := serve(t)
+	get(t, url+"/users/1/profile", true, http.StatusOK)
+
+	spans := wait(t, exp, 3)
+	server := find(t, spans, trace.SpanKindServer, "GET /users/{id}/profile")
+	client := find(t, spans, trace.SpanKindClient, "GET")
+	downstream := find(t, spans, trace.SpanKindServer, "GET /profiles/{id}")
+
+	checkParent(t, server, callerSpanID, true)
+	checkParent(t, client, server.SpanContext.SpanID(), false)
+	checkParent(t, downstream, client.SpanContext.SpanID(), true)
+	if got := downstream.SpanContext.TraceState().String(); got != callerTraceState {
+		t.Errorf("downstream tracestate = %q, want %q", got, callerTraceState)
+	}
+	checkAttrs(t, client, map[attribute.Key]attribute.Value{
+		"http.request.method":       attribute.StringValue("GET"),
+		"http.response.status_code": attribute.IntValue(200),
+		"url.full":                  attribute.StringValue(url + "/profiles/1"),
+		"server.address":            attribute.StringValue("127.0.0.1"),
+	})
+}
+
+func TestServerError(t *testing.T) {
+	url, exp := serve(t)
+	get(t, url+"/users/0/profile", true, http.StatusBadGateway)
+
+	spans := wait(t, exp, 3)
+	server := find(t, spans, trace.SpanKindServer, "GET /users/{id}/profile")
+	client := find(t, spans, trace.SpanKindClient, "GET")
+	downstream := find(t, spans, trace.SpanKindServer, "GET /profiles/{id}")
+
+	if server.Status.Code != codes.Error {
+		t.Errorf("status of the 502 = %v, want error", server.Status.Code)
+	}
+	checkAttrs(t, server, map[attribute.Key]attribute.Value{
+		"http.response.status_code": attribute.IntValue(502),
+		"error.type":                attribute.StringValue("502"),
+	})
+	// the 404 is the caller's failure, not the downstream server's
+	if client.Status.Code != codes.Error {
+		t.Errorf("status of the client span of a 404 = %v, want error", client.Status.Code)
+	}
+	checkAttrs(t, client, map[attribute.Key]attribute.Value{
+		"error.type": attribute.StringValue("404"),
+	})
+	if downstream.Status.Code != codes.Unset {
+		t.Errorf("status of the downstream 404 = %v, want unset", downstream.Status.Code)
+	}
+}
+
+// A handler that panics before sending anything ends its span as a 500.
+func TestPanic(t *testing.T) {
+	url, exp := serve(t)
+
+	req, err := http.NewRequest(http.MethodGet, url+"/panic", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := http.DefaultClient.Do(req); err == nil {
+		res.Body.Close()
+		t.Fatalf("GET /panic answered %d, want the connection dropped", res.StatusCode)
+	}
+
+	s := find(t, wait(t, exp, 1), trace.SpanKindServer, "GET /panic")
+	if s.Status.Code != codes.Error {
+		t.Errorf("status = %v, want error", s.Status.Code)
+	}
+	checkAttrs(t, s, map[attribute.Key]attribute.Value{
+		"http.response.status_code": attribute.IntValue(500),
+	})
+}
+
+// A call that gets no answer is an error of its client span, and the
+// request the caller made keeps its headers.
+func TestTransportError(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tracer := New(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp)))
+
+	srv := httptest.NewServer(nil)
+	url := srv.URL
+	srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, strings.Replace(url, "http://", "http://user:secret@", 1)+"/profiles/1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracer.Transport(nil).RoundTrip(req); err == nil {
+		t.Fatal("the call to a closed server succeeded")
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("the request was given headers %v", req.Header)
+	}
+
+	s := find(t, wait(t, exp, 1), trace.SpanKindClient, "GET")
+	if s.Status.Code != codes.Error {
+		t.Errorf("status = %v, want error", s.Status.Code)
+	}
+	if attr(s, "error.type").Type() == attribute.INVALID {
+		t.Errorf("%s has no error.type", s.Name)
+	}
+	if got := attr(s, "url.full").AsString(); strings.Contains(got, "secret") {
+		t.Errorf("url.full = %q, want the password redacted", got)
+	}
+}
+
+func TestRequestAttributes(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/users/1", nil)
+	r.TLS = &tls.ConnectionState{}
+	r.ProtoMajor, r.ProtoMinor = 2, 0
+	r.RemoteAddr = "[::1]:52000"
+
+	got := map[attribute.Key]attribute.Value{}
+	for _, kv := range RequestAttributes(r) {
+		got[kv.Key] = kv.Value
+	}
+	want := map[attribute.Key]attribute.Value{
+		"url.path":                 attribute.StringValue("/users/1"),
+		"url.scheme":               attribute.StringValue("https"),
+		"network.protocol.version": attribute.StringValue("2"),
+		"server.address":           attribute.StringValue("example.com"),
+		"client.address":           attribute.StringValue("::1"),
+	}
+	if len(got) != len(want) {
+		t.Errorf("attributes = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k].Emit(), v.Emit())
+		}
+	}
+}
+
+func TestProtocolVersion(t *testing.T) {
+	tests := []struct {
+		major, minor int
+		want         string
+	}{
+		{1, 0, "1.0"},
+		{1, 1, "1.1"},
+		{2, 0, "2"},
+		{3, 0, "3"},
+	}
+	for _, tt := range tests {
+		if got := protocolVersion(tt.major, tt.minor); got != tt.want {
+			t.Errorf("protocolVersion(%d, %d) = %q, want %q", tt.major, tt.minor, got, tt.want)
+		}
+	}
+}
+
+func TestSplitHostPort(t *testing.T) {
+	tests := []struct {
+		hostport string
+		host     string
+		port     int
+	}{
+		{"example.com:8080", "example.com", 8080},
+		{"example.com", "example.com", 0},
+		{"[::1]:443", "::1", 443},
+		{"example.com:http", "example.com", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		host, port := splitHostPort(tt.hostport)
+		if host != tt.host || port != tt.port {
+			t.Errorf("splitHostPort(%q) = %q, %d, want %q, %d", tt.hostport, host, port, tt.host, tt.port)
+		}
+	}
+}
+
+// get sends a GET, with the caller's trace context when traced.
+func get(t *testing.T, url string, traced bool, status int) {
+	t.Helper()
+	send(t, http.MethodGet, url, traced, status)
+}
+
+func send(t *testing.T, method, url string, traced bool, status int) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("User-Agent", "tracing-test")
+	if traced {
+		req.Header.Set("traceparent", callerTraceParent)
+		req.Header.Set("tracestate", callerTraceState)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	if res.StatusCode != status {
+		t.Fatalf("%s %s: status = %d, want %d", method, url, res.StatusCode, status)
+	}
+}
+
+// wait returns the spans of exp once there are n. A server span ends after
+// the response is sent, so it may be exported after the client has read
+// the response.
+func wait(t *testing.T, exp *tracetest.InMemoryExporter, n int) tracetest.SpanStubs {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		spans := exp.GetSpans()
+		if len(spans) >= n {
+			if len(spans) > n {
+				t.Errorf("%d spans, want %d: %v", len(spans), n, names(spans))
+			}
+			return spans
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d spans after 2s, want %d: %v", len(spans), n, names(spans))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// find returns the span of kind named name.
+func find(t *testing.T, spans tracetest.SpanStubs, kind trace.SpanKind, name string) tracetest.SpanStub {
+	t.Helper()
+
+	i := slices.IndexFunc(spans, func(s tracetest.SpanStub) bool {
+		return s.SpanKind == kind && s.Name == name
+	})
+	if i < 0 {
+		t.Fatalf("no %s span %q in %v", kind, name, names(spans))
+	}
+	return spans[i]
+}
+
+// checkParent checks that s is in the caller's trace, a child of the span
+// parent, which is in another process when remote.
+func checkParent(t *testing.T, s tracetest.SpanStub, parent trace.SpanID, remote bool) {
+	t.Helper()
+
+	if got := s.SpanContext.TraceID(); got != callerTraceID {
+		t.Errorf("%s: trace ID = %s, want %s", s.Name, got, callerTraceID)
+	}
+	if got := s.Parent.SpanID(); got != parent {
+		t.Errorf("%s: parent = %s, want %s", s.Name, got, parent)
+	}
+	if s.Parent.IsRemote() != remote {
+		t.Errorf("%s: remote parent = %t, want %t", s.Name, s.Parent.IsRemote(), remote)
+	}
+}
+
+func checkAttrs(t *testing.T, s tracetest.SpanStub, want map[attribute.Key]attribute.Value) {
+	t.Helper()
+
+	for k, v := range want {
+		if got := attr(s, k); got != v {
+			t.Errorf("%s: %s = %q, want %q", s.Name, k, got.Emit(), v.Emit())
+		}
+	}
+}
+
+func attr(s tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range s.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func names(spans tracetest.SpanStubs) []string {
+	out := make([]string, len(spans))
+	for i, s := range spans {
+		out[i] = s.SpanKind.String() + " " + s.Name
+	}
+	return out
+}
@@ -0,0 +1,213 @@
+package tus
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// DefaultChunkSize is the PATCH size of a Client without ChunkSize, 4 MiB.
+const DefaultChunkSize = 4 << 20
+
+// maxConflicts is how many 409 answers in a row Resume takes before it
+// gives up; more mean another client is writing the same upload.
+const maxConflicts = 3
+
+// Client uploads files to a tus server. When an upload fails partway,
+// Resume continues it from the offset the server reports, so no byte the
+// server has confirmed is sent twice.
+type Client struct {
+	// Endpoint is the creation URL, such as http://localhost:8080/files/.
+	Endpoint string
+
+	// HTTP sends the requests; http.DefaultClient when nil.
+	HTTP *http.Client
+
+	// ChunkSize is the most bytes sent in one PATCH; DefaultChunkSize when
+	// zero. A dropped connection costs at most one chunk.
+	ChunkSize int64
+
+	// Checksum sends the SHA-256 of every chunk, so the server refuses a
+	// corrupted chunk instead of storing it.
+	Checksum bool
+}
+
+// StatusError is a response with a status the client did not expect.
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Detail     string // of the problem in the body, when there is one
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("tus: %s %s: %d %s", e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Upload creates an upload of length bytes and sends src. The URL of the
+// upload is returned even when sending fails, so the caller can Resume.
+func (c *Client) Upload(ctx context.Context, src io.ReaderAt, length int64, metadata map[string]string) (string, error) {
+	loc, err := c.Create(ctx, length, metadata)
+	if err != nil {
+		return "", err
+	}
+	return loc, c.Resume(ctx, loc, src, length)
+}
+
+// Create starts an upload of length bytes and returns its URL.
+func (c *Client) Create(ctx context.Context, length int64, metadata map[string]string) (string, error) {
+	req, err := c.request(ctx, http.MethodPost, c.Endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Upload-Length", strconv.FormatInt(length, 10))
+	if len(metadata) > 0 {
+		req.Header.Set("Upload-Metadata", FormatMetadata(metadata))
+	}
+
+	res, err := c.do(req, http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+	loc, err := res.Location()
+	if err != nil {
+		return "", fmt.Errorf("tus: create: %w", err)
+	}
+	return loc.String(), nil
+}
+
+// Offset returns how many bytes of the upload at loc the server holds.
+func (c *Client) Offset(ctx context.Context, loc string) (int64, error) {
+	req, err := c.request(ctx, http.MethodHead, loc, nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := c.do(req, http.StatusOK)
+	if err != nil {
+		return 0, err
+	}
+	return uploadOffset(res)
+}
+
+// Resume sends the bytes of src that the upload at loc lacks, in chunks,
+// starting at the offset the server reports. A 409 Conflict means the
+// upload moved on, as when a PATCH cut off by a dropped connection is
+// stored after the offset was read; Resume reads it again and goes on.
+func (c *Client) Resume(ctx context.Context, loc string, src io.ReaderAt, length int64) error {
+	offset, err := c.Offset(ctx, loc)
+	if err != nil {
+		return err
+	}
+
+	conflicts := 0
+	for offset < length {
+		n := min(c.chunkSize(), length-offset)
+		chunk := io.NewSectionReader(src, offset, n)
+
+		req, err := c.request(ctx, http.MethodPatch, loc, chunk)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = n
+		req.Header.Set("Content-Type", ContentType)
+		req.Header.Set("Upload-Offset", strconv.FormatInt(offset, 10))
+		if c.Checksum {
+			h := sha256.New()
+			if _, err := io.Copy(h, io.NewSectionReader(src, offset, n)); err != nil {
+				return err
+			}
+			req.Header.Set("Upload-Checksum", "sha256 "+base64.StdEncoding.EncodeToString(h.Sum(nil)))
+		}
+
+		res, err := c.do(req, http.StatusNoContent)
+		var status *StatusError
+		if errors.As(err, &status) && status.StatusCode == http.StatusConflict && conflicts < maxConflicts {
+			// a request from before a dropped connection got in first
+			conflicts++
+			if offset, err = c.Offset(ctx, loc); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		conflicts = 0
+		next, err := uploadOffset(res)
+		if err != nil {
+			return err
+		}
+		if next <= offset {
+			return fmt.Errorf("tus: PATCH %s: offset stayed at %d", loc, offset)
+		}
+		offset = next
+	}
+	return nil
+}
+
+// Terminate deletes the upload at loc.
+func (c *Client) Terminate(ctx context.Context, loc string) error {
+	req, err := c.request(ctx, http.MethodDelete, loc, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, http.StatusNoContent)
+	return err
+}
+
+func (c *Client) request(ctx context.Context, method, loc string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, loc, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Tus-Resumable", Version)
+	return req, nil
+}
+
+// do sends req and turns any status but want into a *StatusError. The body
+// of a successful response is empty in tus, so it is closed here.
+func (c *Client) do(req *http.Request, want int) (*http.Response, error) {
+	hc := c.HTTP
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	res, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != want {
+		var p struct{ Detail string }
+		json.NewDecoder(io.LimitReader(res.Body, 1<<16)).Decode(&p)
+		return nil, &StatusError{Method: req.Method, URL: req.URL.Redacted(), StatusCode: res.StatusCode, Detail: p.Detail}
+	}
+	io.Copy(io.Discard, io.LimitReader(res.Body, 1<<16))
+	return res, nil
+}
+
+func (c *Client) chunkSize() int64 {
+	if c.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return c.ChunkSize
+}
+
+func uploadOffset(res *http.Response) (int64, error) {
+	offset, err := strconv.ParseInt(res.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("tus: %s %s: bad Upload-Offset %q", res.Request.Method, res.Request.URL.Redacted(), res.Header.Get("Upload-Offset"))
+	}
+	return offset, nil
+}
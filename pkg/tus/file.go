@@ -0,0 +1,211 @@
+package tus
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// idPattern matches the IDs FileStore makes, so no other string reaches a
+// file system path.
+var idPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// FileStore is a Store in a directory. An upload is two files: <id> holds
+// the bytes received so far, so its size is the offset, and <id>.info the
+// length and metadata, written once at creation.
+//
+// The locks live in the FileStore, so only one process may serve a
+// directory.
+type FileStore struct {
+	dir string
+
+	mu    sync.Mutex
+	locks map[string]*lock
+}
+
+// lock is held by whoever put a value into ch. refs counts the holder and
+// the waiters, so the entry is dropped when nobody needs it.
+type lock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewFileStore returns a store in dir, creating it when it does not exist.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir, locks: map[string]*lock{}}, nil
+}
+
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id)
+}
+
+func (s *FileStore) Create(ctx context.Context, length int64, metadata map[string]string) (Info, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return Info{}, err
+	}
+	info := Info{ID: hex.EncodeToString(b), Length: length, Metadata: metadata}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return Info{}, err
+	}
+	if err := os.WriteFile(s.path(info.ID), nil, 0o640); err != nil {
+		return Info{}, err
+	}
+	if err := writeFile(s.path(info.ID)+".info", data); err != nil {
+		os.Remove(s.path(info.ID))
+		return Info{}, err
+	}
+	return info, nil
+}
+
+func (s *FileStore) Get(ctx context.Context, id string) (Info, error) {
+	if !idPattern.MatchString(id) {
+		return Info{}, ErrNotFound
+	}
+
+	data, err := os.ReadFile(s.path(id) + ".info")
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	var info Info
+	if err := json.Unmarshal(data, &info); err != nil {
+		return Info{}, fmt.Errorf("tus: %s.info: %w", id, err)
+	}
+
+	fi, err := os.Stat(s.path(id))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Info{}, ErrNotFound
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	info.Offset = fi.Size()
+	return info, nil
+}
+
+func (s *FileStore) Write(ctx context.Context, id string, offset int64, body io.Reader) (int64, error) {
+	if !idPattern.MatchString(id) {
+		return 0, ErrNotFound
+	}
+
+	f, err := os.OpenFile(s.path(id), os.O_WRONLY, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return 0, err
+	}
+	n, err := io.Copy(f, body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return n, err
+}
+
+func (s *FileStore) Truncate(ctx context.Context, id string, offset int64) error {
+	if !idPattern.MatchString(id) {
+		return ErrNotFound
+	}
+	return os.Truncate(s.path(id), offset)
+}
+
+func (s *FileStore) Delete(ctx context.Context, id string) error {
+	if !idPattern.MatchString(id) {
+		return nil
+	}
+
+	for _, name := range []string{s.path(id) + ".info", s.path(id)} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) Open(ctx context.Context, id string) (io.ReadCloser, error) {
+	info, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Done() {
+		return nil, ErrIncomplete
+	}
+	return os.Open(s.path(id))
+}
+
+// Lock queues the caller behind the current holder of id. A client that
+// resumes after a dropped connection may get here before the server has
+// noticed the drop; it waits for the old request to finish instead of
+// reading an offset that is about to change.
+func (s *FileStore) Lock(ctx context.Context, id string) (func(), error) {
+	s.mu.Lock()
+	l := s.locks[id]
+	if l == nil {
+		l = &lock{ch: make(chan struct{}, 1)}
+		s.locks[id] = l
+	}
+	l.refs++
+	s.mu.Unlock()
+
+	release := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		l.refs--
+		if l.refs == 0 {
+			delete(s.locks, id)
+		}
+	}
+
+	select {
+	case l.ch <- struct{}{}:
+		return func() {
+			<-l.ch
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// writeFile writes data under a temporary name and renames it into place,
+// so a reader never sees half a file.
+func writeFile(name string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tus-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,52 @@
+package tus
+
+import (
+	"encoding/base64"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// ParseMetadata decodes an Upload-Metadata header: comma-separated pairs
+// of a key and its base64 value, where the value may be left out.
+func ParseMetadata(header string) (map[string]string, error) {
+	if strings.TrimSpace(header) == "" {
+		return nil, nil
+	}
+
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, enc, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" || strings.Contains(enc, " ") {
+			return nil, errors.New("Upload-Metadata must be comma-separated keys and base64 values")
+		}
+		if _, dup := metadata[key]; dup {
+			return nil, errors.New("Upload-Metadata has key " + key + " twice")
+		}
+		value, err := base64.StdEncoding.DecodeString(enc)
+		if err != nil {
+			return nil, errors.New("Upload-Metadata value of " + key + " is not base64")
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// FormatMetadata encodes metadata as an Upload-Metadata header, with the
+// keys sorted.
+func FormatMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for k := range metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k
+		if v := metadata[k]; v != "" {
+			pairs[i] += " " + base64.StdEncoding.EncodeToString([]byte(v))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,52 @@
+package tus
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		header string
+		want   map[string]string
+		err    bool
+	}{
+		{header: "", want: nil},
+		{header: "  ", want: nil},
+		{header: "filename aGVsbG8udHh0", want: map[string]string{"filename": "hello.txt"}},
+		{header: "filename aGVsbG8udHh0, draft", want: map[string]string{"filename": "hello.txt", "draft": ""}},
+		{header: "filename", want: map[string]string{"filename": ""}},
+
+		{header: "filename !", err: true},
+		{header: "filename a b", err: true},
+		{header: ",filename", err: true},
+		{header: "draft,draft", err: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMetadata(tt.header)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseMetadata(%q) error = %v, want error %v", tt.header, err, tt.err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseMetadata(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+// FormatMetadata sorts the keys and leaves out empty values, and
+// ParseMetadata reads back what it wrote.
+func TestFormatMetadata(t *testing.T) {
+	metadata := map[string]string{"filename": "report 2024.pdf", "draft": "", "type": "ü"}
+
+	header := FormatMetadata(metadata)
+	if want := "draft,filename cmVwb3J0IDIwMjQucGRm,type w7w="; header != want {
+		t.Errorf("FormatMetadata = %q, want %q", header, want)
+	}
+
+	got, err := ParseMetadata(header)
+	if err != nil || !reflect.DeepEqual(got, metadata) {
+		t.Errorf("ParseMetadata(%q) = %v, %v, want %v", header, got, err, metadata)
+	}
+}
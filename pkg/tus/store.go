@@ -0,0 +1,55 @@
+package tus
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotFound is returned for an upload the store does not hold,
+	// including one that was terminated.
+	ErrNotFound = errors.New("tus: upload not found")
+
+	// ErrIncomplete is returned by Open for an upload that has not
+	// received all of its bytes.
+	ErrIncomplete = errors.New("tus: upload is incomplete")
+)
+
+// Info describes an upload.
+type Info struct {
+	ID       string            `json:"id"`
+	Length   int64             `json:"length"`             // declared size in bytes
+	Offset   int64             `json:"offset"`             // bytes received so far
+	Metadata map[string]string `json:"metadata,omitempty"` // from Upload-Metadata
+}
+
+// Done reports whether every byte of the upload has been received.
+func (i Info) Done() bool {
+	return i.Offset == i.Length
+}
+
+// Store keeps uploads while they are written in chunks. The Handler takes
+// the lock of an upload for every request that reads or changes it, so a
+// store only has to make Lock exclusive.
+type Store interface {
+	// Create starts an empty upload of length bytes.
+	Create(ctx context.Context, length int64, metadata map[string]string) (Info, error)
+	// Get returns the upload id, with its current offset.
+	Get(ctx context.Context, id string) (Info, error)
+	// Write appends body at offset, which is the current offset of the
+	// upload, and returns the number of bytes written, which counts even
+	// when body fails partway.
+	Write(ctx context.Context, id string, offset int64, body io.Reader) (int64, error)
+	// Truncate drops the bytes after offset, to undo a chunk that failed
+	// its checksum.
+	Truncate(ctx context.Context, id string, offset int64) error
+	// Delete removes the upload. Deleting a missing upload is not an
+	// error.
+	Delete(ctx context.Context, id string) error
+	// Open returns the content of a finished upload.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// Lock waits until the caller holds the upload id, or ctx is done, and
+	// returns the function that releases it.
+	Lock(ctx context.Context, id string) (func(), error)
+}
@@ -0,0 +1,356 @@
+// Package tus implements the server side of tus 1.0, the open protocol for
+// resumable uploads, with the creation, termination and checksum
+// extensions, and a client for it. A file is created with POST, appended
+// to in chunks with PATCH at the offset the server last confirmed, and,
+// after a dropped connection, resumed from the offset that HEAD reports.
+//
+// Handler is a plain http.Handler, so every framework mounts the same
+// implementation. Its errors are pkg/problem errors; where the bytes go is
+// a Store.
+package tus
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+const (
+	// Version is the protocol version this package speaks.
+	Version = "1.0.0"
+
+	// Extensions lists the extensions Handler supports.
+	Extensions = "creation,termination,checksum"
+
+	// ContentType is the media type of a PATCH body.
+	ContentType = "application/offset+octet-stream"
+
+	// StatusChecksumMismatch answers a chunk whose checksum does not match
+	// its bytes; tus defines it, HTTP does not.
+	StatusChecksumMismatch = 460
+
+	// DefaultMaxSize is the largest upload a Handler without MaxSize
+	// accepts, 1 GiB.
+	DefaultMaxSize = 1 << 30
+
+	// DefaultLockTimeout is how long a Handler without LockTimeout waits
+	// for an upload that another request holds.
+	DefaultLockTimeout = 5 * time.Second
+)
+
+// checksums are the algorithms of the checksum extension; tus requires
+// sha1.
+var checksums = map[string]func() hash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+// Handler serves tus uploads below BasePath: POST to BasePath creates one,
+// and each upload lives at BasePath followed by its ID.
+type Handler struct {
+	Store Store
+
+	// BasePath is the path the handler is mounted at, ending in a slash,
+	// such as "/files/". The handler sees full paths, so it is mounted
+	// without stripping the prefix.
+	BasePath string
+
+	// MaxSize caps the declared length of an upload; DefaultMaxSize when
+	// zero.
+	MaxSize int64
+
+	// LockTimeout caps the wait for an upload that another request holds,
+	// after which the answer is 423 Locked; DefaultLockTimeout when zero.
+	LockTimeout time.Duration
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	problem.HandlerFunc(h.serve).ServeHTTP(w, r)
+}
+
+func (h *Handler) serve(w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Tus-Resumable", Version)
+
+	method := r.Method
+	// for clients behind proxies that only pass GET and POST; only a POST
+	// is overridden, so that a GET, which a page can make another origin
+	// send, never becomes a PATCH or a DELETE
+	if m := r.Header.Get("X-HTTP-Method-Override"); m != "" && r.Method == http.MethodPost {
+		method = strings.ToUpper(m)
+	}
+
+	if method == http.MethodOptions {
+		w.Header().Set("Tus-Version", Version)
+		w.Header().Set("Tus-Extension", Extensions)
+		w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.maxSize(), 10))
+		w.Header().Set("Tus-Checksum-Algorithm", algorithms())
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	if v := r.Header.Get("Tus-Resumable"); v != Version {
+		w.Header().Set("Tus-Version", Version)
+		return problem.New(http.StatusPreconditionFailed, fmt.Sprintf("Tus-Resumable must be %s", Version))
+	}
+
+	id, ok := strings.CutPrefix(r.URL.Path, h.BasePath)
+	if !ok || strings.Contains(id, "/") {
+		return problem.New(http.StatusNotFound, "")
+	}
+
+	if id == "" {
+		if method != http.MethodPost {
+			return notAllowed(w, "OPTIONS, POST")
+		}
+		return h.create(w, r)
+	}
+
+	switch method {
+	case http.MethodHead:
+		return h.head(w, r, id)
+	case http.MethodPatch:
+		return h.patch(w, r, id)
+	case http.MethodDelete:
+		return h.terminate(w, r, id)
+	default:
+		return notAllowed(w, "OPTIONS, HEAD, PATCH, DELETE")
+	}
+}
+
+// create answers POST: an empty upload of Upload-Length bytes, at the URL
+// in Location.
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) error {
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		return problem.New(http.StatusBadRequest, "Upload-Length must be a non-negative integer")
+	}
+	if length > h.maxSize() {
+		return problem.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("Upload-Length exceeds %d bytes", h.maxSize())).
+			With("limit", h.maxSize())
+	}
+
+	metadata, err := ParseMetadata(r.Header.Get("Upload-Metadata"))
+	if err != nil {
+		return problem.New(http.StatusBadRequest, err.Error())
+	}
+
+	info, err := h.Store.Create(r.Context(), length, metadata)
+	if err != nil {
+		return fmt.Errorf("tus: create: %w", err)
+	}
+
+	w.Header().Set("Location", h.BasePath+info.ID)
+	w.Header().Set("Upload-Offset", "0")
+	w.WriteHeader(http.StatusCreated)
+	return nil
+}
+
+// head answers HEAD with the offset to resume from.
+func (h *Handler) head(w http.ResponseWriter, r *http.Request, id string) error {
+	unlock, err := h.lock(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	info, err := h.get(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(info.Offset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(info.Length, 10))
+	if len(info.Metadata) > 0 {
+		w.Header().Set("Upload-Metadata", FormatMetadata(info.Metadata))
+	}
+	// the offset changes with every PATCH
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// patch appends the body at Upload-Offset. Without a checksum, what
+// arrived before a dropped connection is kept, so the client resumes
+// after it; with one, the chunk is kept whole or not at all.
+func (h *Handler) patch(w http.ResponseWriter, r *http.Request, id string) error {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != ContentType {
+		return problem.New(http.StatusUnsupportedMediaType, fmt.Sprintf("Content-Type must be %s", ContentType))
+	}
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		return problem.New(http.StatusBadRequest, "Upload-Offset must be a non-negative integer")
+	}
+	sum, err := parseChecksum(r.Header.Get("Upload-Checksum"))
+	if err != nil {
+		return err
+	}
+
+	unlock, err := h.lock(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	info, err := h.get(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	if offset != info.Offset {
+		return problem.New(http.StatusConflict, fmt.Sprintf("Upload-Offset is %d, the upload is at %d", offset, info.Offset)).
+			With("offset", info.Offset)
+	}
+
+	remaining := info.Length - info.Offset
+	if r.ContentLength > remaining {
+		return h.tooLong(remaining)
+	}
+
+	body := http.MaxBytesReader(w, r.Body, remaining)
+	if sum != nil {
+		body = io.NopCloser(io.TeeReader(body, sum.hash))
+	}
+	n, werr := h.Store.Write(r.Context(), id, offset, body)
+
+	if sum != nil && (werr != nil || !sum.ok()) {
+		// the chunk is undone even when the client is gone
+		if err := h.Store.Truncate(context.WithoutCancel(r.Context()), id, offset); err != nil {
+			return fmt.Errorf("tus: truncate %s: %w", id, err)
+		}
+		if werr == nil {
+			return problem.New(StatusChecksumMismatch, fmt.Sprintf("%s checksum does not match the chunk", sum.algorithm)).
+				WithType(problem.DefaultType, "Checksum Mismatch")
+		}
+	}
+
+	var size *http.MaxBytesError
+	switch {
+	case errors.Is(werr, ErrNotFound):
+		return problem.New(http.StatusNotFound, "")
+	case errors.As(werr, &size):
+		// without a checksum, the bytes up to the length stay
+		return h.tooLong(remaining)
+	case werr != nil:
+		return fmt.Errorf("tus: write %s after %d bytes: %w", id, n, werr)
+	}
+
+	w.Header().Set("Upload-Offset", strconv.FormatInt(offset+n, 10))
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// terminate answers DELETE. Later requests for the upload get a 404.
+func (h *Handler) terminate(w http.ResponseWriter, r *http.Request, id string) error {
+	unlock, err := h.lock(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := h.get(r.Context(), id); err != nil {
+		return err
+	}
+	if err := h.Store.Delete(r.Context(), id); err != nil {
+		return fmt.Errorf("tus: delete %s: %w", id, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (h *Handler) lock(ctx context.Context, id string) (func(), error) {
+	timeout := h.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	unlock, err := h.Store.Lock(ctx, id)
+	if err != nil {
+		return nil, problem.New(http.StatusLocked, "the upload is in use by another request")
+	}
+	return unlock, nil
+}
+
+func (h *Handler) get(ctx context.Context, id string) (Info, error) {
+	info, err := h.Store.Get(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return Info{}, problem.New(http.StatusNotFound, "")
+	}
+	if err != nil {
+		return Info{}, fmt.Errorf("tus: get %s: %w", id, err)
+	}
+	return info, nil
+}
+
+func (h *Handler) maxSize() int64 {
+	if h.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+	return h.MaxSize
+}
+
+func (h *Handler) tooLong(remaining int64) error {
+	return problem.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("the chunk is longer than the %d bytes the upload lacks", remaining)).
+		With("limit", remaining)
+}
+
+func notAllowed(w http.ResponseWriter, allow string) error {
+	w.Header().Set("Allow", allow)
+	return problem.New(http.StatusMethodNotAllowed, "")
+}
+
+// checksum is the Upload-Checksum of a chunk and the hash of what arrived.
+type checksum struct {
+	algorithm string
+	want      []byte
+	hash      hash.Hash
+}
+
+func (c *checksum) ok() bool {
+	return subtle.ConstantTimeCompare(c.hash.Sum(nil), c.want) == 1
+}
+
+// parseChecksum reads "<algorithm> <base64 digest>"; an empty header is
+// no checksum.
+func parseChecksum(header string) (*checksum, error) {
+	if header == "" {
+		return nil, nil
+	}
+	alg, enc, _ := strings.Cut(header, " ")
+	newHash, ok := checksums[alg]
+	if !ok {
+		return nil, problem.New(http.StatusBadRequest, fmt.Sprintf("checksum algorithm %q is not supported", alg)).
+			With("allowed", strings.Split(algorithms(), ","))
+	}
+	want, err := base64.StdEncoding.DecodeString(enc)
+	if err != nil {
+		return nil, problem.New(http.StatusBadRequest, "Upload-Checksum must be an algorithm and a base64 digest")
+	}
+	return &checksum{algorithm: alg, want: want, hash: newHash()}, nil
+}
+
+func algorithms() string {
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
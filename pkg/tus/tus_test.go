@@ -0,0 +1,456 @@
+package tus_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-mizu/go-fw/pkg/tus"
+)
+
+const maxSize = 8 << 20
+
+// serve starts a Handler at /files/ on a real listener, since some tests
+// cut connections in the middle of a request, and returns its creation URL.
+func serve(t *testing.T, lockTimeout time.Duration) (string, *tus.FileStore) {
+	t.Helper()
+
+	store, err := tus.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(&tus.Handler{Store: store, BasePath: "/files/", MaxSize: maxSize, LockTimeout: lockTimeout})
+	t.Cleanup(srv.Close)
+
+	return srv.URL + "/files/", store
+}
+
+func TestOptions(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	res := send(t, http.MethodOptions, endpoint, nil, "")
+	check(t, res, http.StatusNoContent, map[string]string{
+		"Tus-Version":            tus.Version,
+		"Tus-Extension":          tus.Extensions,
+		"Tus-Max-Size":           strconv.Itoa(maxSize),
+		"Tus-Checksum-Algorithm": "md5,sha1,sha256",
+	})
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	res := send(t, http.MethodPost, endpoint, map[string]string{"Tus-Resumable": "0.2.2", "Upload-Length": "1"}, "")
+	check(t, res, http.StatusPreconditionFailed, map[string]string{"Tus-Version": tus.Version})
+}
+
+func TestCreate(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	metadata := map[string]string{"filename": "report 2024.pdf", "draft": ""}
+	loc, err := (&tus.Client{Endpoint: endpoint}).Create(context.Background(), 1234, metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := send(t, http.MethodHead, loc, nil, "")
+	check(t, res, http.StatusOK, map[string]string{
+		"Upload-Offset":   "0",
+		"Upload-Length":   "1234",
+		"Upload-Metadata": tus.FormatMetadata(metadata),
+		"Cache-Control":   "no-store",
+	})
+
+	tests := []struct {
+		name    string
+		method  string
+		headers map[string]string
+		status  int
+	}{
+		{"over the cap", http.MethodPost, map[string]string{"Upload-Length": strconv.Itoa(maxSize + 1)}, http.StatusRequestEntityTooLarge},
+		{"no length", http.MethodPost, nil, http.StatusBadRequest},
+		{"negative length", http.MethodPost, map[string]string{"Upload-Length": "-1"}, http.StatusBadRequest},
+		{"metadata not base64", http.MethodPost, map[string]string{"Upload-Length": "1", "Upload-Metadata": "filename !"}, http.StatusBadRequest},
+		{"GET", http.MethodGet, nil, http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := send(t, tt.method, endpoint, tt.headers, "")
+			check(t, res, tt.status, nil)
+		})
+	}
+}
+
+// The offset moves with every chunk, and a chunk must start where the
+// upload is.
+func TestPatch(t *testing.T) {
+	endpoint, store := serve(t, 0)
+	loc := create(t, endpoint, 10)
+
+	res := send(t, http.MethodPatch, loc, patch(0, ""), "hello")
+	check(t, res, http.StatusNoContent, map[string]string{"Upload-Offset": "5"})
+
+	for _, offset := range []int{0, 3, 10} {
+		res = send(t, http.MethodPatch, loc, patch(offset, ""), "world")
+		check(t, res, http.StatusConflict, nil)
+	}
+
+	res = send(t, http.MethodPatch, loc, patch(5, ""), "world")
+	check(t, res, http.StatusNoContent, map[string]string{"Upload-Offset": "10"})
+	checkStored(t, store, path.Base(loc), []byte("helloworld"))
+
+	res = send(t, http.MethodPatch, loc, patch(10, ""), "!")
+	check(t, res, http.StatusRequestEntityTooLarge, nil)
+}
+
+func TestPatchRefused(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	tests := []struct {
+		name    string
+		length  int64
+		headers map[string]string
+		status  int
+	}{
+		{"wrong content type", 10, map[string]string{"Content-Type": "application/octet-stream"}, http.StatusUnsupportedMediaType},
+		{"no offset", 10, map[string]string{"Upload-Offset": ""}, http.StatusBadRequest},
+		{"negative offset", 10, map[string]string{"Upload-Offset": "-1"}, http.StatusBadRequest},
+		{"chunk past the length", 4, nil, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := create(t, endpoint, tt.length)
+			h := patch(0, "")
+			for k, v := range tt.headers {
+				h[k] = v
+			}
+			res := send(t, http.MethodPatch, loc, h, "hello")
+			check(t, res, tt.status, nil)
+
+			res = send(t, http.MethodHead, loc, nil, "")
+			check(t, res, http.StatusOK, map[string]string{"Upload-Offset": "0"})
+		})
+	}
+}
+
+// A chunk with a checksum is kept whole or not at all.
+func TestChecksum(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	md5sum := md5.Sum([]byte("hello"))
+	sha1sum := sha1.Sum([]byte("hello"))
+	sha256sum := sha256.Sum256([]byte("hello"))
+	other := sha1.Sum([]byte("world"))
+
+	tests := []struct {
+		name     string
+		checksum string
+		status   int
+		offset   string
+	}{
+		{"md5", "md5 " + base64.StdEncoding.EncodeToString(md5sum[:]), http.StatusNoContent, "5"},
+		{"sha1", "sha1 " + base64.StdEncoding.EncodeToString(sha1sum[:]), http.StatusNoContent, "5"},
+		{"sha256", "sha256 " + base64.StdEncoding.EncodeToString(sha256sum[:]), http.StatusNoContent, "5"},
+		{"mismatch", "sha1 " + base64.StdEncoding.EncodeToString(other[:]), tus.StatusChecksumMismatch, "0"},
+		{"unsupported algorithm", "crc32 AAAAAA==", http.StatusBadRequest, "0"},
+		{"digest not base64", "sha1 !", http.StatusBadRequest, "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := create(t, endpoint, 10)
+			res := send(t, http.MethodPatch, loc, patch(0, tt.checksum), "hello")
+			check(t, res, tt.status, nil)
+
+			res = send(t, http.MethodHead, loc, nil, "")
+			check(t, res, http.StatusOK, map[string]string{"Upload-Offset": tt.offset})
+		})
+	}
+}
+
+func TestResume(t *testing.T) {
+	endpoint, store := serve(t, 0)
+	ctx := context.Background()
+
+	data := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(data)
+
+	// the connection goes away in the third chunk of four
+	cut := &cutter{}
+	cut.budget.Store(600 << 10)
+	c := &tus.Client{Endpoint: endpoint, ChunkSize: 256 << 10, HTTP: cut.client()}
+
+	loc, err := c.Upload(ctx, bytes.NewReader(data), int64(len(data)), map[string]string{"filename": "data.bin"})
+	if loc == "" {
+		t.Fatalf("create: %v", err)
+	}
+	if !errors.Is(err, errCut) {
+		t.Fatalf("upload err = %v, want the connection cut", err)
+	}
+
+	// a fresh connection, as after a network change
+	c = &tus.Client{Endpoint: endpoint, ChunkSize: 256 << 10, Checksum: true}
+	offset, err := c.Offset(ctx, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset < 512<<10 || offset >= int64(len(data)) {
+		t.Fatalf("offset after the cut = %d, want the first two chunks and no more than was sent", offset)
+	}
+
+	if err := c.Resume(ctx, loc, bytes.NewReader(data), int64(len(data))); err != nil {
+		t.Fatal(err)
+	}
+	checkStored(t, store, path.Base(loc), data)
+}
+
+// A HEAD that arrives while a PATCH holds the upload waits for it, so the
+// offset it reports is not about to change.
+func TestLock(t *testing.T) {
+	endpoint, store := serve(t, 0)
+	loc := create(t, endpoint, 10)
+	id := path.Base(loc)
+
+	// the PATCH holds the upload until its body ends; the cleanup ends it
+	// before the server closes, should the test fail halfway
+	body, w := io.Pipe()
+	t.Cleanup(func() { w.Close() })
+	patched := make(chan *http.Response, 1)
+	go func() {
+		patched <- sendBody(t, http.MethodPatch, loc, patch(0, ""), body)
+	}()
+
+	w.Write([]byte("hel"))
+	for {
+		info, err := store.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Offset == 3 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	headed := make(chan *http.Response, 1)
+	go func() {
+		headed <- send(t, http.MethodHead, loc, nil, "")
+	}()
+	select {
+	case res := <-headed:
+		t.Fatalf("HEAD answered %s while a PATCH held the upload", res.Header.Get("Upload-Offset"))
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.Write([]byte("lo"))
+	w.Close()
+	check(t, <-patched, http.StatusNoContent, map[string]string{"Upload-Offset": "5"})
+	check(t, <-headed, http.StatusOK, map[string]string{"Upload-Offset": "5"})
+}
+
+// A request that cannot get the upload within LockTimeout is refused.
+func TestLockTimeout(t *testing.T) {
+	endpoint, store := serve(t, 10*time.Millisecond)
+	loc := create(t, endpoint, 10)
+
+	unlock, err := store.Lock(context.Background(), path.Base(loc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, method := range []string{http.MethodHead, http.MethodPatch, http.MethodDelete} {
+		res := send(t, method, loc, patch(0, ""), "hello")
+		check(t, res, http.StatusLocked, nil)
+	}
+	unlock()
+
+	res := send(t, http.MethodHead, loc, nil, "")
+	check(t, res, http.StatusOK, map[string]string{"Upload-Offset": "0"})
+}
+
+func TestTerminate(t *testing.T) {
+	endpoint, store := serve(t, 0)
+	loc := create(t, endpoint, 10)
+
+	res := send(t, http.MethodDelete, loc, nil, "")
+	check(t, res, http.StatusNoContent, nil)
+
+	res = send(t, http.MethodHead, loc, nil, "")
+	check(t, res, http.StatusNotFound, nil)
+	if _, err := store.Get(context.Background(), path.Base(loc)); !errors.Is(err, tus.ErrNotFound) {
+		t.Errorf("store still has the upload (%v)", err)
+	}
+
+	res = send(t, http.MethodDelete, loc, nil, "")
+	check(t, res, http.StatusNotFound, nil)
+}
+
+func TestMethodOverride(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	t.Run("POST", func(t *testing.T) {
+		loc := create(t, endpoint, 10)
+		h := patch(0, "")
+		h["X-HTTP-Method-Override"] = http.MethodPatch
+		res := send(t, http.MethodPost, loc, h, "hello")
+		check(t, res, http.StatusNoContent, map[string]string{"Upload-Offset": "5"})
+	})
+
+	t.Run("only on POST", func(t *testing.T) {
+		loc := create(t, endpoint, 10)
+		res := send(t, http.MethodGet, loc, map[string]string{"X-HTTP-Method-Override": http.MethodDelete}, "")
+		check(t, res, http.StatusMethodNotAllowed, nil)
+
+		res = send(t, http.MethodHead, loc, nil, "")
+		check(t, res, http.StatusOK, map[string]string{"Upload-Offset": "0"})
+	})
+}
+
+func TestUnknownUpload(t *testing.T) {
+	endpoint, _ := serve(t, 0)
+
+	for _, id := range []string{strings.Repeat("0", 32), "not-an-id", "a/b"} {
+		res := send(t, http.MethodHead, endpoint+id, nil, "")
+		check(t, res, http.StatusNotFound, nil)
+
+		res = send(t, http.MethodPatch, endpoint+id, patch(0, ""), "hello")
+		check(t, res, http.StatusNotFound, nil)
+	}
+}
+
+// send makes a request with Tus-Resumable set, unless headers set it.
+func send(t *testing.T, method, url string, headers map[string]string, body string) *http.Response {
+	t.Helper()
+	return sendBody(t, method, url, headers, strings.NewReader(body))
+}
+
+func sendBody(t *testing.T, method, url string, headers map[string]string, body io.Reader) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	req.Header.Set("Tus-Resumable", tus.Version)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return nil
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+	return res
+}
+
+// patch returns the headers of a PATCH at offset, with an Upload-Checksum
+// unless checksum is empty.
+func patch(offset int, checksum string) map[string]string {
+	h := map[string]string{"Content-Type": tus.ContentType, "Upload-Offset": strconv.Itoa(offset)}
+	if checksum != "" {
+		h["Upload-Checksum"] = checksum
+	}
+	return h
+}
+
+func create(t *testing.T, endpoint string, length int64) string {
+	t.Helper()
+
+	loc, err := (&tus.Client{Endpoint: endpoint}).Create(context.Background(), length, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+// check compares the status and the headers of res; every response has
+// Tus-Resumable.
+func check(t *testing.T, res *http.Response, status int, headers map[string]string) {
+	t.Helper()
+
+	if res == nil {
+		t.Fatal("no response")
+	}
+	if res.StatusCode != status {
+		t.Errorf("%s %s: status = %d, want %d", res.Request.Method, res.Request.URL.Path, res.StatusCode, status)
+	}
+	if got := res.Header.Get("Tus-Resumable"); got != tus.Version {
+		t.Errorf("%s %s: Tus-Resumable = %q, want %q", res.Request.Method, res.Request.URL.Path, got, tus.Version)
+	}
+	for k, want := range headers {
+		if got := res.Header.Get(k); got != want {
+			t.Errorf("%s %s: %s = %q, want %q", res.Request.Method, res.Request.URL.Path, k, got, want)
+		}
+	}
+}
+
+func checkStored(t *testing.T, store tus.Store, id string, want []byte) {
+	t.Helper()
+
+	rc, err := store.Open(context.Background(), id)
+	if err != nil {
+		t.Fatalf("open %s: %v", id, err)
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("stored %s differs from the upload (%d bytes, %v)", id, len(got), err)
+	}
+}
+
+var errCut = errors.New("connection cut")
+
+// cutter closes its connections once budget bytes have been written
+// through them, counted together, as a client that loses its network does.
+type cutter struct {
+	budget atomic.Int64
+}
+
+func (c *cutter) client() *http.Client {
+	return &http.Client{Transport: &http.Transport{DialContext: c.dial}}
+}
+
+func (c *cutter) dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &cutConn{Conn: conn, c: c}, nil
+}
+
+type cutConn struct {
+	net.Conn
+	c *cutter
+}
+
+func (cc *cutConn) Write(p []byte) (int, error) {
+	left := cc.c.budget.Add(-int64(len(p)))
+	if left >= 0 {
+		return cc.Conn.Write(p)
+	}
+
+	n := 0
+	if keep := int64(len(p)) + left; keep > 0 {
+		n, _ = cc.Conn.Write(p[:keep])
+	}
+	cc.Conn.Close()
+	return n, errCut
+}
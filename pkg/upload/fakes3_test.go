@@ -0,0 +1,296 @@
+package upload_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-mizu/go-fw/pkg/upload/internal/sigv4"
+)
+
+// The credentials and region fakeS3 accepts.
+const (
+	s3AccessKey = "test-access-key"
+	s3SecretKey = "test-secret-key"
+	s3Region    = "us-east-1"
+)
+
+// fakeS3 is a stand-in for an S3-compatible service, for testing the S3
+// store without network access. It keeps objects in memory, speaks the
+// path-style PUT, GET, HEAD and DELETE object calls, CopyObject and the
+// multipart upload calls, and checks the Signature Version 4 of every
+// request against fixed credentials. Buckets need not be created first.
+type fakeS3 struct {
+	*httptest.Server
+
+	mu      sync.RWMutex
+	objects map[string]s3Object  // by "bucket/key"
+	uploads map[string]*s3Upload // by upload id
+	nextID  int
+}
+
+type s3Object struct {
+	data        []byte
+	contentType string
+}
+
+// s3Upload is an upload in progress.
+type s3Upload struct {
+	name        string
+	contentType string
+	parts       map[int][]byte
+}
+
+// minPartSize is the smallest part S3 takes, but for the last.
+const minPartSize = 5 << 20
+
+// newFakeS3 starts a server; Close stops it.
+func newFakeS3() *fakeS3 {
+	s := &fakeS3{objects: map[string]s3Object{}, uploads: map[string]*s3Upload{}}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// dsn returns the upload.Open address of bucket on s.
+func (s *fakeS3) dsn(bucket string) string {
+	host := strings.TrimPrefix(s.URL, "http://")
+	return "s3+http://" + s3AccessKey + ":" + s3SecretKey + "@" + host + "/" + bucket + "?region=" + s3Region
+}
+
+// count returns the number of objects in all buckets.
+func (s *fakeS3) count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.objects)
+}
+
+// openUploads returns the number of multipart uploads neither completed
+// nor aborted.
+func (s *fakeS3) openUploads() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.uploads)
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	creds := sigv4.Credentials{AccessKey: s3AccessKey, SecretKey: s3SecretKey, Region: s3Region, Service: "s3"}
+	if err := sigv4.Verify(r, creds); err != nil {
+		s3Error(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket == "" || key == "" {
+		s3Error(w, http.StatusBadRequest, "InvalidRequest", "only object requests are supported")
+		return
+	}
+	name := bucket + "/" + key
+	q := r.URL.Query()
+
+	switch {
+	case r.Method == http.MethodPost && q.Has("uploads"):
+		s.createMultipart(w, r, name)
+	case r.Method == http.MethodPut && q.Has("uploadId"):
+		s.uploadPart(w, r, q)
+	case r.Method == http.MethodPost && q.Has("uploadId"):
+		s.completeMultipart(w, r, name, q.Get("uploadId"))
+	case r.Method == http.MethodDelete && q.Has("uploadId"):
+		s.mu.Lock()
+		delete(s.uploads, q.Get("uploadId"))
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, name)
+	default:
+		s.serveObject(w, r, name)
+	}
+}
+
+// serveObject serves the calls on a single object.
+func (s *fakeS3) serveObject(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			s3Error(w, http.StatusLengthRequired, "MissingContentLength", "Content-Length is required")
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			s3Error(w, http.StatusBadRequest, "IncompleteBody", "body does not match Content-Length")
+			return
+		}
+		s.mu.Lock()
+		s.objects[name] = s3Object{data: data, contentType: r.Header.Get("Content-Type")}
+		s.mu.Unlock()
+
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		obj, ok := s.objects[name]
+		s.mu.RUnlock()
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		if r.Method == http.MethodGet {
+			w.Write(obj.data)
+		}
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.objects, name)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method+" is not supported")
+	}
+}
+
+func (s *fakeS3) createMultipart(w http.ResponseWriter, r *http.Request, name string) {
+	s.mu.Lock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	s.uploads[id] = &s3Upload{name: name, contentType: r.Header.Get("Content-Type"), parts: map[int][]byte{}}
+	s.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(name, "/")
+	writeXML(w, struct {
+		XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+		Bucket   string
+		Key      string
+		UploadId string
+	}{Bucket: bucket, Key: key, UploadId: id})
+}
+
+func (s *fakeS3) uploadPart(w http.ResponseWriter, r *http.Request, q url.Values) {
+	n, err := strconv.Atoi(q.Get("partNumber"))
+	if err != nil || n < 1 || n > 10000 {
+		s3Error(w, http.StatusBadRequest, "InvalidArgument", "partNumber must be between 1 and 10000")
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		s3Error(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	up, ok := s.uploads[q.Get("uploadId")]
+	if ok {
+		up.parts[n] = data
+	}
+	s.mu.Unlock()
+	if !ok {
+		s3Error(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+	w.Header().Set("ETag", etag(data))
+}
+
+func (s *fakeS3) completeMultipart(w http.ResponseWriter, r *http.Request, name, id string) {
+	var req struct {
+		Part []struct {
+			PartNumber int
+			ETag       string
+		}
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Part) == 0 {
+		s3Error(w, http.StatusBadRequest, "MalformedXML", "the parts list is malformed")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	up, ok := s.uploads[id]
+	if !ok || up.name != name {
+		s3Error(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+		return
+	}
+
+	var obj bytes.Buffer
+	for i, p := range req.Part {
+		data, ok := up.parts[p.PartNumber]
+		if !ok || p.ETag != etag(data) || i > 0 && p.PartNumber <= req.Part[i-1].PartNumber {
+			s3Error(w, http.StatusBadRequest, "InvalidPart", "part "+strconv.Itoa(p.PartNumber)+" is missing or out of order")
+			return
+		}
+		if i < len(req.Part)-1 && len(data) < minPartSize {
+			s3Error(w, http.StatusBadRequest, "EntityTooSmall", "part "+strconv.Itoa(p.PartNumber)+" is smaller than 5 MiB")
+			return
+		}
+		obj.Write(data)
+	}
+
+	s.objects[name] = s3Object{data: obj.Bytes(), contentType: up.contentType}
+	delete(s.uploads, id)
+
+	// S3 sends the 200 status before the work is done, so errors from here
+	// on would come in the body; the stand-in has none
+	bucket, key, _ := strings.Cut(name, "/")
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: bucket, Key: key, ETag: etag(obj.Bytes())})
+}
+
+func (s *fakeS3) copyObject(w http.ResponseWriter, r *http.Request, name string) {
+	src, err := url.PathUnescape(strings.TrimPrefix(r.Header.Get("X-Amz-Copy-Source"), "/"))
+	if err != nil {
+		s3Error(w, http.StatusBadRequest, "InvalidArgument", "malformed X-Amz-Copy-Source")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj, ok := s.objects[src]
+	if !ok {
+		s3Error(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	if r.Header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		obj.contentType = r.Header.Get("Content-Type")
+	}
+	s.objects[name] = obj
+
+	writeXML(w, struct {
+		XMLName xml.Name `xml:"CopyObjectResult"`
+		ETag    string
+	}{ETag: etag(obj.data)})
+}
+
+// etag is the quoted MD5 S3 gives a part or a single-part object.
+func etag(data []byte) string {
+	sum := md5.Sum(data)
+	return `"` + hex.EncodeToString(sum[:]) + `"`
+}
+
+func writeXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(v)
+}
+
+// fail writes an S3 error document.
+func s3Error(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: message})
+}
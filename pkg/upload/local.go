@@ -0,0 +1,135 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Local is an ObjectStore in a directory. A key is stored as
+// <dir>/<first two digits>/<key>, so no directory grows too large, and a
+// file is written under a temporary name and renamed into place, so a
+// failed upload never leaves a partial object behind.
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a store in dir, creating it when it does not exist.
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) path(key string) string {
+	return filepath.Join(l.dir, key[:2], key)
+}
+
+func (l *Local) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	dst := l.path(key)
+	// the same key is the same content
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, body)
+	if err == nil && n != size {
+		err = fmt.Errorf("upload: %s: wrote %d of %d bytes", key, n, size)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (l *Local) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	err := os.Remove(l.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Begin writes to a temporary file in the store directory, which Commit
+// renames into place.
+func (l *Local) Begin(ctx context.Context) (Pending, error) {
+	tmp, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return nil, err
+	}
+	return &localPending{l: l, f: tmp}, nil
+}
+
+type localPending struct {
+	l *Local
+	f *os.File
+}
+
+func (p *localPending) Write(b []byte) (int, error) {
+	return p.f.Write(b)
+}
+
+func (p *localPending) Commit(key, contentType string) error {
+	defer os.Remove(p.f.Name())
+
+	if err := checkKey(key); err != nil {
+		p.f.Close()
+		return err
+	}
+	if err := p.f.Close(); err != nil {
+		return err
+	}
+
+	dst := p.l.path(key)
+	// the same key is the same content
+	if _, err := os.Stat(dst); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return err
+	}
+	return os.Rename(p.f.Name(), dst)
+}
+
+func (p *localPending) Abort() error {
+	p.f.Close()
+	return os.Remove(p.f.Name())
+}
@@ -0,0 +1,95 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// Memory is an ObjectStore in a map, for tests and for running the
+// examples without a disk.
+type Memory struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+// NewMemory returns an empty store.
+func NewMemory() *Memory {
+	return &Memory{objects: map[string][]byte{}}
+}
+
+func (m *Memory) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	data, err := readExactly(body, size)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.objects[key] = data
+	return nil
+}
+
+func (m *Memory) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *Memory) Begin(ctx context.Context) (Pending, error) {
+	return &memoryPending{m: m}, nil
+}
+
+type memoryPending struct {
+	m   *Memory
+	buf bytes.Buffer
+}
+
+func (p *memoryPending) Write(b []byte) (int, error) {
+	return p.buf.Write(b)
+}
+
+func (p *memoryPending) Commit(key, contentType string) error {
+	return p.m.Put(context.Background(), key, &p.buf, int64(p.buf.Len()), contentType)
+}
+
+func (p *memoryPending) Abort() error {
+	p.buf = bytes.Buffer{}
+	return nil
+}
+
+// Len returns the number of objects, which tests use to see that an
+// upload stored nothing.
+func (m *Memory) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.objects)
+}
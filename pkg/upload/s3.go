@@ -0,0 +1,135 @@
+package upload
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-mizu/go-fw/pkg/upload/internal/sigv4"
+)
+
+// S3 is an ObjectStore in a bucket of an S3-compatible service, such as
+// AWS S3 or MinIO, spoken to over plain net/http. Requests are path-style,
+// Endpoint/Bucket/key, and signed with Signature Version 4. It is a Sink,
+// through multipart uploads. Its tests run against a stand-in server,
+// fakes3_test.go.
+type S3 struct {
+	Endpoint  string // scheme and host, such as https://s3.eu-west-1.amazonaws.com
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+
+	// Client sends the requests; http.DefaultClient when nil.
+	Client *http.Client
+}
+
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	req, err := s.request(ctx, http.MethodPut, key, io.NopCloser(body))
+	if err != nil {
+		return err
+	}
+	// without a length the body would be sent chunked, which S3 rejects
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+
+	res, err := s.do(req, sigv4.UnsignedPayload)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.do(req, sigv4.EmptyPayload)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	return s.remove(ctx, key)
+}
+
+// remove deletes key without checking it, for the temporary objects of
+// streamed uploads.
+func (s *S3) remove(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.do(req, sigv4.EmptyPayload)
+	if err == ErrNotFound {
+		// S3 answers 204 for a missing key, some compatible services 404
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (s *S3) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u := strings.TrimRight(s.Endpoint, "/") + "/" + url.PathEscape(s.Bucket) + "/" + url.PathEscape(key)
+	return http.NewRequestWithContext(ctx, method, u, body)
+}
+
+// do signs and sends req. A response other than 2xx is closed and returned
+// as an error; 404 is ErrNotFound.
+func (s *S3) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	sigv4.Sign(req, sigv4.Credentials{
+		AccessKey: s.AccessKey,
+		SecretKey: s.SecretKey,
+		Region:    s.Region,
+		Service:   "s3",
+	}, payloadHash, time.Now())
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode/100 == 2 {
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	// the error body is <Error><Code>...</Code><Message>...</Message></Error>
+	var e struct {
+		Code    string
+		Message string
+	}
+	xml.NewDecoder(io.LimitReader(res.Body, 64<<10)).Decode(&e)
+	return nil, fmt.Errorf("upload: s3 %s %s: %s %s: %s", req.Method, req.URL.Path, res.Status, e.Code, e.Message)
+}
@@ -0,0 +1,138 @@
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+// pngOf returns a PNG of n bytes, distinct for each seed.
+func pngOf(n int, seed byte) []byte {
+	data := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), bytes.Repeat([]byte{seed}, n)...)
+	return data[:n]
+}
+
+func formOf(t *testing.T, data []byte) (string, []byte) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	w, err := mw.CreateFormFile("file", "big.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	mw.Close()
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+// Streaming into S3 goes through a multipart upload once a file is larger
+// than one part, and leaves neither temporary objects nor open uploads.
+func TestS3Stream(t *testing.T) {
+	srv := newFakeS3()
+	defer srv.Close()
+
+	store, err := upload.Open(srv.dsn("uploads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads := upload.Uploader{Store: store, MaxFile: 16 << 20, MaxRequest: 32 << 20}
+
+	tests := []struct {
+		name   string
+		size   int
+		status int // 0 for success
+	}{
+		{name: "one part", size: 1 << 20},
+		{name: "exactly one part", size: 5 << 20},
+		{name: "three parts", size: 12<<20 + 7},
+		{name: "over the cap after three parts", size: 16<<20 + 1, status: http.StatusRequestEntityTooLarge},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := srv.count()
+			data := pngOf(tt.size, byte(i))
+			ct, body := formOf(t, data)
+
+			files, err := uploads.ReadStream(context.Background(), ct, bytes.NewReader(body), "file")
+			if tt.status != 0 {
+				var p *problem.Details
+				if !errors.As(err, &p) || p.Status != tt.status {
+					t.Fatalf("ReadStream error = %v, want a %d problem", err, tt.status)
+				}
+				if srv.count() != before {
+					t.Errorf("a rejected upload left %d objects", srv.count()-before)
+				}
+			} else {
+				if err != nil {
+					t.Fatal(err)
+				}
+				sum := sha256.Sum256(data)
+				if want := upload.Key(sum[:], ".png"); len(files) != 1 || files[0].Key != want {
+					t.Fatalf("files = %+v, want one under %s", files, want)
+				}
+				rc, err := store.Open(context.Background(), files[0].Key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, _ := io.ReadAll(rc)
+				rc.Close()
+				if !bytes.Equal(got, data) {
+					t.Errorf("stored %d bytes differ from the %d uploaded", len(got), len(data))
+				}
+				if srv.count() != before+1 {
+					t.Errorf("%d objects after the upload, want %d: the temporary one is left", srv.count(), before+1)
+				}
+			}
+			if n := srv.openUploads(); n != 0 {
+				t.Errorf("%d multipart uploads left open", n)
+			}
+		})
+	}
+}
+
+// A request cancelled in the middle of a multipart upload aborts it.
+func TestS3StreamCancel(t *testing.T) {
+	srv := newFakeS3()
+	defer srv.Close()
+
+	store, err := upload.Open(srv.dsn("uploads"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploads := upload.Uploader{Store: store, MaxFile: 16 << 20, MaxRequest: 32 << 20}
+
+	ct, body := formOf(t, pngOf(12<<20, 1))
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &cancelAfter{r: bytes.NewReader(body), n: 7 << 20, cancel: cancel}
+
+	if _, err := uploads.ReadStream(ctx, ct, r, "file"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("ReadStream error = %v, want context.Canceled", err)
+	}
+	if srv.count() != 0 || srv.openUploads() != 0 {
+		t.Errorf("%d objects and %d open uploads after a cancelled upload, want none", srv.count(), srv.openUploads())
+	}
+}
+
+// cancelAfter cancels its context once n bytes have been read.
+type cancelAfter struct {
+	r      io.Reader
+	n      int
+	cancel context.CancelFunc
+}
+
+func (c *cancelAfter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if c.n -= n; c.n <= 0 {
+		c.cancel()
+	}
+	return n, err
+}
@@ -0,0 +1,229 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-mizu/go-fw/pkg/upload/internal/sigv4"
+)
+
+// s3PartSize is the size of the parts of a streamed object, the smallest
+// S3 accepts for any part but the last.
+const s3PartSize = 5 << 20
+
+// Begin starts a streamed object. Up to one part is buffered, and a file
+// that fits is put under its key by Commit. A larger one is sent as a
+// multipart upload to a temporary key, because the key of an upload is
+// named before its first part; Commit completes it, copies it to the
+// digest key and deletes the temporary object, and Abort aborts it. A
+// bucket lifecycle rule that aborts incomplete multipart uploads cleans up
+// after a server that stopped in between.
+//
+// A copy holds at most 5 GiB, which MaxFile must not exceed.
+func (s *S3) Begin(ctx context.Context) (Pending, error) {
+	var id [16]byte
+	rand.Read(id[:])
+	return &s3Pending{s: s, ctx: ctx, tmp: ".upload-" + hex.EncodeToString(id[:])}, nil
+}
+
+type s3Pending struct {
+	s   *S3
+	ctx context.Context
+	tmp string // key of the multipart upload
+
+	uploadID string // empty until the first part is full
+	parts    []s3Part
+	buf      bytes.Buffer
+}
+
+type s3Part struct {
+	PartNumber int
+	ETag       string
+}
+
+func (p *s3Pending) Write(b []byte) (int, error) {
+	n := 0
+	for len(b) > 0 {
+		k := min(len(b), s3PartSize-p.buf.Len())
+		p.buf.Write(b[:k])
+		n += k
+		b = b[k:]
+
+		if p.buf.Len() == s3PartSize {
+			if err := p.flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// flush sends the buffer as the next part, starting the multipart upload
+// with the first one.
+func (p *s3Pending) flush() error {
+	if p.uploadID == "" {
+		id, err := p.s.createMultipart(p.ctx, p.tmp)
+		if err != nil {
+			return err
+		}
+		p.uploadID = id
+	}
+
+	n := len(p.parts) + 1
+	etag, err := p.s.uploadPart(p.ctx, p.tmp, p.uploadID, n, p.buf.Bytes())
+	if err != nil {
+		return err
+	}
+	p.parts = append(p.parts, s3Part{PartNumber: n, ETag: etag})
+	p.buf.Reset()
+	return nil
+}
+
+func (p *s3Pending) Commit(key, contentType string) error {
+	if err := checkKey(key); err != nil {
+		p.Abort()
+		return err
+	}
+	if p.uploadID == "" {
+		return p.s.Put(p.ctx, key, &p.buf, int64(p.buf.Len()), contentType)
+	}
+
+	if p.buf.Len() > 0 {
+		if err := p.flush(); err != nil {
+			p.Abort()
+			return err
+		}
+	}
+	if err := p.s.completeMultipart(p.ctx, p.tmp, p.uploadID, p.parts); err != nil {
+		p.Abort()
+		return err
+	}
+
+	// the temporary object goes whether or not the copy succeeds
+	defer p.s.remove(context.WithoutCancel(p.ctx), p.tmp)
+	return p.s.copyObject(p.ctx, p.tmp, key, contentType)
+}
+
+func (p *s3Pending) Abort() error {
+	p.buf = bytes.Buffer{}
+	if p.uploadID == "" {
+		return nil
+	}
+
+	// an upload is often aborted because its request was cancelled
+	ctx := context.WithoutCancel(p.ctx)
+	req, err := p.s.request(ctx, http.MethodDelete, p.tmp, nil)
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = "uploadId=" + url.QueryEscape(p.uploadID)
+
+	res, err := p.s.do(req, sigv4.EmptyPayload)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// createMultipart starts a multipart upload to key and returns its id.
+func (s *S3) createMultipart(ctx context.Context, key string) (string, error) {
+	req, err := s.request(ctx, http.MethodPost, key, nil)
+	if err != nil {
+		return "", err
+	}
+	req.URL.RawQuery = "uploads"
+
+	var out struct{ UploadId string }
+	if err := s.doXML(req, sigv4.EmptyPayload, &out); err != nil {
+		return "", err
+	}
+	if out.UploadId == "" {
+		return "", fmt.Errorf("upload: s3 POST %s: no UploadId in the response", req.URL.Path)
+	}
+	return out.UploadId, nil
+}
+
+// uploadPart sends part n of an upload and returns its ETag.
+func (s *S3) uploadPart(ctx context.Context, key, uploadID string, n int, data []byte) (string, error) {
+	req, err := s.request(ctx, http.MethodPut, key, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.URL.RawQuery = url.Values{"partNumber": {fmt.Sprint(n)}, "uploadId": {uploadID}}.Encode()
+
+	res, err := s.do(req, sigv4.UnsignedPayload)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	return res.Header.Get("ETag"), nil
+}
+
+func (s *S3) completeMultipart(ctx context.Context, key, uploadID string, parts []s3Part) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Part    []s3Part
+	}{Part: parts})
+	if err != nil {
+		return err
+	}
+
+	req, err := s.request(ctx, http.MethodPost, key, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.URL.RawQuery = "uploadId=" + url.QueryEscape(uploadID)
+	req.Header.Set("Content-Type", "application/xml")
+
+	return s.doXML(req, sigv4.UnsignedPayload, nil)
+}
+
+// copyObject copies src to dst within the bucket, setting its type.
+func (s *S3) copyObject(ctx context.Context, src, dst, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, dst, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Amz-Copy-Source", "/"+url.PathEscape(s.Bucket)+"/"+url.PathEscape(src))
+	req.Header.Set("X-Amz-Metadata-Directive", "REPLACE")
+	req.Header.Set("Content-Type", contentType)
+
+	return s.doXML(req, sigv4.EmptyPayload, nil)
+}
+
+// doXML is do for the calls that answer with an XML document, which for
+// CompleteMultipartUpload and CopyObject can be an error despite the 200
+// status. The document is decoded into out when out is not nil.
+func (s *S3) doXML(req *http.Request, payloadHash string, out any) error {
+	res, err := s.do(req, payloadHash)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+
+	var e struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := xml.Unmarshal(data, &e); err == nil && e.XMLName.Local == "Error" {
+		return fmt.Errorf("upload: s3 %s %s: %s: %s", req.Method, req.URL.Path, e.Code, e.Message)
+	}
+
+	if out == nil {
+		return nil
+	}
+	return xml.Unmarshal(data, out)
+}
@@ -0,0 +1,90 @@
+package upload
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sniffLen is how many bytes http.DetectContentType looks at.
+const sniffLen = 512
+
+// DefaultAllow is the allow-list of an Uploader without one: images, PDF
+// and plain text. HTML and SVG are left out on purpose, since a browser
+// runs their scripts when they are served back.
+var DefaultAllow = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf", "text/plain"}
+
+// extensions are the key extensions of the types http.DetectContentType
+// reports; any other allowed type is stored without one.
+var extensions = map[string]string{
+	"image/png":       ".png",
+	"image/jpeg":      ".jpg",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"image/bmp":       ".bmp",
+	"application/pdf": ".pdf",
+	"application/zip": ".zip",
+	"text/plain":      ".txt",
+	"audio/mpeg":      ".mp3",
+	"video/mp4":       ".mp4",
+}
+
+func (u Uploader) allow() []string {
+	if u.Allow == nil {
+		return DefaultAllow
+	}
+	return u.Allow
+}
+
+// sniff returns the media type of the content that starts with head, its
+// extension, and whether the allow-list has it. Parameters such as the
+// charset of text/plain are dropped.
+func (u Uploader) sniff(head []byte) (ctype, ext string, ok bool) {
+	ctype, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	for _, t := range u.allow() {
+		if t == ctype {
+			return ctype, extensions[ctype], true
+		}
+	}
+	return ctype, "", false
+}
+
+// maxName is the longest name SanitizeFilename returns, in bytes, which is
+// the limit of most file systems.
+const maxName = 255
+
+// SanitizeFilename returns name reduced to something safe to show or to
+// send back in a Content-Disposition header: the last element of a path
+// with either separator, letters, digits and "._-" only, no leading or
+// trailing dots, and at most 255 bytes. A name with nothing left becomes
+// "file". The stores never use it as a path; keys are content addresses.
+func SanitizeFilename(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == utf8.RuneError:
+			b.WriteByte('_')
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	name = b.String()
+	for len(name) > maxName {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	name = strings.Trim(name, ".")
+	if strings.Trim(name, "_") == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,59 @@
+package upload
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "photo.png", "photo.png"},
+		{"letters of any script", "résumé-2024_v2.pdf", "résumé-2024_v2.pdf"},
+		{"spaces and brackets", "my file (1).png", "my_file__1_.png"},
+
+		{"path traversal", "../../etc/passwd", "passwd"},
+		{"absolute path", "/var/www/index.html", "index.html"},
+		{"Windows separators", `..\..\Windows\System32\cmd.exe`, "cmd.exe"},
+		{"drive letter", `C:\Users\ann\report.docx`, "report.docx"},
+		{"mixed separators", `a/b\c/d.txt`, "d.txt"},
+
+		{"NUL byte", "shell.php\x00.png", "shell.php_.png"},
+		{"control characters", "a\r\nb.txt", "a__b.txt"},
+		{"invalid UTF-8", "\xff\xfe.png", "__.png"},
+		{"leading and trailing dots", ".htaccess.", "htaccess"},
+
+		{"empty", "", "file"},
+		{"only a separator", "/", "file"},
+		{"directory", "uploads/", "file"},
+		{"only dots", "..", "file"},
+		{"nothing but replacements", "***", "file"},
+		{"dots around a NUL byte", ".\x00.", "file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.in); got != tt.want {
+				t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// Long names are cut to maxName bytes without splitting a character.
+func TestSanitizeFilenameLength(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"ASCII", strings.Repeat("a", 300), strings.Repeat("a", maxName)},
+		{"two-byte letters", strings.Repeat("é", 200), strings.Repeat("é", maxName/2)},
+		{"dot at the cut", strings.Repeat("a", maxName-1) + ".png", strings.Repeat("a", maxName-1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeFilename(tt.in); got != tt.want {
+				t.Errorf("SanitizeFilename = %d bytes %q…, want %d bytes", len(got), got[:10], len(tt.want))
+			}
+		})
+	}
+}
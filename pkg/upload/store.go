@@ -0,0 +1,122 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrNotFound is returned for a key the store does not hold.
+	ErrNotFound = errors.New("upload: object not found")
+
+	// ErrInvalidKey is returned for a key that is not a content address.
+	ErrInvalidKey = errors.New("upload: invalid key")
+)
+
+// ObjectStore keeps uploaded files under their keys. Keys are content
+// addresses made by Key, so putting a key twice stores the same bytes and
+// the stores may keep the first copy.
+type ObjectStore interface {
+	// Put stores size bytes read from body. contentType is the sniffed
+	// type of the content.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open returns the content stored under key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// Sink is implemented by stores that can take a file before its key is
+// known, which is what streaming needs: the key is the digest of content
+// that has not been read yet. Local, Memory and S3 are sinks.
+type Sink interface {
+	// Begin starts an object. Exactly one of Commit and Abort must be
+	// called on the result.
+	Begin(ctx context.Context) (Pending, error)
+}
+
+// Pending is an object being written to a Sink.
+type Pending interface {
+	io.Writer
+	// Commit stores what was written under key.
+	Commit(key, contentType string) error
+	// Abort drops what was written.
+	Abort() error
+}
+
+// keyPattern matches a hex SHA-256 digest and an optional extension.
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}(\.[a-z0-9]{1,8})?$`)
+
+// Key returns the content address of a file: the hex digest followed by the
+// extension of its type, such as ".png".
+func Key(sum []byte, ext string) string {
+	return fmt.Sprintf("%x%s", sum, ext)
+}
+
+// checkKey rejects anything but a content address, so no key reaches a
+// file system path or an object URL that the store did not make.
+func checkKey(key string) error {
+	if !keyPattern.MatchString(key) {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// Open returns an in-memory store when dsn is empty, an S3 store when dsn
+// is an s3:// URL, or s3+http:// for an endpoint without TLS, and a Local
+// store in the directory dsn names otherwise.
+//
+// An S3 URL names the endpoint host and the bucket, path-style, as in
+// s3://key:secret@s3.example.com/uploads?region=eu-west-1. Without user
+// information the credentials come from AWS_ACCESS_KEY_ID and
+// AWS_SECRET_ACCESS_KEY; the region defaults to us-east-1.
+func Open(dsn string) (ObjectStore, error) {
+	switch {
+	case dsn == "":
+		return NewMemory(), nil
+	case strings.HasPrefix(dsn, "s3://"), strings.HasPrefix(dsn, "s3+http://"):
+		return parseS3(dsn)
+	default:
+		return NewLocal(dsn)
+	}
+}
+
+func parseS3(dsn string) (*S3, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket := strings.Trim(u.Path, "/")
+	if u.Host == "" || bucket == "" || strings.Contains(bucket, "/") {
+		return nil, fmt.Errorf("upload: %s: want s3://host/bucket", u.Redacted())
+	}
+
+	scheme := "https"
+	if u.Scheme == "s3+http" {
+		scheme = "http"
+	}
+
+	s := &S3{
+		Endpoint:  scheme + "://" + u.Host,
+		Bucket:    bucket,
+		Region:    u.Query().Get("region"),
+		AccessKey: os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+	}
+	if u.User != nil {
+		s.AccessKey = u.User.Username()
+		s.SecretKey, _ = u.User.Password()
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+
+	return s, nil
+}
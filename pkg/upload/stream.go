@@ -0,0 +1,203 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Stream stores the files of field while the body of r is read, part by
+// part, without a form in memory or on disk. The body is wrapped in
+// http.MaxBytesReader as in Form, and cancelling r's context, which
+// net/http does when the client goes away, stops the upload.
+func (u Uploader) Stream(w http.ResponseWriter, r *http.Request, field string) ([]File, error) {
+	if r.ContentLength > u.maxRequest() {
+		if _, err := boundary(r.Header.Get("Content-Type")); err != nil {
+			return nil, err
+		}
+		return nil, u.requestTooLarge()
+	}
+	return u.ReadStream(r.Context(), r.Header.Get("Content-Type"), http.MaxBytesReader(w, r.Body, u.maxRequest()), field)
+}
+
+// ReadStream is Stream for a body that is not an http.Request, such as
+// the fasthttp body stream of Fiber.
+//
+// Each file goes from the multipart reader through the SHA-256 hash into a
+// Pending object of the store, which must be a Sink, so memory use does
+// not depend on the size of the upload. The type is sniffed from the
+// first bytes before anything is written; MaxFile and MaxRequest are
+// checked as the bytes arrive. The files are committed once the whole
+// body has been read, and all of them are dropped on the first failure,
+// as with Save. Parts other than the files of field are read and
+// discarded.
+func (u Uploader) ReadStream(ctx context.Context, contentType string, body io.Reader, field string) ([]File, error) {
+	sink, ok := u.Store.(Sink)
+	if !ok {
+		return nil, fmt.Errorf("upload: %T cannot store streamed files", u.Store)
+	}
+
+	b, err := boundary(contentType)
+	if err != nil {
+		return nil, err
+	}
+	mr := multipart.NewReader(&ctxReader{ctx: ctx, r: u.capped(body)}, b)
+
+	var (
+		files     []File
+		pending   []Pending
+		committed int
+	)
+	defer func() {
+		// what is still pending on return belongs to a failed upload
+		for _, p := range pending[committed:] {
+			p.Abort()
+		}
+	}()
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, u.readError(ctx, err)
+		}
+
+		if part.FormName() != field || part.FileName() == "" {
+			if _, err := io.Copy(io.Discard, part); err != nil {
+				return nil, u.readError(ctx, err)
+			}
+			continue
+		}
+
+		f, p, err := u.stream(ctx, sink, part)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		pending = append(pending, p)
+	}
+
+	if len(files) == 0 {
+		return nil, problem.New(http.StatusBadRequest, fmt.Sprintf("form field %q has no file", field)).With("field", field)
+	}
+
+	for i, p := range pending {
+		committed = i + 1
+		if err := p.Commit(files[i].Key, files[i].ContentType); err != nil {
+			return nil, fmt.Errorf("upload: store %s: %w", files[i].Key, err)
+		}
+	}
+
+	return files, nil
+}
+
+// stream copies one file part into a new Pending object of sink. On
+// success the object is left for the caller to commit.
+func (u Uploader) stream(ctx context.Context, sink Sink, part *multipart.Part) (File, Pending, error) {
+	name := SanitizeFilename(part.FileName())
+	src := &errReader{r: part}
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return File{}, nil, u.readError(ctx, err)
+	}
+	head = head[:n]
+	if n == 0 {
+		return File{}, nil, problem.New(http.StatusBadRequest, fmt.Sprintf("file %q is empty", name)).With("file", name)
+	}
+
+	ctype, ext, ok := u.sniff(head)
+	if !ok {
+		return File{}, nil, problem.New(http.StatusUnsupportedMediaType, fmt.Sprintf("file %q is %s, which is not allowed", name, ctype)).
+			With("file", name).
+			With("allowed", u.allow())
+	}
+
+	p, err := sink.Begin(ctx)
+	if err != nil {
+		return File{}, nil, fmt.Errorf("upload: store: %w", err)
+	}
+
+	h := sha256.New()
+	dst := io.MultiWriter(p, h)
+	size, err := u.copyFile(dst, head, src)
+	switch {
+	case src.err != nil:
+		p.Abort()
+		return File{}, nil, u.readError(ctx, src.err)
+	case err != nil:
+		p.Abort()
+		return File{}, nil, fmt.Errorf("upload: store: %w", err)
+	case size > u.maxFile():
+		p.Abort()
+		return File{}, nil, u.fileTooLarge(name)
+	}
+
+	sum := h.Sum(nil)
+	return File{
+		Name:        name,
+		Key:         Key(sum, ext),
+		Size:        size,
+		ContentType: ctype,
+		SHA256:      hex.EncodeToString(sum),
+	}, p, nil
+}
+
+// copyFile writes head and then the rest of src to dst, stopping one byte
+// past MaxFile.
+func (u Uploader) copyFile(dst io.Writer, head []byte, src io.Reader) (int64, error) {
+	if _, err := dst.Write(head); err != nil {
+		return 0, err
+	}
+	rest, err := io.Copy(dst, io.LimitReader(src, u.maxFile()+1-int64(len(head))))
+	return int64(len(head)) + rest, err
+}
+
+// readError maps a failure to read the body onto a problem. A cancelled
+// context is returned as it is: the client is gone and no response will
+// reach it.
+func (u Uploader) readError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+		return fmt.Errorf("upload: %w", err)
+	}
+	return u.explain(err)
+}
+
+// ctxReader fails once ctx is done, so a cancelled upload stops at the
+// next read even when the body itself would go on.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// errReader records the error of its reader, so a failed copy can tell a
+// broken body from a failing store.
+type errReader struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err != nil && err != io.EOF {
+		e.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,288 @@
+// Package upload stores files from multipart/form-data requests safely:
+// the request and every file are held to a size cap, the type is sniffed
+// from the content and checked against an allow-list, and the file is
+// stored under its SHA-256 digest, never under the name the client sent.
+// Failures are pkg/problem errors, so the problem adapters of every
+// framework send them as they are; where the bytes go is an ObjectStore.
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+const (
+	// DefaultMaxFile is the per-file cap of an Uploader without one, 10 MiB.
+	DefaultMaxFile = 10 << 20
+
+	// DefaultMaxRequest is the request body cap of an Uploader without
+	// one, 32 MiB.
+	DefaultMaxRequest = 32 << 20
+
+	// formMemory is how much of a form is kept in memory; larger files
+	// are spilled to temporary files, which the form's RemoveAll deletes.
+	formMemory = 1 << 20
+)
+
+// Uploader checks and stores the files of a form.
+type Uploader struct {
+	Store ObjectStore
+
+	// MaxFile caps the size of each file, MaxRequest the size of the whole
+	// request body, boundaries and other fields included.
+	MaxFile    int64
+	MaxRequest int64
+
+	// Allow lists the media types a file may have, as sniffed from its
+	// first bytes; DefaultAllow when nil.
+	Allow []string
+}
+
+// File describes a stored file. It is what the upload endpoints answer
+// with, one entry per file.
+type File struct {
+	Name        string `json:"name"`         // sanitised name the client sent
+	Key         string `json:"key"`          // content address in the store
+	Size        int64  `json:"size"`         // length in bytes
+	ContentType string `json:"content_type"` // sniffed type, not the one the client sent
+	SHA256      string `json:"sha256"`       // hex digest of the content
+}
+
+// Form parses the multipart body of r. The body is wrapped in
+// http.MaxBytesReader, so reading stops at MaxRequest bytes and the server
+// closes the connection instead of draining the rest. The caller removes
+// the temporary files with the form's RemoveAll.
+func (u Uploader) Form(w http.ResponseWriter, r *http.Request) (*multipart.Form, error) {
+	if _, err := boundary(r.Header.Get("Content-Type")); err != nil {
+		return nil, err
+	}
+	if r.ContentLength > u.maxRequest() {
+		return nil, u.requestTooLarge()
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, u.maxRequest())
+	if err := r.ParseMultipartForm(formMemory); err != nil {
+		return nil, u.explain(err)
+	}
+	return r.MultipartForm, nil
+}
+
+// ReadForm parses a multipart body read from body, such as a fasthttp
+// body stream, stopping at MaxRequest bytes. The caller removes the
+// temporary files with the form's RemoveAll.
+func (u Uploader) ReadForm(contentType string, body io.Reader) (*multipart.Form, error) {
+	b, err := boundary(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	form, err := multipart.NewReader(u.capped(body), b).ReadForm(formMemory)
+	if err != nil {
+		return nil, u.explain(err)
+	}
+	return form, nil
+}
+
+// Save checks every file of the form field and stores them. Nothing is
+// stored unless all of them pass: a file over MaxFile is a 413, a type
+// outside the allow-list a 415, a missing or empty file a 400. The caps
+// are checked again here, so a form parsed by a framework helper is held
+// to them as well.
+func (u Uploader) Save(ctx context.Context, form *multipart.Form, field string) ([]File, error) {
+	if u.Store == nil {
+		return nil, errors.New("upload: Uploader has no Store")
+	}
+
+	var headers []*multipart.FileHeader
+	if form != nil {
+		headers = form.File[field]
+	}
+	if len(headers) == 0 {
+		return nil, problem.New(http.StatusBadRequest, fmt.Sprintf("form field %q has no file", field)).With("field", field)
+	}
+
+	files := make([]File, len(headers))
+	var total int64
+	for i, fh := range headers {
+		f, err := u.check(fh)
+		if err != nil {
+			return nil, err
+		}
+		total += f.Size
+		if total > u.maxRequest() {
+			return nil, u.requestTooLarge()
+		}
+		files[i] = f
+	}
+
+	for i, fh := range headers {
+		if err := u.put(ctx, fh, files[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// check reads the file once to sniff its type and hash it.
+func (u Uploader) check(fh *multipart.FileHeader) (File, error) {
+	name := SanitizeFilename(fh.Filename)
+	if fh.Size > u.maxFile() {
+		return File{}, u.fileTooLarge(name)
+	}
+
+	src, err := fh.Open()
+	if err != nil {
+		return File{}, err
+	}
+	defer src.Close()
+
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return File{}, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return File{}, problem.New(http.StatusBadRequest, fmt.Sprintf("file %q is empty", name)).With("file", name)
+	}
+
+	ctype, ext, ok := u.sniff(head)
+	if !ok {
+		return File{}, problem.New(http.StatusUnsupportedMediaType, fmt.Sprintf("file %q is %s, which is not allowed", name, ctype)).
+			With("file", name).
+			With("allowed", u.allow())
+	}
+
+	h := sha256.New()
+	h.Write(head)
+	// one byte past the cap tells a file that is too large
+	rest, err := io.Copy(h, io.LimitReader(src, u.maxFile()+1-int64(n)))
+	if err != nil {
+		return File{}, err
+	}
+	size := int64(n) + rest
+	if size > u.maxFile() {
+		return File{}, u.fileTooLarge(name)
+	}
+
+	sum := h.Sum(nil)
+	return File{
+		Name:        name,
+		Key:         Key(sum, ext),
+		Size:        size,
+		ContentType: ctype,
+		SHA256:      hex.EncodeToString(sum),
+	}, nil
+}
+
+func (u Uploader) put(ctx context.Context, fh *multipart.FileHeader, f File) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	if err := u.Store.Put(ctx, f.Key, src, f.Size, f.ContentType); err != nil {
+		return fmt.Errorf("upload: store %s: %w", f.Key, err)
+	}
+	return nil
+}
+
+func (u Uploader) maxFile() int64 {
+	if u.MaxFile <= 0 {
+		return DefaultMaxFile
+	}
+	return u.MaxFile
+}
+
+func (u Uploader) maxRequest() int64 {
+	if u.MaxRequest <= 0 {
+		return DefaultMaxRequest
+	}
+	return u.MaxRequest
+}
+
+// explain maps the errors of multipart parsing onto problems.
+func (u Uploader) explain(err error) error {
+	var size *http.MaxBytesError
+	if errors.As(err, &size) || errors.Is(err, multipart.ErrMessageTooLarge) {
+		return u.requestTooLarge()
+	}
+	return problem.New(http.StatusBadRequest, "malformed multipart body")
+}
+
+func (u Uploader) requestTooLarge() error {
+	return problem.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", u.maxRequest())).
+		With("limit", u.maxRequest())
+}
+
+func (u Uploader) fileTooLarge(name string) error {
+	return problem.New(http.StatusRequestEntityTooLarge, fmt.Sprintf("file %q exceeds %d bytes", name, u.maxFile())).
+		With("file", name).
+		With("limit", u.maxFile())
+}
+
+// capped returns body limited to MaxRequest bytes. Reading past the limit
+// fails with *http.MaxBytesError, as with http.MaxBytesReader, which a
+// body without an http.ResponseWriter cannot use.
+func (u Uploader) capped(body io.Reader) io.Reader {
+	return &capReader{r: body, n: u.maxRequest(), limit: u.maxRequest()}
+}
+
+type capReader struct {
+	r     io.Reader
+	n     int64 // bytes left, -1 once the limit was passed
+	limit int64
+}
+
+func (c *capReader) Read(p []byte) (int, error) {
+	if c.n < 0 {
+		return 0, &http.MaxBytesError{Limit: c.limit}
+	}
+	// one byte more than is left tells the limit was passed
+	if int64(len(p)) > c.n+1 {
+		p = p[:c.n+1]
+	}
+	n, err := c.r.Read(p)
+	if int64(n) <= c.n {
+		c.n -= int64(n)
+		return n, err
+	}
+	n, c.n = int(c.n), -1
+	return n, &http.MaxBytesError{Limit: c.limit}
+}
+
+// boundary checks that contentType is multipart/form-data and returns its
+// boundary parameter.
+func boundary(contentType string) (string, error) {
+	mt, params, err := mime.ParseMediaType(contentType)
+	if err != nil || mt != "multipart/form-data" {
+		return "", problem.New(http.StatusUnsupportedMediaType, "Content-Type must be multipart/form-data")
+	}
+	if params["boundary"] == "" {
+		return "", problem.New(http.StatusBadRequest, "multipart Content-Type has no boundary")
+	}
+	return params["boundary"], nil
+}
+
+// readExactly reads all of body and checks it is size bytes long.
+func readExactly(body io.Reader, size int64) ([]byte, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("upload: read %d of %d bytes", len(data), size)
+	}
+	return data, nil
+}
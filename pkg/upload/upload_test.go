@@ -0,0 +1,48 @@
+package upload
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadExactly(t *testing.T) {
+	broken := errors.New("connection reset")
+
+	tests := []struct {
+		name    string
+		body    io.Reader
+		size    int64
+		want    string
+		wantErr string
+	}{
+		{name: "exact", body: strings.NewReader("hello"), size: 5, want: "hello"},
+		{name: "empty", body: strings.NewReader(""), size: 0, want: ""},
+		{name: "one byte at a time", body: iotest.OneByteReader(strings.NewReader("hello")), size: 5, want: "hello"},
+		{name: "short", body: strings.NewReader("hell"), size: 5, wantErr: "upload: read 4 of 5 bytes"},
+		{name: "long", body: strings.NewReader("hello!"), size: 5, wantErr: "upload: read 6 of 5 bytes"},
+		{name: "read error", body: io.MultiReader(strings.NewReader("he"), iotest.ErrReader(broken)), size: 5, wantErr: broken.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readExactly(tt.body, tt.size)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				if got != nil {
+					t.Errorf("data = %q, want nil", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("data = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package upload_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/problem"
+	"github.com/go-mizu/go-fw/pkg/upload"
+)
+
+// Sample contents, recognised by their magic bytes.
+var (
+	samplePNG  = append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), bytes.Repeat([]byte{1}, 64)...)
+	samplePDF  = []byte("%PDF-1.7\n1 0 obj << /Type /Catalog >> endobj\n%%EOF\n")
+	sampleHTML = []byte("<!DOCTYPE html><script>alert(document.cookie)</script>")
+)
+
+// part is one file of a form. Type is the Content-Type the client claims.
+type part struct {
+	field, name, ctype string
+	data               []byte
+}
+
+// form encodes parts as a multipart/form-data body, after a plain field.
+func form(parts ...part) (string, []byte) {
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("note", "hello")
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, p.field, p.name))
+		h.Set("Content-Type", p.ctype)
+		w, _ := mw.CreatePart(h)
+		w.Write(p.data)
+	}
+	mw.Close()
+	return mw.FormDataContentType(), buf.Bytes()
+}
+
+// filled returns a PNG of n bytes.
+func filled(n int) []byte {
+	return append(bytes.Clone(samplePNG), make([]byte, n-len(samplePNG))...)
+}
+
+type uploadCase struct {
+	name        string
+	contentType string
+	body        []byte
+	status      int           // of the problem, 0 for success
+	detail      string        // of the problem
+	files       []upload.File // of a success, with Key and SHA256 filled in
+	contents    [][]byte      // the content of each of files
+	absent      [][]byte      // PNG contents that must not have been stored
+}
+
+func uploadCases() []uploadCase {
+	var cases []uploadCase
+	add := func(c uploadCase) { cases = append(cases, c) }
+
+	ct, body := form(part{"file", "../../etc/passwd.png", "image/png", samplePNG})
+	add(uploadCase{name: "png with a traversal name", contentType: ct, body: body,
+		files: []upload.File{{Name: "passwd.png", Size: int64(len(samplePNG)), ContentType: "image/png"}}, contents: [][]byte{samplePNG}})
+
+	ct, body = form(part{"file", `C:\Users\ann\report 2024.pdf`, "application/octet-stream", samplePDF}, part{"file", ".hidden", "text/plain", []byte("plain text\n")})
+	add(uploadCase{name: "two files", contentType: ct, body: body,
+		files: []upload.File{
+			{Name: "report_2024.pdf", Size: int64(len(samplePDF)), ContentType: "application/pdf"},
+			{Name: "hidden", Size: 11, ContentType: "text/plain"},
+		}, contents: [][]byte{samplePDF, []byte("plain text\n")}})
+
+	ct, body = form(part{"file", "cat.png", "image/png", sampleHTML})
+	add(uploadCase{name: "html claiming to be png", contentType: ct, body: body, status: 415,
+		detail: `file "cat.png" is text/html, which is not allowed`})
+
+	ok := filled(len(samplePNG) + 1)
+	ct, body = form(part{"file", "ok.png", "image/png", ok}, part{"file", "page.html", "text/html", sampleHTML})
+	add(uploadCase{name: "one bad file rejects all", contentType: ct, body: body, status: 415,
+		detail: `file "page.html" is text/html, which is not allowed`, absent: [][]byte{ok}})
+
+	ct, body = form(part{"file", "empty.txt", "text/plain", nil})
+	add(uploadCase{name: "empty file", contentType: ct, body: body, status: 400, detail: `file "empty.txt" is empty`})
+
+	ct, body = form(part{"other", "cat.png", "image/png", samplePNG})
+	add(uploadCase{name: "no file in the field", contentType: ct, body: body, status: 400, detail: `form field "file" has no file`})
+
+	add(uploadCase{name: "not multipart", contentType: "application/json", body: []byte(`{}`), status: 415,
+		detail: "Content-Type must be multipart/form-data"})
+
+	add(uploadCase{name: "no boundary", contentType: "multipart/form-data", body: []byte(`{}`), status: 400,
+		detail: "multipart Content-Type has no boundary"})
+
+	add(uploadCase{name: "malformed multipart", contentType: "multipart/form-data; boundary=xxx", body: []byte("--xxx\r\nno end"), status: 400,
+		detail: "malformed multipart body"})
+
+	ct, body = form(part{"file", "big.png", "image/png", filled(1<<20 + 1)})
+	add(uploadCase{name: "file over the cap", contentType: ct, body: body, status: 413, detail: `file "big.png" exceeds 1048576 bytes`})
+
+	big := filled(800 << 10)
+	ct, body = form(part{"file", "a.png", "image/png", big}, part{"file", "b.png", "image/png", big}, part{"file", "c.png", "image/png", big})
+	add(uploadCase{name: "request over the cap", contentType: ct, body: body, status: 413, detail: "request body exceeds 2097152 bytes"})
+
+	return cases
+}
+
+// The three ways into an Uploader answer every case alike and store the
+// same objects, whatever the store.
+func TestUploader(t *testing.T) {
+	s3 := newFakeS3()
+	defer s3.Close()
+
+	ways := map[string]func(u upload.Uploader, contentType string, body []byte) ([]upload.File, error){
+		"Form": func(u upload.Uploader, contentType string, body []byte) ([]upload.File, error) {
+			r := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(body))
+			r.Header.Set("Content-Type", contentType)
+			form, err := u.Form(httptest.NewRecorder(), r)
+			if err != nil {
+				return nil, err
+			}
+			defer form.RemoveAll()
+			return u.Save(r.Context(), form, "file")
+		},
+		"ReadForm": func(u upload.Uploader, contentType string, body []byte) ([]upload.File, error) {
+			form, err := u.ReadForm(contentType, bytes.NewReader(body))
+			if err != nil {
+				return nil, err
+			}
+			defer form.RemoveAll()
+			return u.Save(context.Background(), form, "file")
+		},
+		"Stream": func(u upload.Uploader, contentType string, body []byte) ([]upload.File, error) {
+			r := httptest.NewRequest(http.MethodPost, "/stream", bytes.NewReader(body))
+			r.Header.Set("Content-Type", contentType)
+			return u.Stream(httptest.NewRecorder(), r, "file")
+		},
+	}
+
+	for store, dsn := range map[string]string{"memory": "", "local": t.TempDir(), "s3": s3.dsn("uploads")} {
+		for way, save := range ways {
+			t.Run(store+"/"+way, func(t *testing.T) {
+				objects, err := upload.Open(dsn)
+				if err != nil {
+					t.Fatal(err)
+				}
+				u := upload.Uploader{Store: objects, MaxFile: 1 << 20, MaxRequest: 2 << 20}
+
+				for _, c := range uploadCases() {
+					t.Run(c.name, func(t *testing.T) {
+						files, err := save(u, c.contentType, c.body)
+						if c.status != 0 {
+							checkProblem(t, err, c.status, c.detail)
+							for _, data := range c.absent {
+								sum := sha256.Sum256(data)
+								if _, err := objects.Open(context.Background(), upload.Key(sum[:], ".png")); !errors.Is(err, upload.ErrNotFound) {
+									t.Errorf("a rejected upload was stored (%v)", err)
+								}
+							}
+							return
+						}
+
+						if err != nil {
+							t.Fatal(err)
+						}
+						if len(files) != len(c.files) {
+							t.Fatalf("%d files, want %d: %+v", len(files), len(c.files), files)
+						}
+						for i, want := range c.files {
+							sum := sha256.Sum256(c.contents[i])
+							want.SHA256 = hex.EncodeToString(sum[:])
+							want.Key = files[i].Key
+							if files[i] != want {
+								t.Errorf("file %d = %+v, want %+v", i, files[i], want)
+							}
+							if !strings.HasPrefix(want.Key, want.SHA256) {
+								t.Errorf("key %q is not the content address", want.Key)
+							}
+							checkStored(t, objects, want.Key, c.contents[i])
+						}
+					})
+				}
+			})
+		}
+	}
+}
+
+func checkProblem(t *testing.T, err error, status int, detail string) {
+	t.Helper()
+
+	var p *problem.Details
+	if !errors.As(err, &p) {
+		t.Fatalf("error = %v, want a %d problem", err, status)
+	}
+	if p.Status != status || p.Detail != detail {
+		t.Errorf("problem = %d %q, want %d %q", p.Status, p.Detail, status, detail)
+	}
+}
+
+func checkStored(t *testing.T, store upload.ObjectStore, key string, want []byte) {
+	t.Helper()
+
+	rc, err := store.Open(context.Background(), key)
+	if err != nil {
+		t.Errorf("open %s: %v", key, err)
+		return
+	}
+	defer rc.Close()
+
+	got, err := io.ReadAll(rc)
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("stored %s differs from the upload (%v)", key, err)
+	}
+}
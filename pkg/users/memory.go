@@ -0,0 +1,99 @@
+package users
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+// Memory is a Store held in a map, lost when the process exits.
+type Memory struct {
+	mu     sync.Mutex
+	users  map[int]models.UserData
+	lastID int
+}
+
+// NewMemory returns an empty store.
+func NewMemory() *Memory {
+	return &Memory{users: map[int]models.UserData{}}
+}
+
+func (m *Memory) Create(_ context.Context, req models.CreateUserRequest) (models.UserData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.taken(req.Email, 0) {
+		return models.UserData{}, ErrEmailTaken
+	}
+
+	m.lastID++
+	u := models.UserData{ID: m.lastID, Email: req.Email, Role: req.Role}
+	m.users[u.ID] = u
+	return u, nil
+}
+
+func (m *Memory) Get(_ context.Context, id int) (models.UserData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return models.UserData{}, ErrNotFound
+	}
+	return u, nil
+}
+
+func (m *Memory) List(_ context.Context, page pagination.Offset) ([]models.UserData, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := pagination.Slice(slices.Sorted(maps.Keys(m.users)), page)
+	out := make([]models.UserData, len(ids))
+	for i, id := range ids {
+		out[i] = m.users[id]
+	}
+	return out, len(m.users), nil
+}
+
+func (m *Memory) Update(_ context.Context, id int, req models.UpdateUserRequest) (models.UserData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return models.UserData{}, ErrNotFound
+	}
+	if req.Email != nil && m.taken(*req.Email, id) {
+		return models.UserData{}, ErrEmailTaken
+	}
+
+	req.Apply(&u)
+	m.users[id] = u
+	return u, nil
+}
+
+func (m *Memory) Delete(_ context.Context, id int) (models.UserData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	u, ok := m.users[id]
+	if !ok {
+		return models.UserData{}, ErrNotFound
+	}
+	delete(m.users, id)
+	return u, nil
+}
+
+// taken reports whether a user other than id has email.
+func (m *Memory) taken(email string, id int) bool {
+	for _, u := range m.users {
+		if u.Email == email && u.ID != id {
+			return true
+		}
+	}
+	return false
+}
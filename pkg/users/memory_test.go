@@ -0,0 +1,165 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+func ptr(s string) *string { return &s }
+
+func create(t *testing.T, s Store, email, role string) models.UserData {
+	t.Helper()
+	u, err := s.Create(context.Background(), models.CreateUserRequest{Email: email, Password: "secret123", Role: role})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestMemoryCreate(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	ann := create(t, m, "ann@example.com", "admin")
+	bob := create(t, m, "bob@example.com", "viewer")
+	if ann != (models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}) || bob.ID != 2 {
+		t.Errorf("created %+v and %+v, want ids 1 and 2", ann, bob)
+	}
+
+	if _, err := m.Create(ctx, models.CreateUserRequest{Email: "ann@example.com", Role: "viewer"}); !errors.Is(err, ErrEmailTaken) {
+		t.Errorf("second ann: error = %v, want ErrEmailTaken", err)
+	}
+
+	// ids are never handed out again, not even after a delete
+	if _, err := m.Delete(ctx, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if cy := create(t, m, "cy@example.com", "editor"); cy.ID != 3 {
+		t.Errorf("id after a delete = %d, want 3", cy.ID)
+	}
+}
+
+func TestMemoryGetDelete(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	ann := create(t, m, "ann@example.com", "admin")
+
+	if got, err := m.Get(ctx, ann.ID); err != nil || got != ann {
+		t.Errorf("Get = %+v, %v, want %+v", got, err, ann)
+	}
+	if _, err := m.Get(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(99): error = %v, want ErrNotFound", err)
+	}
+
+	if got, err := m.Delete(ctx, ann.ID); err != nil || got != ann {
+		t.Errorf("Delete = %+v, %v, want the user as it was", got, err)
+	}
+	if _, err := m.Get(ctx, ann.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: error = %v, want ErrNotFound", err)
+	}
+	if _, err := m.Delete(ctx, ann.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete: error = %v, want ErrNotFound", err)
+	}
+	// the email is free again
+	create(t, m, "ann@example.com", "viewer")
+}
+
+func TestMemoryUpdate(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	ann := create(t, m, "ann@example.com", "admin")
+	bob := create(t, m, "bob@example.com", "viewer")
+
+	tests := []struct {
+		name string
+		id   int
+		req  models.UpdateUserRequest
+		want models.UserData
+		err  error
+	}{
+		{name: "role", id: bob.ID, req: models.UpdateUserRequest{Role: ptr("editor")}, want: models.UserData{ID: 2, Email: "bob@example.com", Role: "editor"}},
+		{name: "own email", id: bob.ID, req: models.UpdateUserRequest{Email: ptr("bob@example.com")}, want: models.UserData{ID: 2, Email: "bob@example.com", Role: "editor"}},
+		{name: "new email", id: bob.ID, req: models.UpdateUserRequest{Email: ptr("rob@example.com")}, want: models.UserData{ID: 2, Email: "rob@example.com", Role: "editor"}},
+		{name: "email of another user", id: bob.ID, req: models.UpdateUserRequest{Email: ptr(ann.Email), Role: ptr("admin")}, err: ErrEmailTaken},
+		{name: "unknown id", id: 99, req: models.UpdateUserRequest{Role: ptr("admin")}, err: ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.Update(ctx, tt.id, tt.req)
+			if !errors.Is(err, tt.err) || got != tt.want {
+				t.Errorf("Update = %+v, %v, want %+v, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+
+	// a refused update changes nothing
+	if got, _ := m.Get(ctx, bob.ID); got.Email != "rob@example.com" || got.Role != "editor" {
+		t.Errorf("after the refused update: %+v", got)
+	}
+}
+
+func TestMemoryList(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		create(t, m, email, "viewer")
+	}
+	m.Delete(ctx, 2)
+
+	tests := []struct {
+		page pagination.Offset
+		ids  []int
+	}{
+		{pagination.Offset{Page: 1, Size: 2}, []int{1, 3}},
+		{pagination.Offset{Page: 2, Size: 2}, []int{4, 5}},
+		{pagination.Offset{Page: 3, Size: 2}, []int{}},
+		{pagination.Offset{Page: 1, Size: 20}, []int{1, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		items, total, err := m.List(ctx, tt.page)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := []int{}
+		for _, u := range items {
+			ids = append(ids, u.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.ids) || total != 4 {
+			t.Errorf("List(%+v) = ids %v, total %d, want %v, 4", tt.page, ids, total, tt.ids)
+		}
+	}
+}
+
+// Concurrent creates of one email leave exactly one user.
+func TestMemoryCreateRace(t *testing.T) {
+	m := NewMemory()
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := m.Create(context.Background(), models.CreateUserRequest{Email: "ann@example.com", Role: "admin"})
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created != 1 {
+		t.Errorf("%d creates succeeded, want 1", created)
+	}
+}
@@ -0,0 +1,127 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+	"github.com/go-mizu/go-fw/pkg/problem"
+)
+
+// Service validates requests, calls the Store and returns the envelope to
+// send. The HTTP layers pass it the decoded body and the raw path id.
+type Service struct {
+	Store Store
+	Pages pagination.Config
+}
+
+// NewService serves store with the default page sizes.
+func NewService(store Store) *Service {
+	return &Service{Store: store, Pages: pagination.Default}
+}
+
+// BadBody is the response for a body that does not decode.
+func BadBody() models.Envelope {
+	return models.Fail(http.StatusBadRequest, "Invalid JSON body")
+}
+
+// Create handles POST /users.
+func (s *Service) Create(ctx context.Context, req models.CreateUserRequest) models.Envelope {
+	if err := req.Validate(); err != nil {
+		return models.Invalid(err)
+	}
+
+	u, err := s.Store.Create(ctx, req)
+	if err != nil {
+		return fail(err)
+	}
+	return models.Created(u).WithMessage("User created")
+}
+
+// List handles GET /users?page=N&page_size=N. The links go in the Link
+// header.
+func (s *Service) List(ctx context.Context, u *url.URL) (models.Envelope, pagination.Links) {
+	page, err := s.Pages.Offset(u)
+	if err != nil {
+		return fail(err), pagination.Links{}
+	}
+
+	items, total, err := s.Store.List(ctx, page)
+	if err != nil {
+		return fail(err), pagination.Links{}
+	}
+	return pagination.Paginated(items, page, total), page.Links(u, total)
+}
+
+// Get handles GET /users/{id}.
+func (s *Service) Get(ctx context.Context, id string) models.Envelope {
+	n, err := parseID(id)
+	if err != nil {
+		return fail(err)
+	}
+
+	u, err := s.Store.Get(ctx, n)
+	if err != nil {
+		return fail(err)
+	}
+	return models.OK(u)
+}
+
+// Update handles PATCH /users/{id}.
+func (s *Service) Update(ctx context.Context, id string, req models.UpdateUserRequest) models.Envelope {
+	n, err := parseID(id)
+	if err != nil {
+		return fail(err)
+	}
+	if err := req.Validate(); err != nil {
+		return models.Invalid(err)
+	}
+
+	u, err := s.Store.Update(ctx, n, req)
+	if err != nil {
+		return fail(err)
+	}
+	return models.OK(u).WithMessage("User updated")
+}
+
+// Delete handles DELETE /users/{id}.
+func (s *Service) Delete(ctx context.Context, id string) models.Envelope {
+	n, err := parseID(id)
+	if err != nil {
+		return fail(err)
+	}
+
+	u, err := s.Store.Delete(ctx, n)
+	if err != nil {
+		return fail(err)
+	}
+	return models.OK(u).WithMessage("User deleted")
+}
+
+var errBadID = problem.New(http.StatusBadRequest, "Invalid user id")
+
+func parseID(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return 0, errBadID
+	}
+	return n, nil
+}
+
+// fail maps store errors, and the 400 problems of parseID and pagination,
+// onto error envelopes. Anything else is a 500 without detail.
+func fail(err error) models.ErrorResponse {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return models.Fail(http.StatusNotFound, "User not found")
+	case errors.Is(err, ErrEmailTaken):
+		return models.Fail(http.StatusConflict, "Email already in use")
+	}
+
+	p := problem.From(err)
+	return models.Fail(p.StatusCode(), p.Detail)
+}
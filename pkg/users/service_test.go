@@ -0,0 +1,121 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+// broken is a Store whose every call fails, as a database that went away.
+type broken struct{ Store }
+
+var errDown = errors.New("database is down")
+
+func (broken) Get(context.Context, int) (models.UserData, error) {
+	return models.UserData{}, errDown
+}
+
+func TestService(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemory())
+	ann := models.CreateUserRequest{Email: "ann@example.com", Password: "secret123", Role: "admin"}
+	annData := models.UserData{ID: 1, Email: "ann@example.com", Role: "admin"}
+
+	tests := []struct {
+		name string
+		call func() models.Envelope
+		want models.Envelope
+	}{
+		{
+			name: "create",
+			call: func() models.Envelope { return svc.Create(ctx, ann) },
+			want: models.Created(annData).WithMessage("User created"),
+		},
+		{
+			name: "create taken",
+			call: func() models.Envelope { return svc.Create(ctx, ann) },
+			want: models.Fail(http.StatusConflict, "Email already in use"),
+		},
+		{
+			name: "create invalid",
+			call: func() models.Envelope { return svc.Create(ctx, models.CreateUserRequest{}) },
+			want: models.Invalid(models.CreateUserRequest{}.Validate()),
+		},
+		{
+			name: "get",
+			call: func() models.Envelope { return svc.Get(ctx, "1") },
+			want: models.OK(annData),
+		},
+		{
+			name: "get unknown",
+			call: func() models.Envelope { return svc.Get(ctx, "99") },
+			want: models.Fail(http.StatusNotFound, "User not found"),
+		},
+		{
+			name: "id not a number",
+			call: func() models.Envelope { return svc.Get(ctx, "abc") },
+			want: models.Fail(http.StatusBadRequest, "Invalid user id"),
+		},
+		{
+			name: "id 0",
+			call: func() models.Envelope { return svc.Delete(ctx, "0") },
+			want: models.Fail(http.StatusBadRequest, "Invalid user id"),
+		},
+		{
+			name: "bad id before the body",
+			call: func() models.Envelope { return svc.Update(ctx, "-1", models.UpdateUserRequest{}) },
+			want: models.Fail(http.StatusBadRequest, "Invalid user id"),
+		},
+		{
+			name: "update",
+			call: func() models.Envelope { return svc.Update(ctx, "1", models.UpdateUserRequest{Role: ptr("viewer")}) },
+			want: models.OK(models.UserData{ID: 1, Email: "ann@example.com", Role: "viewer"}).WithMessage("User updated"),
+		},
+		{
+			name: "delete",
+			call: func() models.Envelope { return svc.Delete(ctx, "1") },
+			want: models.OK(models.UserData{ID: 1, Email: "ann@example.com", Role: "viewer"}).WithMessage("User deleted"),
+		},
+		{
+			name: "store failure",
+			call: func() models.Envelope { return NewService(broken{}).Get(ctx, "1") },
+			want: models.Fail(http.StatusInternalServerError, ""),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.call(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	ctx := context.Background()
+	svc := NewService(NewMemory())
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		svc.Create(ctx, models.CreateUserRequest{Email: email, Password: "secret123", Role: "viewer"})
+	}
+
+	u, _ := url.Parse("/users?page=2&page_size=2")
+	res, links := svc.List(ctx, u)
+	want := pagination.Paginated([]models.UserData{{ID: 3, Email: "c@example.com", Role: "viewer"}}, pagination.Offset{Page: 2, Size: 2}, 3)
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("List = %+v, want %+v", res, want)
+	}
+	if links.Prev == "" || links.Next != "" {
+		t.Errorf("links = %+v, want a prev and no next", links)
+	}
+
+	u, _ = url.Parse("/users?page=0")
+	if res, _ := svc.List(ctx, u); res.StatusCode() != http.StatusBadRequest {
+		t.Errorf("page 0: status %d, want 400", res.StatusCode())
+	}
+}
@@ -0,0 +1,153 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+const schema = `CREATE TABLE IF NOT EXISTS users (
+	id    INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT NOT NULL UNIQUE,
+	role  TEXT NOT NULL
+)`
+
+// SQL is a Store in a SQLite database. It uses database/sql only, so any
+// SQLite driver works.
+type SQL struct {
+	db *sql.DB
+}
+
+// NewSQL creates the users table when it does not exist.
+func NewSQL(ctx context.Context, db *sql.DB) (*SQL, error) {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return nil, err
+	}
+	return &SQL{db: db}, nil
+}
+
+func (s *SQL) Create(ctx context.Context, req models.CreateUserRequest) (models.UserData, error) {
+	var u models.UserData
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		if err := taken(ctx, tx, req.Email, 0); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `INSERT INTO users (email, role) VALUES (?, ?)`, req.Email, req.Role)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		u = models.UserData{ID: int(id), Email: req.Email, Role: req.Role}
+		return nil
+	})
+	return u, err
+}
+
+func (s *SQL) Get(ctx context.Context, id int) (models.UserData, error) {
+	return get(ctx, s.db, id)
+}
+
+func (s *SQL) List(ctx context.Context, page pagination.Offset) ([]models.UserData, int, error) {
+	var total int
+	if err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, email, role FROM users ORDER BY id LIMIT ? OFFSET ?`, page.Size, page.Skip())
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	out := []models.UserData{}
+	for rows.Next() {
+		var u models.UserData
+		if err := rows.Scan(&u.ID, &u.Email, &u.Role); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, u)
+	}
+	return out, total, rows.Err()
+}
+
+func (s *SQL) Update(ctx context.Context, id int, req models.UpdateUserRequest) (models.UserData, error) {
+	var u models.UserData
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if u, err = get(ctx, tx, id); err != nil {
+			return err
+		}
+		if req.Email != nil {
+			if err := taken(ctx, tx, *req.Email, id); err != nil {
+				return err
+			}
+		}
+
+		req.Apply(&u)
+		_, err = tx.ExecContext(ctx, `UPDATE users SET email = ?, role = ? WHERE id = ?`, u.Email, u.Role, id)
+		return err
+	})
+	return u, err
+}
+
+func (s *SQL) Delete(ctx context.Context, id int) (models.UserData, error) {
+	var u models.UserData
+	err := s.tx(ctx, func(tx *sql.Tx) error {
+		var err error
+		if u, err = get(ctx, tx, id); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, id)
+		return err
+	})
+	return u, err
+}
+
+// tx runs fn in a transaction, committed when fn succeeds.
+func (s *SQL) tx(ctx context.Context, fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// querier is what *sql.DB and *sql.Tx have in common.
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func get(ctx context.Context, q querier, id int) (models.UserData, error) {
+	var u models.UserData
+	err := q.QueryRowContext(ctx, `SELECT id, email, role FROM users WHERE id = ?`, id).Scan(&u.ID, &u.Email, &u.Role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.UserData{}, ErrNotFound
+	}
+	return u, err
+}
+
+// taken returns ErrEmailTaken when a user other than id has email. The
+// UNIQUE constraint backs it up, but its error differs between drivers.
+func taken(ctx context.Context, q querier, email string, id int) error {
+	var n int
+	err := q.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE email = ? AND id != ?`, email, id).Scan(&n)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrEmailTaken
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+// Package users is the user service of the CRUD chapter: a Store with an
+// in-memory and a SQLite implementation, and a Service that turns requests
+// into pkg/models envelopes, so the six HTTP layers only decode and write.
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/go-mizu/go-fw/pkg/models"
+	"github.com/go-mizu/go-fw/pkg/pagination"
+)
+
+var (
+	// ErrNotFound is returned for an id no user has.
+	ErrNotFound = errors.New("users: not found")
+
+	// ErrEmailTaken is returned when another user has the email already.
+	ErrEmailTaken = errors.New("users: email already in use")
+)
+
+// Store keeps users. Requests are validated before they reach it. The
+// password is checked and dropped; authentication is out of scope.
+type Store interface {
+	Create(ctx context.Context, req models.CreateUserRequest) (models.UserData, error)
+	Get(ctx context.Context, id int) (models.UserData, error)
+	// List returns one page of users ordered by id, and the total count.
+	List(ctx context.Context, page pagination.Offset) ([]models.UserData, int, error)
+	Update(ctx context.Context, id int, req models.UpdateUserRequest) (models.UserData, error)
+	// Delete removes the user and returns it as it was.
+	Delete(ctx context.Context, id int) (models.UserData, error)
+}
+
+// Open returns an in-memory store when dsn is empty, and a SQLite store on
+// the database dsn names otherwise. The program registers the driver by
+// importing github.com/mattn/go-sqlite3.
+func Open(dsn string) (Store, error) {
+	if dsn == "" {
+		return NewMemory(), nil
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer, and every connection to :memory: would be
+	// a database of its own
+	db.SetMaxOpenConns(1)
+
+	return NewSQL(context.Background(), db)
+}
@@ -1,7 +1,8 @@
 #!/usr/bin/env bash
 set -euo pipefail
 
-for mod in $(find . -name go.mod -type f); do
-  dir=$(dirname "$mod")
-  (cd "$dir" && go build ./...)
-done
+# Run from repo root.
+# Builds every go.work module in parallel and prints a chapter x framework
+# status grid. See cmd/build for -vet, -test, -json and -embed.
+
+exec go run ./cmd/build "$@"
@@ -0,0 +1,10 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Runs every module's tests and benchmarks once, including the extracted
+# *_test.go files of 22-testing and 23-performance.
+
+for mod in $(find . -name go.mod -type f -not -path "./.git/*" | sort); do
+  dir=$(dirname "$mod")
+  (cd "$dir" && go test -bench . -benchtime 1x ./...)
+done
@@ -4,15 +4,23 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/go-mizu/go-fw/pkg/registry"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
 )
 
 type row struct {
@@ -29,7 +37,7 @@ type row struct {
 type goDLItem struct {
 	Version string `json:"version"` // e.g. "go1.25.5"
 	Stable  bool   `json:"stable"`
-	Files   []any  `json:"files"`
+	Files   []any  `json:"files,omitempty"`
 }
 
 type goListModule struct {
@@ -38,127 +46,150 @@ type goListModule struct {
 	Time    time.Time `json:"Time"`
 }
 
-type fw struct {
-	Name        string
-	Description string
-	Module      string
-	URL         string
+// source answers the questions the table needs, either from the network or
+// from a cache directory written by -update-cache.
+type source interface {
+	goDL() ([]goDLItem, error)
+	goReleases() (map[string]string, error) // version -> release date
+	latest(mod string) (goListModule, error)
 }
 
-var frameworks = []fw{
-	{"net/http", "Go standard library HTTP server", "", "https://github.com/golang/go"},
-	{"Chi", "Router built on net/http", "github.com/go-chi/chi/v5", "https://github.com/go-chi/chi"},
-	{"Gin", "API focused HTTP framework", "github.com/gin-gonic/gin", "https://github.com/gin-gonic/gin"},
-	{"Echo", "HTTP framework with error returns", "github.com/labstack/echo/v4", "https://github.com/labstack/echo"},
-	{"Fiber", "fasthttp based framework", "github.com/gofiber/fiber/v2", "https://github.com/gofiber/fiber"},
-	{"Mizu", "net/http aligned framework", "github.com/go-mizu/mizu", "https://github.com/go-mizu/mizu"},
-}
+// Cache layout, relative to -cache:
+//
+//	go.dev/dl.json                     go.dev/dl/?mode=json without file lists
+//	go.dev/releases.json               {"go1.25.5": "2025-12-15", ...}
+//	proxy/<module>/@v/list             GOPROXY=file:// layout, one version per line
+//	proxy/<module>/@v/<version>.info   {"Version": ..., "Time": ...}
+const (
+	dlSnapshot      = "go.dev/dl.json"
+	releaseSnapshot = "go.dev/releases.json"
+	proxyDir        = "proxy"
+)
 
 func main() {
 	var (
 		timeout = flag.Duration("timeout", 10*time.Second, "HTTP timeout")
 		verbose = flag.Bool("v", false, "print debug warnings to stderr")
+		offline = flag.Bool("offline", false, "read go.dev snapshots and module versions from -cache instead of the network")
+		update  = flag.Bool("update-cache", false, "refresh -cache from the network and exit")
+		cache   = flag.String("cache", "scripts/versions-cache", "directory holding go.dev snapshots and a file:// GOPROXY layout")
+		asOf    = flag.String("now", "", "reference date (YYYY-MM-DD) for the released ago column, default today")
 	)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go run scripts/versions.go [flags] [drift [-json]]")
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	now := time.Now()
-	ctx := context.Background()
-	httpc := &http.Client{Timeout: *timeout}
+	if *offline && *update {
+		fmt.Fprintln(os.Stderr, "-offline and -update-cache are mutually exclusive")
+		os.Exit(2)
+	}
 
-	var rows []row
+	now := time.Now()
+	if *asOf != "" {
+		t, err := time.Parse("2006-01-02", *asOf)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "-now:", err)
+			os.Exit(2)
+		}
+		now = t
+	}
 
-	// net/http: best is go.dev dl for version + go.dev release history for date.
-	goVer, goDate, goAgo := "N/A", "N/A", "N/A"
+	var src source = netSource{ctx: context.Background(), httpc: &http.Client{Timeout: *timeout}}
 
-	if v, err := latestStableGoVersion(ctx, httpc); err == nil {
-		goVer = v
-		if d, err := goReleaseDateFromHistory(ctx, httpc, v); err == nil {
-			goDate = d
-			if t, err := time.Parse("2006-01-02", d); err == nil {
-				goAgo = fmt.Sprintf("%d days", daysAgo(t, now))
-			}
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "go: version=%s date=%s (from go.dev)\n", goVer, goDate)
-			}
-		} else {
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "warn: go release date not found for %s: %v\n", v, err)
-			}
-		}
-	} else {
-		if *verbose {
-			fmt.Fprintf(os.Stderr, "warn: go.dev dl failed: %v\n", err)
+	if *update {
+		if err := updateCache(src, *cache); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
 	}
 
-	// Fallback: local Go version (date unknown)
-	if goVer == "N/A" || goVer == "" {
-		if lv := localGoVersion(); lv != "" {
-			goVer = lv
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "warn: using local Go version fallback: %s (date unknown)\n", lv)
-			}
-		}
+	if *offline {
+		src = cacheSource{dir: *cache}
 	}
 
-	rows = append(rows, row{
-		Name:        "net/http",
-		Description: "Go standard library HTTP server",
-		URL:         "https://github.com/golang/go",
-		LatestVer:   goVer,
-		LatestDate:  goDate,
-		LatestAgo:   goAgo,
-	})
+	switch flag.Arg(0) {
+	case "":
+	case "drift":
+		runDrift(src, flag.Args()[1:], *verbose)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown subcommand %q\n", flag.Arg(0))
+		os.Exit(2)
+	}
 
-	// frameworks: go list -m -json <module>@latest
-	for _, f := range frameworks {
-		if f.Module == "" {
-			continue
-		}
-		ver, t, ok := latestViaGoList(f.Module)
-		r := row{
-			Name:        f.Name,
-			Description: f.Description,
-			Module:      f.Module,
-			URL:         f.URL,
-			LatestVer:   "N/A",
-			LatestDate:  "N/A",
-			LatestAgo:   "N/A",
+	rows, err := tableRows(src, now)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if *offline {
+			fmt.Fprintf(os.Stderr, "%s is incomplete; refresh it with -update-cache\n", *cache)
 		}
-		if ok {
-			r.LatestVer = ver
-			if !t.IsZero() {
-				r.LatestDate = t.Format("2006-01-02")
-				r.LatestAgo = fmt.Sprintf("%d days", daysAgo(t, now))
-			}
+		os.Exit(1)
+	}
+	if *verbose {
+		for _, r := range rows {
+			fmt.Fprintf(os.Stderr, "%s: version=%s date=%s\n", r.Name, r.LatestVer, r.LatestDate)
 		}
-		rows = append(rows, r)
 	}
 
-	printMarkdown(rows)
+	printMarkdown(os.Stdout, rows)
 }
 
-func latestStableGoVersion(ctx context.Context, httpc *http.Client) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://go.dev/dl/?mode=json", nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("User-Agent", "go-fw-versions/1.6")
-	req.Header.Set("Accept", "application/json")
+// tableRows resolves the latest version and release date of every
+// registered framework; for net/http that is Go itself, from the go.dev
+// download feed and release history. A lookup that fails is an error
+// rather than an N/A cell, so a stale cache cannot go unnoticed.
+func tableRows(src source, now time.Time) ([]row, error) {
+	var (
+		rows []row
+		errs []error
+	)
 
-	resp, err := httpc.Do(req)
+	goVer, err := latestStableGoVersion(src)
+	var goDate time.Time
+	if err == nil {
+		goDate, err = goReleaseDate(src, goVer)
+	}
 	if err != nil {
-		return "", err
+		errs = append(errs, fmt.Errorf("go: %w", err))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("go.dev dl: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+	for _, f := range registry.Frameworks {
+		version, date := goVer, goDate
+		if !f.Stdlib() {
+			m, err := src.latest(f.Module)
+			if err == nil && m.Time.IsZero() {
+				err = fmt.Errorf("no release time for %s", m.Version)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", f.Module, err))
+				continue
+			}
+			version, date = m.Version, m.Time.UTC()
+		}
+		if version == "" {
+			continue
+		}
+
+		rows = append(rows, row{
+			Name:        f.Title,
+			Description: f.Description,
+			Module:      f.Module,
+			URL:         f.URL,
+			LatestVer:   version,
+			LatestDate:  date.Format("2006-01-02"),
+			LatestAgo:   fmt.Sprintf("%d days", daysAgo(date, now)),
+		})
 	}
 
-	var items []goDLItem
-	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+	return rows, errors.Join(errs...)
+}
+
+func latestStableGoVersion(src source) (string, error) {
+	items, err := src.goDL()
+	if err != nil {
 		return "", err
 	}
 
@@ -178,68 +209,263 @@ func latestStableGoVersion(ctx context.Context, httpc *http.Client) (string, err
 	return "", fmt.Errorf("no versions found in go.dev dl feed")
 }
 
-func goReleaseDateFromHistory(ctx context.Context, httpc *http.Client, version string) (string, error) {
-	// Release history page includes lines like: "go1.25.5 (released 2025-12-15)"
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://go.dev/doc/devel/release", nil)
+func goReleaseDate(src source, version string) (time.Time, error) {
+	releases, err := src.goReleases()
 	if err != nil {
-		return "", err
+		return time.Time{}, err
+	}
+	d, ok := releases[version]
+	if !ok {
+		return time.Time{}, fmt.Errorf("release date not found for %s", version)
+	}
+	return time.Parse("2006-01-02", d)
+}
+
+// netSource queries go.dev and the configured GOPROXY.
+type netSource struct {
+	ctx   context.Context
+	httpc *http.Client
+}
+
+func (s netSource) get(url, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(s.ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
 	}
 	req.Header.Set("User-Agent", "go-fw-versions/1.6")
-	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept", accept)
 
-	resp, err := httpc.Do(req)
+	resp, err := s.httpc.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		b, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("go.dev release history: %s: %s", resp.Status, strings.TrimSpace(string(b)))
+		return nil, fmt.Errorf("%s: %s: %s", url, resp.Status, strings.TrimSpace(string(b)))
 	}
 
-	b, err := io.ReadAll(resp.Body)
+	return b, nil
+}
+
+func (s netSource) goDL() ([]goDLItem, error) {
+	b, err := s.get("https://go.dev/dl/?mode=json", "application/json")
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	s := string(b)
 
-	ver := regexp.QuoteMeta(version)
-	re := regexp.MustCompile(`\b` + ver + `\b\s*\(released\s*([0-9]{4}-[0-9]{2}-[0-9]{2})\)`)
-	m := re.FindStringSubmatch(s)
-	if len(m) == 2 {
-		return m[1], nil
+	var items []goDLItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, err
 	}
-
-	return "", fmt.Errorf("release date not found for %s", version)
+	return items, nil
 }
 
-func localGoVersion() string {
-	cmd := exec.Command("go", "env", "GOVERSION")
-	out, err := cmd.Output()
+// releaseRE matches release history lines like "go1.25.5 (released 2025-12-15)".
+var releaseRE = regexp.MustCompile(`\b(go[0-9][0-9a-z.]*)\b\s*\(released\s*([0-9]{4}-[0-9]{2}-[0-9]{2})\)`)
+
+func (s netSource) goReleases() (map[string]string, error) {
+	b, err := s.get("https://go.dev/doc/devel/release", "text/html")
 	if err != nil {
-		return ""
+		return nil, err
+	}
+
+	releases := map[string]string{}
+	for _, m := range releaseRE.FindAllStringSubmatch(string(b), -1) {
+		releases[m[1]] = m[2]
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no release dates found in go.dev release history")
 	}
-	return strings.TrimSpace(string(out))
+	return releases, nil
 }
 
-func latestViaGoList(module string) (ver string, t time.Time, ok bool) {
-	cmd := exec.Command("go", "list", "-m", "-json", module+"@latest")
+func (s netSource) latest(mod string) (goListModule, error) {
+	cmd := exec.Command("go", "list", "-m", "-json", mod+"@latest")
 	cmd.Env = os.Environ()
 
 	out, err := cmd.Output()
 	if err != nil {
-		return "", time.Time{}, false
+		return goListModule{}, fmt.Errorf("go list: %w", err)
 	}
 
 	var m goListModule
 	if err := json.Unmarshal(out, &m); err != nil {
-		return "", time.Time{}, false
+		return goListModule{}, err
 	}
 	if m.Version == "" {
-		return "", time.Time{}, false
+		return goListModule{}, fmt.Errorf("go list: no version for %s", mod)
+	}
+	return m, nil
+}
+
+// cacheSource reads the snapshots written by updateCache. Its proxy
+// directory also works as GOPROXY=file://<dir>/proxy for the go command.
+type cacheSource struct {
+	dir string
+}
+
+func (s cacheSource) readJSON(name string, v any) error {
+	b, err := os.ReadFile(filepath.Join(s.dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+func (s cacheSource) goDL() ([]goDLItem, error) {
+	var items []goDLItem
+	return items, s.readJSON(dlSnapshot, &items)
+}
+
+func (s cacheSource) goReleases() (map[string]string, error) {
+	var releases map[string]string
+	return releases, s.readJSON(releaseSnapshot, &releases)
+}
+
+// latest picks the highest release in @v/list, or the highest pre-release
+// when there is no release, the same rule the go command applies.
+func (s cacheSource) latest(mod string) (goListModule, error) {
+	dir, err := moduleDir(s.dir, mod)
+	if err != nil {
+		return goListModule{}, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "list"))
+	if err != nil {
+		return goListModule{}, err
+	}
+
+	best := ""
+	for _, v := range strings.Fields(string(b)) {
+		if !semver.IsValid(v) {
+			continue
+		}
+		switch {
+		case best == "":
+			best = v
+		case (semver.Prerelease(best) == "") != (semver.Prerelease(v) == ""):
+			if semver.Prerelease(v) == "" {
+				best = v
+			}
+		case semver.Compare(v, best) > 0:
+			best = v
+		}
+	}
+	if best == "" {
+		return goListModule{}, fmt.Errorf("%s: no versions in @v/list", mod)
+	}
+
+	ev, err := module.EscapeVersion(best)
+	if err != nil {
+		return goListModule{}, err
+	}
+
+	var m goListModule
+	b, err = os.ReadFile(filepath.Join(dir, ev+".info"))
+	if err != nil {
+		return goListModule{}, err
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return goListModule{}, err
+	}
+	m.Path = mod
+	return m, nil
+}
+
+// moduleDir returns the @v directory of mod in the cache proxy layout.
+func moduleDir(cache, mod string) (string, error) {
+	ep, err := module.EscapePath(mod)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cache, proxyDir, filepath.FromSlash(ep), "@v"), nil
+}
+
+// updateCache snapshots everything the offline table needs. Modules that
+// cannot be resolved keep their previous cache entry.
+func updateCache(src source, dir string) error {
+	var errs []error
+
+	if items, err := src.goDL(); err != nil {
+		errs = append(errs, err)
+	} else {
+		for i := range items {
+			items[i].Files = nil
+		}
+		errs = append(errs, writeJSON(filepath.Join(dir, dlSnapshot), items))
+	}
+
+	if releases, err := src.goReleases(); err != nil {
+		errs = append(errs, err)
+	} else {
+		errs = append(errs, writeJSON(filepath.Join(dir, releaseSnapshot), releases))
+	}
+
+	for _, mod := range registry.Modules() {
+		m, err := src.latest(mod)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", mod, err))
+			continue
+		}
+		errs = append(errs, writeProxyVersion(dir, mod, m))
+	}
+
+	return errors.Join(errs...)
+}
+
+// writeProxyVersion records m as a version of mod in the file proxy layout.
+func writeProxyVersion(dir, mod string, m goListModule) error {
+	vdir, err := moduleDir(dir, mod)
+	if err != nil {
+		return err
+	}
+
+	ev, err := module.EscapeVersion(m.Version)
+	if err != nil {
+		return err
+	}
+
+	info := struct {
+		Version string
+		Time    time.Time
+	}{m.Version, m.Time.UTC()}
+	if err := writeJSON(filepath.Join(vdir, ev+".info"), info); err != nil {
+		return err
+	}
+
+	listFile := filepath.Join(vdir, "list")
+	b, err := os.ReadFile(listFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	versions := strings.Fields(string(b))
+	for _, v := range versions {
+		if v == m.Version {
+			return nil
+		}
+	}
+	versions = append(versions, m.Version)
+	sort.Slice(versions, func(i, j int) bool { return semver.Compare(versions[i], versions[j]) < 0 })
+
+	return os.WriteFile(listFile, []byte(strings.Join(versions, "\n")+"\n"), 0644)
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
 	}
-	return m.Version, m.Time, true
+	return os.WriteFile(path, append(b, '\n'), 0644)
 }
 
 func daysAgo(t, now time.Time) int {
@@ -255,13 +481,13 @@ func daysAgo(t, now time.Time) int {
 	return int(n0.Sub(t0).Hours() / 24)
 }
 
-func printMarkdown(rows []row) {
-	fmt.Println("## Frameworks covered")
-	fmt.Println()
-	fmt.Println("| Framework | Description | Latest version | Latest date | Released ago | GitHub |")
-	fmt.Println("|---|---|---|---|---|---|")
+func printMarkdown(w io.Writer, rows []row) {
+	fmt.Fprintln(w, "## Frameworks covered")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| Framework | Description | Latest version | Latest date | Released ago | GitHub |")
+	fmt.Fprintln(w, "|---|---|---|---|---|---|")
 	for _, r := range rows {
-		fmt.Printf("| %s | %s | %s | %s | %s | %s |\n",
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n",
 			esc(r.Name),
 			esc(r.Description),
 			esc(r.LatestVer),
@@ -275,3 +501,255 @@ func printMarkdown(rows []row) {
 func esc(s string) string {
 	return strings.ReplaceAll(s, "|", "\\|")
 }
+
+// pin is what one example go.mod requires of its framework. For net/http it
+// is the go directive.
+type pin struct {
+	Chapter   string `json:"chapter"`
+	Framework string `json:"framework"`
+	Module    string `json:"module"`
+	Pinned    string `json:"pinned"`
+	Latest    string `json:"latest"`
+	Behind    bool   `json:"behind"`
+}
+
+// spread lists the distinct versions one framework is pinned at and the
+// chapters using each.
+type spread struct {
+	Framework string              `json:"framework"`
+	Module    string              `json:"module"`
+	Versions  map[string][]string `json:"versions"`
+}
+
+type driftReport struct {
+	Latest       map[string]string `json:"latest"`
+	Pins         []pin             `json:"pins"`
+	Inconsistent []spread          `json:"inconsistent"`
+}
+
+// runDrift compares the version every example module pins against the latest
+// one and prints a chapter × framework matrix.
+func runDrift(src source, args []string, verbose bool) {
+	fs := flag.NewFlagSet("drift", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the report as JSON instead of Markdown")
+	_ = fs.Parse(args)
+
+	latest := map[string]string{}
+	var errs []error
+	for _, f := range registry.Frameworks {
+		var (
+			v   string
+			err error
+		)
+		if f.Stdlib() {
+			v, err = latestStableGoVersion(src)
+		} else {
+			var m goListModule
+			m, err = src.latest(f.Module)
+			v = m.Version
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("latest %s: %w", f.Title, err))
+			continue
+		}
+		if verbose {
+			fmt.Fprintf(os.Stderr, "%s: latest=%s\n", f.Title, v)
+		}
+		latest[f.Dir] = v
+	}
+	if err := errors.Join(errs...); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	report, err := collectPins(latest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	printDrift(report)
+}
+
+// collectPins parses NN-*/<fw>/go.mod for every registered framework.
+func collectPins(latest map[string]string) (driftReport, error) {
+	report := driftReport{Latest: latest}
+
+	mods, err := filepath.Glob("[0-9][0-9]-*/*/go.mod")
+	if err != nil {
+		return report, err
+	}
+
+	spreads := map[string]*spread{}
+
+	for _, path := range mods {
+		dir := filepath.Dir(path)
+		f, ok := registry.ByDir(filepath.Base(dir))
+		if !ok {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return report, err
+		}
+		mf, err := modfile.ParseLax(path, data, nil)
+		if err != nil {
+			return report, err
+		}
+
+		p := pin{
+			Chapter:   filepath.Dir(dir),
+			Framework: f.Dir,
+			Module:    f.Module,
+			Latest:    latest[f.Dir],
+		}
+
+		if f.Stdlib() {
+			p.Module = "go"
+			if mf.Go != nil {
+				p.Pinned = "go" + mf.Go.Version
+			}
+		} else {
+			for _, r := range mf.Require {
+				if r.Mod.Path == f.Module {
+					p.Pinned = r.Mod.Version
+				}
+			}
+		}
+
+		if p.Pinned == "" {
+			continue
+		}
+		if p.Latest != "" {
+			p.Behind = behind(f, p.Pinned, p.Latest)
+		}
+		report.Pins = append(report.Pins, p)
+
+		sp := spreads[f.Dir]
+		if sp == nil {
+			sp = &spread{Framework: f.Dir, Module: p.Module, Versions: map[string][]string{}}
+			spreads[f.Dir] = sp
+		}
+		sp.Versions[p.Pinned] = append(sp.Versions[p.Pinned], p.Chapter)
+	}
+
+	for _, f := range registry.Frameworks {
+		if sp := spreads[f.Dir]; sp != nil && len(sp.Versions) > 1 {
+			report.Inconsistent = append(report.Inconsistent, *sp)
+		}
+	}
+
+	return report, nil
+}
+
+// behind reports whether pinned is older than latest. The go directive of
+// a go.mod names a language version, such as go1.25, so for net/http only
+// the major and minor of the latest release count: go1.25 is current while
+// go1.25.5 is the latest, and behind once go1.26.0 is out.
+func behind(f registry.Framework, pinned, latest string) bool {
+	pinned, latest = semverOf(pinned), semverOf(latest)
+	if f.Stdlib() {
+		pinned, latest = semver.MajorMinor(pinned), semver.MajorMinor(latest)
+	}
+	return semver.Compare(pinned, latest) < 0
+}
+
+// semverOf turns a Go toolchain version such as go1.25.5 into v1.25.5 so Go
+// and module versions compare the same way.
+func semverOf(v string) string {
+	if rest, ok := strings.CutPrefix(v, "go"); ok {
+		return "v" + rest
+	}
+	return v
+}
+
+func printDrift(report driftReport) {
+	cells := map[string]map[string]pin{}
+	var chapters []string
+	for _, p := range report.Pins {
+		if cells[p.Chapter] == nil {
+			cells[p.Chapter] = map[string]pin{}
+			chapters = append(chapters, p.Chapter)
+		}
+		cells[p.Chapter][p.Framework] = p
+	}
+	sort.Strings(chapters)
+
+	fmt.Println("## Version drift")
+	fmt.Println()
+	fmt.Print("| Chapter |")
+	for _, f := range registry.Frameworks {
+		fmt.Printf(" %s |", esc(f.Title))
+	}
+	fmt.Println()
+	fmt.Print("|---|")
+	for range registry.Frameworks {
+		fmt.Print("---|")
+	}
+	fmt.Println()
+
+	fmt.Print("| *latest* |")
+	for _, f := range registry.Frameworks {
+		v := report.Latest[f.Dir]
+		if v == "" {
+			v = "N/A"
+		}
+		fmt.Printf(" %s |", v)
+	}
+	fmt.Println()
+
+	for _, chapter := range chapters {
+		fmt.Printf("| %s |", chapter)
+		for _, f := range registry.Frameworks {
+			p, ok := cells[chapter][f.Dir]
+			switch {
+			case !ok:
+				fmt.Print(" - |")
+			case p.Behind:
+				fmt.Printf(" %s ↓ |", p.Pinned)
+			default:
+				fmt.Printf(" %s |", p.Pinned)
+			}
+		}
+		fmt.Println()
+	}
+
+	fmt.Println()
+	fmt.Println("↓ behind latest, - no go.mod or no requirement on the framework")
+
+	if len(report.Inconsistent) == 0 {
+		return
+	}
+
+	fmt.Println()
+	fmt.Println("### Inconsistent pins")
+	fmt.Println()
+	fmt.Println("| Framework | Version | Chapters |")
+	fmt.Println("|---|---|---|")
+	for _, sp := range report.Inconsistent {
+		versions := make([]string, 0, len(sp.Versions))
+		for v := range sp.Versions {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return semver.Compare(semverOf(versions[i]), semverOf(versions[j])) < 0
+		})
+		for _, v := range versions {
+			chapters := strings.Join(sp.Versions[v], ", ")
+			if n := len(sp.Versions[v]); n > 5 {
+				chapters = fmt.Sprintf("%d chapters", n)
+			}
+			fmt.Printf("| %s | %s | %s |\n", sp.Module, v, chapters)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-mizu/go-fw/pkg/registry"
+)
+
+const fixtureCache = "testdata/cache"
+
+func TestTableRows(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows, err := tableRows(cacheSource{dir: fixtureCache}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	printMarkdown(&b, rows)
+
+	want := `## Frameworks covered
+
+| Framework | Description | Latest version | Latest date | Released ago | GitHub |
+|---|---|---|---|---|---|
+| net/http | Go standard library HTTP server | go1.25.5 | 2025-12-02 | 30 days | https://github.com/golang/go |
+| Chi | Router built on net/http | v5.2.3 | 2025-08-26 | 128 days | https://github.com/go-chi/chi |
+| Gin | API focused HTTP framework | v1.11.0 | 2025-09-20 | 103 days | https://github.com/gin-gonic/gin |
+| Echo | HTTP framework with error returns | v4.14.0 | 2025-12-11 | 21 days | https://github.com/labstack/echo |
+| Fiber | fasthttp based framework | v2.52.10 | 2025-11-19 | 43 days | https://github.com/gofiber/fiber |
+| Mizu | net/http aligned framework | v0.2.2-rc.1 | 2025-12-15 | 17 days | https://github.com/go-mizu/mizu |
+`
+	if got := b.String(); got != want {
+		t.Errorf("table:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCacheSourceLatest(t *testing.T) {
+	src := cacheSource{dir: fixtureCache}
+
+	tests := []struct {
+		mod  string
+		want string
+	}{
+		{"github.com/go-chi/chi/v5", "v5.2.3"},     // release beats a newer pre-release
+		{"github.com/labstack/echo/v4", "v4.14.0"}, // @v/list is not sorted
+		{"github.com/go-mizu/mizu", "v0.2.2-rc.1"}, // pre-releases only
+	}
+	for _, tt := range tests {
+		m, err := src.latest(tt.mod)
+		if err != nil {
+			t.Errorf("latest(%s): %v", tt.mod, err)
+			continue
+		}
+		if m.Version != tt.want || m.Path != tt.mod || m.Time.IsZero() {
+			t.Errorf("latest(%s) = %+v, want %s with a time", tt.mod, m, tt.want)
+		}
+	}
+}
+
+// A cache miss is an error naming what is missing, never a guessed row.
+func TestTableRowsCacheMiss(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		corrupt func(dir string) error
+		want    string
+	}{
+		{
+			name:    "no go.dev feed",
+			corrupt: func(dir string) error { return os.Remove(filepath.Join(dir, dlSnapshot)) },
+			want:    "go: open",
+		},
+		{
+			name:    "no release date",
+			corrupt: func(dir string) error { return os.WriteFile(filepath.Join(dir, releaseSnapshot), []byte("{}"), 0644) },
+			want:    "go: release date not found for go1.25.5",
+		},
+		{
+			name:    "no module",
+			corrupt: func(dir string) error { return os.RemoveAll(filepath.Join(dir, proxyDir, "github.com/gin-gonic")) },
+			want:    "github.com/gin-gonic/gin: open",
+		},
+		{
+			name: "no release time",
+			corrupt: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, proxyDir, "github.com/gofiber/fiber/v2/@v/v2.52.10.info"), []byte(`{"Version":"v2.52.10"}`), 0644)
+			},
+			want: "github.com/gofiber/fiber/v2: no release time for v2.52.10",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.CopyFS(dir, os.DirFS(fixtureCache)); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.corrupt(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := tableRows(cacheSource{dir: dir}, now)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("tableRows error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// Whatever -update-cache writes, -offline reads back.
+func TestUpdateCache(t *testing.T) {
+	fixture := cacheSource{dir: fixtureCache}
+	dir := t.TempDir()
+
+	if err := updateCache(fixture, dir); err != nil {
+		t.Fatal(err)
+	}
+	// a second run keeps @v/list free of duplicates
+	if err := updateCache(fixture, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	want, err := tableRows(fixture, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tableRows(cacheSource{dir: dir}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rows from the written cache:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	list, err := os.ReadFile(filepath.Join(dir, proxyDir, "github.com/gin-gonic/gin/@v/list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(list) != "v1.11.0\n" {
+		t.Errorf("@v/list = %q, want only the latest version once", list)
+	}
+}
+
+func TestBehind(t *testing.T) {
+	stdlib, _ := registry.ByDir("nethttp")
+	gin, _ := registry.ByDir("gin")
+
+	tests := []struct {
+		f      registry.Framework
+		pinned string
+		latest string
+		want   bool
+	}{
+		{stdlib, "go1.25", "go1.25.5", false}, // the go directive is a language version
+		{stdlib, "go1.25.0", "go1.25.5", false},
+		{stdlib, "go1.24", "go1.25.5", true},
+		{stdlib, "go1.26", "go1.25.5", false},
+		{gin, "v1.10.1", "v1.11.0", true},
+		{gin, "v1.11.0", "v1.11.0", false},
+		{gin, "v1.11.0", "v1.11.1", true}, // module patches count
+	}
+	for _, tt := range tests {
+		if got := behind(tt.f, tt.pinned, tt.latest); got != tt.want {
+			t.Errorf("behind(%s, %s, %s) = %v, want %v", tt.f.Dir, tt.pinned, tt.latest, got, tt.want)
+		}
+	}
+}
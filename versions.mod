@@ -0,0 +1,16 @@
+// Minimum versions for the third-party modules imported by the examples.
+// cmd/extract copies these into every NN-*/<fw>/go.mod it generates; bump a
+// version here and re-run the extractor to move all chapters at once.
+
+require (
+	github.com/gin-gonic/gin v1.11.0
+	github.com/go-chi/chi/v5 v5.2.3
+	github.com/go-mizu/mizu v0.2.2
+	github.com/gofiber/fiber/v2 v2.52.10
+	github.com/gofiber/template/html/v2 v2.1.3
+	github.com/gofiber/websocket/v2 v2.2.1
+	github.com/gorilla/websocket v1.5.3
+	github.com/labstack/echo/v4 v4.14.0
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/prometheus/client_golang v1.19.1
+)